@@ -0,0 +1,412 @@
+// Command aaronjson formats, validates and queries JSON documents using the
+// aaronjson package, so that the command line parses and prints JSON exactly
+// as the services built on the library do.
+//
+// Usage:
+//
+//	aaronjson fmt [flags] [file ...]
+//	aaronjson get [flags] <pointer> [file ...]
+//	aaronjson query [flags] <jsonpath> [file ...]
+//	aaronjson validate [flags] [file ...]
+//	aaronjson ndjson [flags] [file ...]
+//
+// fmt pretty-prints each document (or compacts it with -compact), get prints
+// the value an RFC 6901 JSON Pointer refers to, query prints every value an
+// RFC 9535 JSONPath expression selects and validate only checks the input.
+// ndjson converts a stream of concatenated JSON values, such as pretty-printed
+// documents, to newline-delimited JSON; with -split the elements of top-level
+// arrays become separate lines.
+// Inputs are files or, if none are named or the name is "-", standard input.
+//
+// With -ndjson each input is read as newline-delimited JSON: every line is a
+// separate document and output is written one compact value per line.
+//
+// Invalid input is reported on standard error with its position and the
+// offending line. The exit status is 0 on success, 1 if any input was
+// invalid or a pointer did not resolve, and 2 for usage errors.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// Exit statuses.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command describes a subcommand. The value-producing subcommands take one
+// leading argument (the pointer or expression) before the file names.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	hasArg  bool
+	// lines reads each input as concatenated values and writes one compact
+	// value per line.
+	lines bool
+	// prepare validates the leading argument and returns the function that
+	// processes each document.
+	prepare func(arg string, c *cli) (func(doc aaronjson.JsonValue) error, error)
+}
+
+var commands = []*command{
+	{
+		name:    "fmt",
+		usage:   "fmt [flags] [file ...]",
+		summary: "pretty-print or compact JSON",
+		prepare: func(_ string, c *cli) (func(aaronjson.JsonValue) error, error) {
+			return c.write, nil
+		},
+	},
+	{
+		name:    "get",
+		usage:   "get [flags] <pointer> [file ...]",
+		summary: "print the value a JSON Pointer refers to",
+		hasArg:  true,
+		prepare: func(arg string, c *cli) (func(aaronjson.JsonValue) error, error) {
+			ptr, err := aaronjson.ParsePointer(arg)
+			if err != nil {
+				return nil, err
+			}
+			return func(doc aaronjson.JsonValue) error {
+				v, err := ptr.Get(doc)
+				if err != nil {
+					return err
+				}
+				return c.write(v)
+			}, nil
+		},
+	},
+	{
+		name:    "query",
+		usage:   "query [flags] <jsonpath> [file ...]",
+		summary: "print the values a JSONPath expression selects",
+		hasArg:  true,
+		prepare: func(arg string, c *cli) (func(aaronjson.JsonValue) error, error) {
+			jp, err := aaronjson.CompileJSONPath(arg)
+			if err != nil {
+				return nil, err
+			}
+			return func(doc aaronjson.JsonValue) error {
+				for _, node := range jp.Query(doc) {
+					var err error
+					if c.paths {
+						err = c.writeLine(node.NormalizedPath())
+					} else {
+						err = c.write(node.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}, nil
+		},
+	},
+	{
+		name:    "validate",
+		usage:   "validate [flags] [file ...]",
+		summary: "check that the input is valid JSON",
+		prepare: func(string, *cli) (func(aaronjson.JsonValue) error, error) {
+			return func(aaronjson.JsonValue) error { return nil }, nil
+		},
+	},
+	{
+		name:    "ndjson",
+		usage:   "ndjson [flags] [file ...]",
+		summary: "convert a stream of JSON values to newline-delimited JSON",
+		lines:   true,
+		prepare: func(_ string, c *cli) (func(aaronjson.JsonValue) error, error) {
+			return func(doc aaronjson.JsonValue) error {
+				arr, ok := doc.(*aaronjson.JsonArray)
+				if !c.split || !ok {
+					return c.write(doc)
+				}
+				elems, _ := arr.GetSlice()
+				for _, elem := range elems {
+					if err := c.write(elem); err != nil {
+						return err
+					}
+				}
+				return nil
+			}, nil
+		},
+	},
+}
+
+// cli holds the flags and output streams of one invocation.
+type cli struct {
+	stdin     io.Reader
+	stdout    *bufio.Writer
+	stderr    io.Writer
+	enc       *aaronjson.Encoder
+	parse     aaronjson.ParseOptions
+	ndjson    bool
+	stream    bool
+	split     bool
+	raw       bool
+	paths     bool
+	keepGoing bool
+	failed    bool
+	writeErr  error
+}
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.name == args[0] {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "aaronjson: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	c := &cli{stdin: stdin, stdout: bufio.NewWriter(stdout), stderr: stderr}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: aaronjson %s\n\n%s.\n\nflags:\n", cmd.usage, capitalize(cmd.summary))
+		fs.PrintDefaults()
+	}
+	compact, indent, width := new(bool), new(string), new(int)
+	if !cmd.lines {
+		fs.BoolVar(compact, "compact", false, "write compact output instead of pretty-printing")
+		fs.StringVar(indent, "indent", "  ", "indentation for each nesting level of pretty output")
+		fs.IntVar(width, "width", 0, "keep arrays and objects that fit in this many columns on one line")
+	}
+	sortKeys := fs.Bool("sort-keys", false, "write object members sorted by key")
+	fs.BoolVar(&c.ndjson, "ndjson", false, "read newline-delimited JSON, one document per line")
+	useNumber := fs.Bool("use-number", false, "keep numbers exactly as written in the input")
+	strict := fs.Bool("strict", false, "reject duplicate object keys")
+	maxDepth := fs.Int("max-depth", aaronjson.DefaultMaxDepth, "maximum nesting depth of the input")
+	if cmd.name == "get" || cmd.name == "query" {
+		fs.BoolVar(&c.raw, "raw", false, "write strings without quotes")
+	}
+	if cmd.name == "query" {
+		fs.BoolVar(&c.paths, "paths", false, "write the normalized paths of the selected values instead")
+	}
+	if cmd.name == "ndjson" {
+		fs.BoolVar(&c.split, "split", false, "write the elements of top-level arrays on separate lines")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	var arg string
+	files := fs.Args()
+	if cmd.hasArg {
+		if len(files) == 0 {
+			fmt.Fprintf(stderr, "usage: aaronjson %s\n", cmd.usage)
+			return exitUsage
+		}
+		arg, files = files[0], files[1:]
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	c.parse = aaronjson.ParseOptions{UseNumber: *useNumber, MaxDepth: *maxDepth}
+	if *strict {
+		c.parse.DuplicateKeys = aaronjson.DuplicateKeyReject
+	}
+	c.stream = cmd.lines
+	c.enc = aaronjson.NewEncoder(c.stdout)
+	if !*compact && !c.ndjson && !cmd.lines {
+		opts := aaronjson.DefaultPrettyOptions()
+		opts.Indent = *indent
+		opts.MaxInlineWidth = *width
+		c.enc.SetPrettyOptions(opts)
+	}
+	if *sortKeys {
+		c.enc.SetKeyOrder(aaronjson.KeyOrderSorted)
+	}
+	// Validation reports every problem; other commands stop at the first bad line
+	c.keepGoing = cmd.name == "validate"
+
+	process, err := cmd.prepare(arg, c)
+	if err != nil {
+		fmt.Fprintf(stderr, "aaronjson: %v\n", err)
+		return exitUsage
+	}
+	for _, name := range files {
+		c.processFile(name, process)
+		if c.writeErr != nil {
+			fmt.Fprintf(stderr, "aaronjson: %v\n", c.writeErr)
+			return exitError
+		}
+	}
+	if err := c.stdout.Flush(); err != nil {
+		fmt.Fprintf(stderr, "aaronjson: %v\n", err)
+		return exitError
+	}
+	if c.failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// processFile reads the documents of one input and passes each to process,
+// reporting any errors against the input's name.
+func (c *cli) processFile(name string, process func(aaronjson.JsonValue) error) {
+	display := name
+	var r io.Reader = c.stdin
+	if name == "-" {
+		display = "<stdin>"
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			c.report("", err)
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if c.stream {
+		// Decode also stops at a stray ']' or '}', which More would take
+		// for the end of the input
+		dec := aaronjson.NewDecoderWithOptions(r, c.parse)
+		for {
+			doc, err := dec.Decode()
+			if err == io.EOF {
+				return
+			}
+			if err == nil {
+				err = process(doc)
+			}
+			if err != nil {
+				c.report(display, err)
+				return
+			}
+		}
+	}
+	if !c.ndjson {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			c.report(display, err)
+			return
+		}
+		doc, err := aaronjson.ParseByteWithOptions(data, c.parse)
+		if err == nil {
+			err = process(doc)
+		}
+		if err != nil {
+			c.report(display, err)
+		}
+		return
+	}
+
+	opts := aaronjson.NDJSONOptions{Parse: c.parse}
+	if c.keepGoing {
+		opts.BadLines = aaronjson.BadLineCollect
+	}
+	nr := aaronjson.NewNDJSONReaderWithOptions(r, opts)
+	for nr.Next() {
+		if err := process(nr.Value()); err != nil {
+			c.report(display, &aaronjson.LineError{Line: nr.Line(), Err: err})
+			if c.writeErr != nil || !c.keepGoing {
+				return
+			}
+		}
+	}
+	for _, lineErr := range nr.Errors() {
+		c.report(display, lineErr)
+	}
+	if err := nr.Err(); err != nil {
+		c.report(display, err)
+	}
+}
+
+// write writes v in the selected output format. Strings are written
+// unquoted with -raw.
+func (c *cli) write(v aaronjson.JsonValue) error {
+	if c.raw && v.IsString() {
+		s, _ := v.AsString()
+		return c.writeLine(s)
+	}
+	if err := c.enc.Encode(v); err != nil {
+		c.writeErr = err
+		return err
+	}
+	return nil
+}
+
+// writeLine writes s followed by a newline.
+func (c *cli) writeLine(s string) error {
+	if _, err := c.stdout.WriteString(s + "\n"); err != nil {
+		c.writeErr = err
+		return err
+	}
+	return nil
+}
+
+// report writes err to standard error, followed by the offending line of a
+// syntax error, and marks the run as failed. Output written so far is
+// flushed first so that the two streams interleave sensibly.
+func (c *cli) report(name string, err error) {
+	c.failed = true
+	if c.writeErr != nil {
+		return
+	}
+	_ = c.stdout.Flush()
+	if name != "" {
+		fmt.Fprintf(c.stderr, "aaronjson: %s: %v\n", name, err)
+	} else {
+		fmt.Fprintf(c.stderr, "aaronjson: %v\n", err)
+	}
+	var syntaxErr *aaronjson.SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Snippet != "" {
+		for _, line := range strings.Split(syntaxErr.Snippet, "\n") {
+			fmt.Fprintf(c.stderr, "    %s\n", line)
+		}
+	}
+}
+
+// printUsage writes the list of subcommands.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: aaronjson <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "aaronjson <command> -h" for the flags of a command.`)
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
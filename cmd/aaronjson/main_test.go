@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI runs the command line with the given standard input and returns
+// the exit status and both output streams.
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// writeFile creates a file with the given content in a temporary directory.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testDoc = `{"name": "ann", "tags": ["a", "b"], "address": {"city": "Oslo", "zip": null}}`
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string
+	}{
+		{
+			name:  "fmt pretty",
+			args:  []string{"fmt"},
+			stdin: `{"a":[1,{"b":2}]}`,
+			want:  "{\n  \"a\": [\n    1,\n    {\n      \"b\": 2\n    }\n  ]\n}\n",
+		},
+		{
+			name:  "fmt indent and width",
+			args:  []string{"fmt", "-indent", "\t", "-width", "20"},
+			stdin: `{"a":[1,2],"b":{"c":"a long string value"}}`,
+			want:  "{\n\t\"a\": [1, 2],\n\t\"b\": {\n\t\t\"c\": \"a long string value\"\n\t}\n}\n",
+		},
+		{name: "fmt compact", args: []string{"fmt", "-compact"}, stdin: "{ \"b\" : 1 ,\n \"a\" : [ 1.50 ] }", want: "{\"b\":1,\"a\":[1.5]}\n"},
+		{name: "fmt sorted", args: []string{"fmt", "-compact", "-sort-keys"}, stdin: `{"b":1,"a":{"d":1,"c":2}}`, want: "{\"a\":{\"c\":2,\"d\":1},\"b\":1}\n"},
+		{name: "fmt use-number", args: []string{"fmt", "-use-number"}, stdin: `[1.10, 12345678901234567890123]`, want: "[\n  1.10,\n  12345678901234567890123\n]\n"},
+		{name: "fmt ndjson", args: []string{"fmt", "-ndjson"}, stdin: "{ \"a\" : 1 }\n\n[ 2 ]\n", want: "{\"a\":1}\n[2]\n"},
+		{name: "get", args: []string{"get", "/address/city"}, stdin: testDoc, want: "\"Oslo\"\n"},
+		{name: "get raw", args: []string{"get", "-raw", "/tags/1"}, stdin: testDoc, want: "b\n"},
+		{name: "get whole document", args: []string{"get", "-compact", ""}, stdin: `{"a":1}`, want: "{\"a\":1}\n"},
+		{name: "get ndjson", args: []string{"get", "-ndjson", "/a"}, stdin: "{\"a\":1}\n{\"a\":[2]}\n", want: "1\n[2]\n"},
+		{name: "query", args: []string{"query", "$.tags[*]"}, stdin: testDoc, want: "\"a\"\n\"b\"\n"},
+		{name: "query raw", args: []string{"query", "-raw", "$..city"}, stdin: testDoc, want: "Oslo\n"},
+		{name: "query paths", args: []string{"query", "-paths", "$.address.*"}, stdin: testDoc, want: "$['address']['city']\n$['address']['zip']\n"},
+		{name: "query no match", args: []string{"query", "$.missing"}, stdin: testDoc, want: ""},
+		{name: "query filter", args: []string{"query", "-compact", "$[?@.n > 1]"}, stdin: `[{"n":1},{"n":2}]`, want: "{\"n\":2}\n"},
+		{name: "validate", args: []string{"validate"}, stdin: testDoc, want: ""},
+		{name: "validate ndjson", args: []string{"validate", "-ndjson"}, stdin: "1\n\"x\"\n", want: ""},
+		{name: "ndjson", args: []string{"ndjson"}, stdin: "{\n  \"a\": [1, 2]\n}\n[3] \"x\"", want: "{\"a\":[1,2]}\n[3]\n\"x\"\n"},
+		{name: "ndjson split", args: []string{"ndjson", "-split", "-sort-keys"}, stdin: `[{"b":1,"a":2}, [3]] 4`, want: "{\"a\":2,\"b\":1}\n[3]\n4\n"},
+		{name: "ndjson empty", args: []string{"ndjson"}, stdin: " \n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, tt.stdin, tt.args...)
+			if code != exitOK || stderr != "" {
+				t.Fatalf("exit %d, stderr %q", code, stderr)
+			}
+			if stdout != tt.want {
+				t.Errorf("stdout = %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	first := writeFile(t, "first.json", `{"a":1}`)
+	second := writeFile(t, "second.json", `{"a":2}`)
+
+	code, stdout, stderr := runCLI(t, `{"a":3}`, "get", "/a", first, "-", second)
+	if code != exitOK || stderr != "" {
+		t.Fatalf("exit %d, stderr %q", code, stderr)
+	}
+	if stdout != "1\n3\n2\n" {
+		t.Errorf("stdout = %q, want 1, 3 and 2", stdout)
+	}
+
+	code, _, stderr = runCLI(t, "", "validate", filepath.Join(t.TempDir(), "missing.json"))
+	if code != exitError || !strings.Contains(stderr, "missing.json") {
+		t.Errorf("missing file: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	bad := writeFile(t, "bad.json", "{\"a\": 1,\n \"b\": x}")
+	good := writeFile(t, "good.json", `[]`)
+
+	code, stdout, stderr := runCLI(t, "", "fmt", "-compact", good, bad)
+	if code != exitError {
+		t.Errorf("exit %d, want %d", code, exitError)
+	}
+	if stdout != "[]\n" {
+		t.Errorf("stdout = %q, want the valid document only", stdout)
+	}
+	want := "aaronjson: " + bad + ": invalid character 'x' looking for beginning of value at line 2, column 7 (offset 15)\n" +
+		"     \"b\": x}\n" +
+		"          ^\n"
+	if stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+
+	// Validation reports every bad line; other commands stop at the first
+	input := "1\nnope\n2\n[\n"
+	code, _, stderr = runCLI(t, input, "validate", "-ndjson")
+	if code != exitError || strings.Count(stderr, "aaronjson: <stdin>: line ") != 2 ||
+		!strings.Contains(stderr, "line 2:") || !strings.Contains(stderr, "line 4:") {
+		t.Errorf("validate -ndjson: exit %d, stderr %q", code, stderr)
+	}
+	code, _, stderr = runCLI(t, "{}\n{\"a\" 1}\n", "validate", "-ndjson")
+	want = "aaronjson: <stdin>: line 2: expected ':' after key at column 6 (offset 5)\n" +
+		"    {\"a\" 1}\n" +
+		"         ^\n"
+	if code != exitError || stderr != want {
+		t.Errorf("validate -ndjson: exit %d, stderr %q, want %q", code, stderr, want)
+	}
+	// ndjson writes the values before the error as it decodes them
+	code, stdout, stderr = runCLI(t, "{\n  \"a\": 1\n}\n[2,\n 3 4]\n", "ndjson")
+	want = "aaronjson: <stdin>: expected ',' or ']' at line 5, column 4 (offset 20)\n" +
+		"     3 4]\n" +
+		"       ^\n"
+	if code != exitError || stdout != "{\"a\":1}\n" || stderr != want {
+		t.Errorf("ndjson: exit %d, stdout %q, stderr %q, want %q", code, stdout, stderr, want)
+	}
+	code, stdout, stderr = runCLI(t, input, "fmt", "-ndjson")
+	if code != exitError || stdout != "1\n" || strings.Count(stderr, "aaronjson:") != 1 {
+		t.Errorf("fmt -ndjson: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	code, _, stderr = runCLI(t, `{"a":1,"a":2}`, "validate", "-strict")
+	if code != exitError || !strings.Contains(stderr, `duplicate key "a"`) {
+		t.Errorf("validate -strict: exit %d, stderr %q", code, stderr)
+	}
+	code, _, stderr = runCLI(t, `[[[1]]]`, "validate", "-max-depth", "2")
+	if code != exitError || !strings.Contains(stderr, "depth") {
+		t.Errorf("validate -max-depth: exit %d, stderr %q", code, stderr)
+	}
+	code, _, stderr = runCLI(t, `{"a":1}`, "get", "/b")
+	if code != exitError || !strings.Contains(stderr, "key not found") {
+		t.Errorf("get missing key: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{args: nil, code: exitUsage, want: "commands:"},
+		{args: []string{"help"}, code: exitOK, want: "commands:"},
+		{args: []string{"frobnicate"}, code: exitUsage, want: `unknown command "frobnicate"`},
+		{args: []string{"fmt", "-nope"}, code: exitUsage, want: "flag provided but not defined"},
+		{args: []string{"fmt", "-raw"}, code: exitUsage, want: "flag provided but not defined"},
+		{args: []string{"ndjson", "-indent", " "}, code: exitUsage, want: "flag provided but not defined"},
+		{args: []string{"get", "-h"}, code: exitOK, want: "usage: aaronjson get"},
+		{args: []string{"get"}, code: exitUsage, want: "usage: aaronjson get"},
+		{args: []string{"get", "no-slash"}, code: exitUsage, want: "invalid JSON pointer"},
+		{args: []string{"query", "$["}, code: exitUsage, want: "invalid JSONPath"},
+	}
+
+	for _, tt := range tests {
+		code, _, stderr := runCLI(t, "", tt.args...)
+		if code != tt.code || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%q: exit %d, stderr %q; want exit %d and %q", tt.args, code, stderr, tt.code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,574 @@
+package aaronjson
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the kind of a Token.
+type TokenKind int
+
+const (
+	TokenNone TokenKind = iota
+	TokenBeginObject
+	TokenEndObject
+	TokenBeginArray
+	TokenEndArray
+	TokenKey
+	TokenString
+	TokenNumber
+	TokenBool
+	TokenNull
+)
+
+var tokenKindNames = [...]string{
+	TokenNone:        "none",
+	TokenBeginObject: "begin object",
+	TokenEndObject:   "end object",
+	TokenBeginArray:  "begin array",
+	TokenEndArray:    "end array",
+	TokenKey:         "key",
+	TokenString:      "string",
+	TokenNumber:      "number",
+	TokenBool:        "bool",
+	TokenNull:        "null",
+}
+
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// Token is a single lexical element of a JSON stream.
+type Token struct {
+	Kind TokenKind
+	// Value holds the decoded text of keys and strings, the original lexeme of
+	// numbers, and the literal input text of every other token, e.g. "{" or "true".
+	Value string
+	// Offset is the byte offset of the start of the token in the stream.
+	Offset int
+}
+
+// decoderState is what the decoder expects to read next.
+type decoderState int
+
+const (
+	stateValue       decoderState = iota // a value, or end of input at the top level
+	stateValueOrEnd                      // a value or ']' right after '['
+	stateKeyOrEnd                        // a key or '}' right after '{'
+	stateKey                             // a key after ',' in an object
+	stateColon                           // the ':' after a key
+	stateCommaOrEnd                      // ',' or the end of the enclosing container
+)
+
+// minRead is the smallest amount of free buffer space offered to the reader.
+const minRead = 4096
+
+// Decoder reads JSON values from an input stream one token at a time.
+// Only the token being read, or the value being decoded, is held in memory,
+// so arbitrarily large documents can be walked with constant memory.
+//
+// The stream may hold any number of top-level values separated by whitespace.
+// Errors report byte offsets into the stream; syntax errors also give the
+// line and column.
+type Decoder struct {
+	r      io.Reader
+	rerr   error  // error returned by the last read, io.EOF at end of input
+	buf    []byte // buffered input
+	pos    int    // start of the unread input in buf
+	mark   int    // start of the value being captured by Decode, or -1
+	offset int    // stream offset of buf[0]
+	line   int    // newlines in the input discarded before buf[0]
+	column int    // characters discarded since the last of those newlines
+
+	p     *parser
+	stack []byte // '{' or '[' for every open container
+	state decoderState
+	tok   Token
+	err   error // sticky error, io.EOF at the end of the stream
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, ParseOptions{})
+}
+
+// NewDecoderWithOptions returns a new decoder that reads from r using the given options.
+// MaxBytes limits the size of each value materialized by Decode rather than the whole stream.
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions) *Decoder {
+	return &Decoder{
+		r:    r,
+		mark: -1,
+		p:    newParser(opts),
+	}
+}
+
+// Next advances the decoder to the next token, which is then available
+// through Token. It returns false at the end of the input or when an error
+// occurs; Err reports which.
+func (d *Decoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	tok, err := d.readToken()
+	if err != nil {
+		d.err = d.withContext(err)
+		return false
+	}
+	d.tok = tok
+	return true
+}
+
+// Token returns the token read by the most recent call to Next.
+func (d *Decoder) Token() Token {
+	return d.tok
+}
+
+// Err returns the first error encountered by the decoder, or nil if the
+// input ended cleanly.
+func (d *Decoder) Err() error {
+	if d.err == io.EOF {
+		return nil
+	}
+	return d.err
+}
+
+// More reports whether another value, key or top-level value follows before
+// the end of the enclosing container or the input.
+func (d *Decoder) More() bool {
+	if d.err != nil || d.prepare() != nil {
+		return false
+	}
+	c := d.buf[d.pos]
+	return c != '}' && c != ']'
+}
+
+// InputOffset returns the stream offset just past the most recently read token.
+func (d *Decoder) InputOffset() int {
+	return d.offset + d.pos
+}
+
+// Decode reads the next complete value from the stream and returns it as a
+// JsonValue tree. It can be mixed freely with Next, for example to decode the
+// elements of a huge array one at a time after reading its TokenBeginArray.
+// It returns io.EOF when no top-level values remain.
+func (d *Decoder) Decode() (JsonValue, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := d.expectValue(); err != nil {
+		return nil, err
+	}
+
+	d.mark = d.pos
+	defer func() { d.mark = -1 }()
+	if err := d.skipValue(); err != nil {
+		d.err = d.withContext(err)
+		return nil, d.err
+	}
+
+	raw := d.buf[d.mark:d.pos]
+	value, _, err := d.p.parseValue(raw, 0)
+	if err != nil {
+		d.err = d.withContext(shiftErrorOffset(err, d.offset+d.mark))
+		return nil, d.err
+	}
+	return value, nil
+}
+
+// Skip reads past the next complete value without building it.
+// It returns io.EOF when no top-level values remain.
+func (d *Decoder) Skip() error {
+	if d.err != nil {
+		return d.err
+	}
+	if err := d.expectValue(); err != nil {
+		return err
+	}
+	if err := d.skipValue(); err != nil {
+		d.err = d.withContext(err)
+		return d.err
+	}
+	return nil
+}
+
+// expectValue positions the decoder at the start of the next value.
+// It fails without consuming anything if the next token is a key or the end
+// of a container.
+func (d *Decoder) expectValue() error {
+	if err := d.prepare(); err != nil {
+		d.err = d.withContext(err)
+		return d.err
+	}
+	switch d.state {
+	case stateKeyOrEnd, stateKey:
+		return fmt.Errorf("cannot decode value at offset %d: next token is a key or '}'", d.offset+d.pos)
+	case stateCommaOrEnd:
+		return fmt.Errorf("cannot decode value at offset %d: next token ends a container", d.offset+d.pos)
+	case stateValueOrEnd:
+		if d.buf[d.pos] == ']' {
+			return fmt.Errorf("cannot decode value at offset %d: next token ends a container", d.offset+d.pos)
+		}
+	}
+	return nil
+}
+
+// skipValue reads the tokens of the value starting at the current position.
+func (d *Decoder) skipValue() error {
+	depth := len(d.stack)
+	for {
+		if _, err := d.readToken(); err != nil {
+			if err == io.EOF {
+				err = newSyntaxError(d.offset+d.pos, "unexpected end of data")
+			}
+			return err
+		}
+		if d.mark >= 0 && d.p.opts.MaxBytes > 0 && d.pos-d.mark > d.p.opts.MaxBytes {
+			return newLimitError(ErrMaxBytesExceeded, d.p.opts.MaxBytes, d.offset+d.mark+d.p.opts.MaxBytes)
+		}
+		if len(d.stack) == depth {
+			return nil
+		}
+	}
+}
+
+// readToken reads the token at the next position and advances past it.
+func (d *Decoder) readToken() (Token, error) {
+	if err := d.prepare(); err != nil {
+		return Token{}, err
+	}
+
+	start := d.offset + d.pos
+	c := d.buf[d.pos]
+	switch d.state {
+	case stateKeyOrEnd, stateKey:
+		if c == '}' && d.state == stateKeyOrEnd {
+			return d.endContainer(TokenEndObject, "}"), nil
+		}
+		if c != '"' {
+			return Token{}, newSyntaxError(start, "expected string key")
+		}
+		key, err := d.readString()
+		if err != nil {
+			return Token{}, err
+		}
+		if key == "" && d.p.opts.DisallowEmptyKeys {
+			return Token{}, newSyntaxError(start, "empty key")
+		}
+		d.state = stateColon
+		return Token{Kind: TokenKey, Value: key, Offset: start}, nil
+	case stateCommaOrEnd:
+		// prepare stops here only at the end of a container
+		if c == '}' {
+			return d.endContainer(TokenEndObject, "}"), nil
+		}
+		return d.endContainer(TokenEndArray, "]"), nil
+	case stateValueOrEnd:
+		if c == ']' {
+			return d.endContainer(TokenEndArray, "]"), nil
+		}
+	}
+
+	switch c {
+	case '{', '[':
+		if d.p.maxDepth > 0 && len(d.stack) >= d.p.maxDepth {
+			return Token{}, newLimitError(ErrMaxDepthExceeded, d.p.maxDepth, start)
+		}
+		d.stack = append(d.stack, c)
+		d.pos++
+		if c == '{' {
+			d.state = stateKeyOrEnd
+			return Token{Kind: TokenBeginObject, Value: "{", Offset: start}, nil
+		}
+		d.state = stateValueOrEnd
+		return Token{Kind: TokenBeginArray, Value: "[", Offset: start}, nil
+	case '"':
+		s, err := d.readString()
+		if err != nil {
+			return Token{}, err
+		}
+		d.afterValue()
+		return Token{Kind: TokenString, Value: s, Offset: start}, nil
+	case 't', 'f', 'n':
+		d.ensure(6) // the longest literal plus its terminator
+		var n int
+		var err error
+		kind := TokenBool
+		if c == 'n' {
+			kind = TokenNull
+			_, n, err = parseNull(d.buf[d.pos:], 0)
+		} else {
+			_, n, err = parseBool(d.buf[d.pos:], 0)
+		}
+		if err != nil {
+			return Token{}, d.inputError(err, start)
+		}
+		lit := string(d.buf[d.pos : d.pos+n])
+		d.pos += n
+		d.afterValue()
+		return Token{Kind: kind, Value: lit, Offset: start}, nil
+	default:
+		if (c >= '0' && c <= '9') || c == '-' || c == '+' {
+			lexeme, err := d.readNumber()
+			if err != nil {
+				return Token{}, err
+			}
+			d.afterValue()
+			return Token{Kind: TokenNumber, Value: lexeme, Offset: start}, nil
+		}
+		return Token{}, newSyntaxError(start, "invalid character %s looking for beginning of value", quoteChar(c))
+	}
+}
+
+// prepare skips whitespace and the ':' and ',' separators expected in the
+// current state, leaving the decoder at the start of the next token.
+// It returns io.EOF at the end of the input between top-level values.
+func (d *Decoder) prepare() error {
+	for {
+		if !d.skipSpace() {
+			if d.rerr != io.EOF {
+				return d.rerr
+			}
+			return d.endOfInputError()
+		}
+
+		c := d.buf[d.pos]
+		switch d.state {
+		case stateColon:
+			if c != ':' {
+				return newSyntaxError(d.offset+d.pos, "expected ':' after key")
+			}
+			d.pos++
+			d.state = stateValue
+			continue
+		case stateCommaOrEnd:
+			top := d.stack[len(d.stack)-1]
+			switch {
+			case c == ',':
+				d.pos++
+				d.state = stateValue
+				if top == '{' {
+					d.state = stateKey
+				}
+				continue
+			case top == '{' && c != '}':
+				return newSyntaxError(d.offset+d.pos, "expected ',' or '}'")
+			case top == '[' && c != ']':
+				return newSyntaxError(d.offset+d.pos, "expected ',' or ']'")
+			}
+		}
+		return nil
+	}
+}
+
+// endOfInputError returns the error for input ending in the current state.
+func (d *Decoder) endOfInputError() error {
+	if len(d.stack) == 0 {
+		return io.EOF
+	}
+	if d.state == stateColon {
+		return newSyntaxError(d.offset+d.pos, "expected ':' after key")
+	}
+	if d.stack[len(d.stack)-1] == '{' {
+		return newSyntaxError(d.offset+d.pos, "unexpected end of data while parsing object")
+	}
+	return newSyntaxError(d.offset+d.pos, "unexpected end of data while parsing array")
+}
+
+// endContainer consumes the closing delimiter of the innermost container.
+func (d *Decoder) endContainer(kind TokenKind, value string) Token {
+	tok := Token{Kind: kind, Value: value, Offset: d.offset + d.pos}
+	d.pos++
+	d.stack = d.stack[:len(d.stack)-1]
+	d.afterValue()
+	return tok
+}
+
+// afterValue updates the state once a complete value has been read.
+func (d *Decoder) afterValue() {
+	if len(d.stack) == 0 {
+		d.state = stateValue
+	} else {
+		d.state = stateCommaOrEnd
+	}
+}
+
+// readString reads and decodes the string token at the current position.
+func (d *Decoder) readString() (string, error) {
+	// Find the closing quote, skipping escaped characters
+	n := 1
+	for {
+		for ; d.pos+n < len(d.buf); n++ {
+			if c := d.buf[d.pos+n]; c == '\\' {
+				n++
+			} else if c == '"' {
+				break
+			}
+		}
+		if d.pos+n < len(d.buf) || !d.fill() {
+			break
+		}
+	}
+
+	// The parser decodes the string, or reports what is wrong with it
+	value, end, err := d.p.parseString(d.buf[d.pos:], 0)
+	if err != nil {
+		return "", d.inputError(err, d.offset+d.pos)
+	}
+	d.pos += end
+	return value.(*JsonString).data, nil
+}
+
+// readNumber reads and validates the number token at the current position.
+func (d *Decoder) readNumber() (string, error) {
+	n := 0
+	for {
+		for ; d.pos+n < len(d.buf) && isNumberChar(d.buf[d.pos+n]); n++ {
+		}
+		if d.pos+n < len(d.buf) || !d.fill() {
+			break
+		}
+	}
+
+	_, end, err := d.p.parseNumber(d.buf[d.pos:], 0)
+	if err != nil {
+		return "", d.inputError(err, d.offset+d.pos)
+	}
+	lexeme := string(d.buf[d.pos : d.pos+end])
+	d.pos += end
+	return lexeme, nil
+}
+
+// isNumberChar reports whether c can appear in a number lexeme.
+func isNumberChar(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// skipSpace advances past whitespace, reading more input as needed.
+// It returns false if the input ends first.
+func (d *Decoder) skipSpace() bool {
+	for {
+		d.pos = skipWhitespace(d.buf, d.pos)
+		if d.pos < len(d.buf) {
+			return true
+		}
+		if !d.fill() {
+			return false
+		}
+	}
+}
+
+// ensure reads input until at least n bytes follow the current position.
+// It returns false if the input ends first.
+func (d *Decoder) ensure(n int) bool {
+	for len(d.buf)-d.pos < n {
+		if !d.fill() {
+			return false
+		}
+	}
+	return true
+}
+
+// fill reads more input into the buffer, first discarding the bytes before
+// the current token, or before the value being captured by Decode.
+// It returns false once the reader has no more data.
+func (d *Decoder) fill() bool {
+	if d.rerr != nil {
+		return false
+	}
+
+	keep := d.pos
+	if d.mark >= 0 {
+		keep = d.mark
+	}
+	if keep > 0 {
+		for _, c := range d.buf[:keep] {
+			switch {
+			case c == '\n':
+				d.line++
+				d.column = 0
+			case utf8.RuneStart(c):
+				d.column++
+			}
+		}
+		n := copy(d.buf, d.buf[keep:])
+		d.buf = d.buf[:n]
+		d.offset += keep
+		d.pos -= keep
+		if d.mark >= 0 {
+			d.mark -= keep
+		}
+	}
+
+	if cap(d.buf)-len(d.buf) < minRead {
+		buf := make([]byte, len(d.buf), 2*cap(d.buf)+minRead)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err != nil {
+		d.rerr = err
+	}
+	return n > 0 || err == nil
+}
+
+// withContext adds the line, column and snippet to a syntax error or the
+// lines and columns to a duplicate key error, counting the input already
+// discarded from the buffer. The snippet shows only the buffered part of
+// the offending line.
+func (d *Decoder) withContext(err error) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		if e.Offset < d.offset {
+			break
+		}
+		e.Offset -= d.offset
+		e.withContext(d.buf)
+		e.Offset += d.offset
+		e.Line, e.Column = d.position(e.Offset)
+	case *DuplicateKeyError:
+		if e.FirstOffset < d.offset {
+			break
+		}
+		e.FirstLine, e.FirstColumn = d.position(e.FirstOffset)
+		e.Line, e.Column = d.position(e.Offset)
+	}
+	return err
+}
+
+// position returns the 1-based line and column of a stream offset within the buffer.
+func (d *Decoder) position(offset int) (line, column int) {
+	line, column, _ = lineColumn(d.buf, offset-d.offset)
+	if line == 1 {
+		column += d.column
+	}
+	return line + d.line, column
+}
+
+// inputError returns the read error if the input was cut short by one,
+// and otherwise the parse error err found at stream offset base.
+func (d *Decoder) inputError(err error, base int) error {
+	if d.rerr != nil && d.rerr != io.EOF {
+		return d.rerr
+	}
+	return shiftErrorOffset(err, base)
+}
+
+// shiftErrorOffset moves the offsets of a parse error by base, turning
+// offsets into a slice of the input into offsets into the whole stream.
+func shiftErrorOffset(err error, base int) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		e.Offset += base
+	case *LimitError:
+		e.Offset += base
+	case *DuplicateKeyError:
+		e.Offset += base
+		e.FirstOffset += base
+	}
+	return err
+}
@@ -0,0 +1,298 @@
+package aaronjson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoderTokens(t *testing.T) {
+	input := `{"name": "Jo\"hn", "tags": [1, -2.5e3, true, null], "": {}} "next"`
+	want := []Token{
+		{Kind: TokenBeginObject, Value: "{", Offset: 0},
+		{Kind: TokenKey, Value: "name", Offset: 1},
+		{Kind: TokenString, Value: `Jo"hn`, Offset: 9},
+		{Kind: TokenKey, Value: "tags", Offset: 19},
+		{Kind: TokenBeginArray, Value: "[", Offset: 27},
+		{Kind: TokenNumber, Value: "1", Offset: 28},
+		{Kind: TokenNumber, Value: "-2.5e3", Offset: 31},
+		{Kind: TokenBool, Value: "true", Offset: 39},
+		{Kind: TokenNull, Value: "null", Offset: 45},
+		{Kind: TokenEndArray, Value: "]", Offset: 49},
+		{Kind: TokenKey, Value: "", Offset: 52},
+		{Kind: TokenBeginObject, Value: "{", Offset: 56},
+		{Kind: TokenEndObject, Value: "}", Offset: 57},
+		{Kind: TokenEndObject, Value: "}", Offset: 58},
+		{Kind: TokenString, Value: "next", Offset: 60},
+	}
+
+	// Reading one byte at a time exercises every buffer refill path
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	var got []Token
+	for dec.Next() {
+		got = append(got, dec.Token())
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d tokens, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	input := `{"total": 3, "items": [{"id": 1}, {"id": 2, "skip": [1, [2]]}, {"id": 3}]}`
+	dec := NewDecoder(iotest.HalfReader(strings.NewReader(input)))
+
+	// Walk to the items array, then decode its elements one at a time
+	for dec.Next() {
+		if tok := dec.Token(); tok.Kind == TokenKey && tok.Value == "items" {
+			break
+		}
+	}
+	if !dec.Next() || dec.Token().Kind != TokenBeginArray {
+		t.Fatalf("expected begin array, got %+v (err %v)", dec.Token(), dec.Err())
+	}
+
+	var items []string
+	for dec.More() {
+		item, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		items = append(items, item.String())
+	}
+	want := []string{`{"id":1}`, `{"id":2,"skip":[1,[2]]}`, `{"id":3}`}
+	if strings.Join(items, " ") != strings.Join(want, " ") {
+		t.Errorf("Decode() items = %v, want %v", items, want)
+	}
+
+	for _, kind := range []TokenKind{TokenEndArray, TokenEndObject} {
+		if !dec.Next() || dec.Token().Kind != kind {
+			t.Fatalf("expected %v, got %+v (err %v)", kind, dec.Token(), dec.Err())
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode() at end error = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1 {\"a\": [true]}\n\"x\"  "))
+	var got []string
+	for {
+		value, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		got = append(got, value.String())
+	}
+	if want := `1 {"a":[true]} "x"`; strings.Join(got, " ") != want {
+		t.Errorf("Decode() values = %v, want %v", got, want)
+	}
+}
+
+func TestDecoderSkip(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[{"big": [1, 2, {"c": 3}]}, 42]`))
+	if !dec.Next() {
+		t.Fatalf("Next() error = %v", dec.Err())
+	}
+	if err := dec.Skip(); err != nil {
+		t.Fatalf("Skip() error = %v", err)
+	}
+	value, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if i, _ := value.AsInt(); i != 42 {
+		t.Errorf("Decode() after Skip() = %v, want 42", value)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       ParseOptions
+		wantErr    error
+		wantOffset int
+	}{
+		{
+			name:       "missing colon",
+			input:      `{"a" 1}`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 5,
+		},
+		{
+			name:       "missing comma",
+			input:      `[1 2]`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 3,
+		},
+		{
+			name:       "mismatched delimiter",
+			input:      `{"a": 1]`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 7,
+		},
+		{
+			name:       "trailing comma",
+			input:      `[1,]`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 3,
+		},
+		{
+			name:       "unterminated string",
+			input:      `["abc`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 1,
+		},
+		{
+			name:       "truncated object",
+			input:      `{"a": 1`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 7,
+		},
+		{
+			name:       "invalid literal",
+			input:      `[tru]`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 1,
+		},
+		{
+			name:       "invalid number",
+			input:      `[01]`,
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 1,
+		},
+		{
+			name:       "depth limit",
+			input:      `[[[1]]]`,
+			opts:       ParseOptions{MaxDepth: 2},
+			wantErr:    ErrMaxDepthExceeded,
+			wantOffset: 2,
+		},
+		{
+			name:       "empty key in strict mode",
+			input:      `{"": 1}`,
+			opts:       ParseOptions{DisallowEmptyKeys: true},
+			wantErr:    ErrInvalidJsonFormat,
+			wantOffset: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoderWithOptions(iotest.OneByteReader(strings.NewReader(tt.input)), tt.opts)
+			for dec.Next() {
+			}
+			err := dec.Err()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Err() = %v, want %v", err, tt.wantErr)
+			}
+			var offset int
+			var se *SyntaxError
+			var le *LimitError
+			switch {
+			case errors.As(err, &se):
+				offset = se.Offset
+			case errors.As(err, &le):
+				offset = le.Offset
+			}
+			if offset != tt.wantOffset {
+				t.Errorf("error offset = %d, want %d (%v)", offset, tt.wantOffset, err)
+			}
+		})
+	}
+}
+
+func TestDecoderErrorPosition(t *testing.T) {
+	tests := []struct {
+		input   string
+		decode  bool
+		line    int
+		column  int
+		snippet string
+	}{
+		{input: "[1,\n  2,\n  x]", line: 3, column: 3, snippet: "x\n^"},
+		{input: "[\"é\", 1, 2 3]", line: 1, column: 12, snippet: "3\n^"},
+		{input: "1\n{\"a\":\n [1}", decode: true, line: 3, column: 4, snippet: " [1}\n   ^"},
+	}
+
+	for _, tt := range tests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.input)))
+		var err error
+		if tt.decode {
+			for err == nil {
+				_, err = dec.Decode()
+			}
+		} else {
+			for dec.Next() {
+			}
+			err = dec.Err()
+		}
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: error = %v, want a SyntaxError", tt.input, err)
+			continue
+		}
+		if se.Line != tt.line || se.Column != tt.column || se.Snippet != tt.snippet {
+			t.Errorf("%q: error at line %d, column %d, snippet %q, want %d, %d, %q",
+				tt.input, se.Line, se.Column, se.Snippet, tt.line, tt.column, tt.snippet)
+		}
+	}
+}
+
+func TestDecoderDecodeErrors(t *testing.T) {
+	input := `[{"a": 1}, {"a": 2, "a": 3}]`
+	dec := NewDecoderWithOptions(strings.NewReader(input), ParseOptions{DuplicateKeys: DuplicateKeyReject})
+	if !dec.Next() {
+		t.Fatalf("Next() error = %v", dec.Err())
+	}
+	if _, err := dec.Decode(); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	_, err := dec.Decode()
+	var de *DuplicateKeyError
+	if !errors.As(err, &de) {
+		t.Fatalf("Decode() error = %v, want DuplicateKeyError", err)
+	}
+	if de.FirstOffset != 12 || de.Offset != 20 {
+		t.Errorf("DuplicateKeyError offsets = %d, %d, want 12, 20", de.FirstOffset, de.Offset)
+	}
+	if de.FirstColumn != 13 || de.Column != 21 {
+		t.Errorf("DuplicateKeyError columns = %d, %d, want 13, 21", de.FirstColumn, de.Column)
+	}
+
+	dec = NewDecoderWithOptions(strings.NewReader(`["abcdef", 1]`), ParseOptions{MaxBytes: 4})
+	dec.Next()
+	if _, err := dec.Decode(); !errors.Is(err, ErrMaxBytesExceeded) {
+		t.Errorf("Decode() error = %v, want ErrMaxBytesExceeded", err)
+	}
+
+	dec = NewDecoder(strings.NewReader(`{"a": 1}`))
+	dec.Next()
+	if _, err := dec.Decode(); err == nil {
+		t.Error("Decode() before a key should return an error")
+	}
+}
+
+func TestDecoderReadError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	dec := NewDecoder(io.MultiReader(strings.NewReader(`[1, "ab`), iotest.ErrReader(readErr)))
+	for dec.Next() {
+	}
+	if !errors.Is(dec.Err(), readErr) {
+		t.Errorf("Err() = %v, want %v", dec.Err(), readErr)
+	}
+}
@@ -0,0 +1,154 @@
+package aaronjson
+
+import (
+	"bufio"
+	"io"
+)
+
+// Encoder writes JsonValue trees to an output stream.
+// Output goes through a buffered writer directly, so large documents are never
+// materialized in memory as a whole.
+type Encoder struct {
+	w       *bufio.Writer
+	pretty  bool
+	opts    PrettyOptions
+	scratch []byte
+}
+
+// NewEncoder returns a new encoder that writes to w.
+// By default the encoder produces compact output.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// SetIndent makes the encoder put every array element and object member on
+// its own line, beginning with prefix followed by one copy of indent per
+// nesting level. Calling SetIndent("", "") restores compact output.
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.pretty = prefix != "" || indent != ""
+	enc.opts = PrettyOptions{
+		Prefix:          prefix,
+		Indent:          indent,
+		KeyOrder:        enc.opts.KeyOrder,
+		SpaceAfterColon: true,
+	}
+}
+
+// SetKeyOrder selects the order in which object members are written.
+// By default members are written in insertion order.
+func (enc *Encoder) SetKeyOrder(order KeyOrder) {
+	enc.opts.KeyOrder = order
+}
+
+// SetPrettyOptions makes the encoder pretty-print values using opts.
+// Encode always terminates a value with a newline, so opts.TrailingNewline is ignored.
+func (enc *Encoder) SetPrettyOptions(opts PrettyOptions) {
+	enc.pretty = true
+	enc.opts = opts
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline,
+// and flushes the buffered output. It returns the first write error encountered.
+func (enc *Encoder) Encode(v JsonValue) error {
+	enc.writeValue(v, 0, 0)
+	_ = enc.w.WriteByte('\n')
+	return enc.w.Flush()
+}
+
+// encodeValue writes v without a trailing newline and flushes the buffered output.
+func (enc *Encoder) encodeValue(v JsonValue) error {
+	enc.writeValue(v, 0, 0)
+	return enc.w.Flush()
+}
+
+// writeValue writes v at the given nesting depth, starting at the given column.
+// Write errors are sticky in bufio.Writer and are reported by the final Flush.
+func (enc *Encoder) writeValue(v JsonValue, depth, column int) {
+	switch jv := v.(type) {
+	case *JsonObject:
+		if len(jv.keys) == 0 {
+			_, _ = enc.w.WriteString("{}")
+			return
+		}
+		if enc.writeInline(v, column) {
+			return
+		}
+		_ = enc.w.WriteByte('{')
+		for i, key := range enc.keys(jv) {
+			if i > 0 {
+				_ = enc.w.WriteByte(',')
+			}
+			memberColumn := enc.writeNewline(depth + 1)
+			enc.scratch = appendQuotedString(enc.scratch[:0], key)
+			enc.scratch = append(enc.scratch, ':')
+			if enc.pretty && enc.opts.SpaceAfterColon {
+				enc.scratch = append(enc.scratch, ' ')
+			}
+			_, _ = enc.w.Write(enc.scratch)
+			enc.writeValue(jv.data[key], depth+1, memberColumn+len(enc.scratch))
+		}
+		enc.writeNewline(depth)
+		_ = enc.w.WriteByte('}')
+	case *JsonArray:
+		if len(jv.data) == 0 {
+			_, _ = enc.w.WriteString("[]")
+			return
+		}
+		if enc.writeInline(v, column) {
+			return
+		}
+		_ = enc.w.WriteByte('[')
+		for i, item := range jv.data {
+			if i > 0 {
+				_ = enc.w.WriteByte(',')
+			}
+			itemColumn := enc.writeNewline(depth + 1)
+			enc.writeValue(item, depth+1, itemColumn)
+		}
+		enc.writeNewline(depth)
+		_ = enc.w.WriteByte(']')
+	default:
+		enc.scratch = appendCompact(enc.scratch[:0], v)
+		_, _ = enc.w.Write(enc.scratch)
+	}
+}
+
+// keys returns the object keys in the order the encoder writes them.
+func (enc *Encoder) keys(obj *JsonObject) []string {
+	return orderedKeys(obj, enc.opts.KeyOrder)
+}
+
+// writeInline writes a container on a single line if the pretty options allow
+// it and the line fits within MaxInlineWidth. It reports whether it wrote v.
+func (enc *Encoder) writeInline(v JsonValue, column int) bool {
+	if !enc.pretty || enc.opts.MaxInlineWidth <= 0 {
+		return false
+	}
+	limit := enc.opts.MaxInlineWidth - column
+	if limit <= 0 {
+		return false
+	}
+	buf, ok := appendInline(enc.scratch[:0], v, &enc.opts, limit)
+	enc.scratch = buf
+	if !ok {
+		return false
+	}
+	_, _ = enc.w.Write(buf)
+	return true
+}
+
+// writeNewline starts a new line indented for the given depth when pretty
+// printing is enabled, and returns the column at which the line continues.
+func (enc *Encoder) writeNewline(depth int) int {
+	if !enc.pretty {
+		return 0
+	}
+	_ = enc.w.WriteByte('\n')
+	_, _ = enc.w.WriteString(enc.opts.Prefix)
+	for i := 0; i < depth; i++ {
+		_, _ = enc.w.WriteString(enc.opts.Indent)
+	}
+	return len(enc.opts.Prefix) + depth*len(enc.opts.Indent)
+}
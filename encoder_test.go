@@ -0,0 +1,85 @@
+package aaronjson
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestEncoderCompact(t *testing.T) {
+	parsed, err := Parse(`{"b": [1, 2.5, "x"], "a": {"c": null}}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(parsed); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := enc.Encode(NewJsonInt(7)); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := "{\"b\":[1,2.5,\"x\"],\"a\":{\"c\":null}}\n7\n"
+	if buf.String() != want {
+		t.Errorf("Encode() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncoderIndent(t *testing.T) {
+	parsed, err := Parse(`{"name": "John", "tags": ["a", "b"], "empty": {}}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent(">", "\t")
+	enc.SetKeyOrder(KeyOrderSorted)
+	if err := enc.Encode(parsed); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := "{\n>\t\"empty\": {},\n>\t\"name\": \"John\",\n>\t\"tags\": [\n>\t\t\"a\",\n>\t\t\"b\"\n>\t]\n>}\n"
+	if buf.String() != want {
+		t.Errorf("Encode() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncoderWriteError(t *testing.T) {
+	writeErr := errors.New("disk full")
+	enc := NewEncoder(&failingWriter{err: writeErr})
+
+	arr := NewJsonArray()
+	for i := 0; i < 10000; i++ {
+		_, _ = arr.Append(NewJsonString("some reasonably long value"))
+	}
+	if err := enc.Encode(arr); !errors.Is(err, writeErr) {
+		t.Errorf("Encode() error = %v, want %v", err, writeErr)
+	}
+}
+
+func TestEncoderMatchesCompact(t *testing.T) {
+	parsed, err := Parse(`[{"k": "v\n", "n": -1.5e-9}, [true, false, null], 18446744073709551615]`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(parsed); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if strings.TrimSuffix(buf.String(), "\n") != string(Compact(parsed)) {
+		t.Errorf("Encode() = %s, want %s", buf.String(), Compact(parsed))
+	}
+}
@@ -0,0 +1,246 @@
+package aaronjson
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Equal reports whether a and b represent the same JSON value.
+// Objects are equal when they hold the same keys with equal values, regardless
+// of key order, and arrays when their elements are pairwise equal. Numbers are
+// compared by value, so a JsonInt, JsonFloat and JsonNumber holding the same
+// number are equal.
+func Equal(a, b JsonValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	switch av := a.(type) {
+	case *JsonObject:
+		bv, ok := b.(*JsonObject)
+		if !ok || len(av.data) != len(bv.data) {
+			return false
+		}
+		for key, value := range av.data {
+			other, exists := bv.data[key]
+			if !exists || !Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case *JsonArray:
+		bv, ok := b.(*JsonArray)
+		if !ok || len(av.data) != len(bv.data) {
+			return false
+		}
+		for i := range av.data {
+			if !Equal(av.data[i], bv.data[i]) {
+				return false
+			}
+		}
+		return true
+	case *JsonString:
+		bv, ok := b.(*JsonString)
+		return ok && av.data == bv.data
+	case *JsonBool:
+		bv, ok := b.(*JsonBool)
+		return ok && av.data == bv.data
+	case *JsonNull:
+		return b.IsNull()
+	case *JsonInt, *JsonFloat, *JsonNumber:
+		return compareNumbers(a, b) == 0
+	default:
+		return a == b
+	}
+}
+
+// Compare orders any two JSON values and returns -1, 0 or +1. Values of
+// different kinds are ordered null < false < true < numbers < strings <
+// arrays < objects. Numbers compare by value, with NaN below every other
+// number; strings compare by code point; arrays compare element by element.
+// Objects compare first by their sorted key sets and then by the values of
+// those keys in sorted order. Compare reports 0 exactly when Equal reports
+// true, except for NaN, which Compare treats as equal to itself.
+func Compare(a, b JsonValue) int {
+	if ra, rb := kindRank(a), kindRank(b); ra != rb {
+		return compareOrdered(int64(ra), int64(rb))
+	}
+
+	switch av := a.(type) {
+	case *JsonObject:
+		bv := b.(*JsonObject)
+		akeys, bkeys := sortedKeys(av), sortedKeys(bv)
+		for i := 0; i < len(akeys) && i < len(bkeys); i++ {
+			if c := strings.Compare(akeys[i], bkeys[i]); c != 0 {
+				return c
+			}
+		}
+		if len(akeys) != len(bkeys) {
+			return compareOrdered(int64(len(akeys)), int64(len(bkeys)))
+		}
+		for _, key := range akeys {
+			if c := Compare(av.data[key], bv.data[key]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case *JsonArray:
+		bv := b.(*JsonArray)
+		for i := 0; i < len(av.data) && i < len(bv.data); i++ {
+			if c := Compare(av.data[i], bv.data[i]); c != 0 {
+				return c
+			}
+		}
+		return compareOrdered(int64(len(av.data)), int64(len(bv.data)))
+	case *JsonString:
+		// Byte order of UTF-8 matches code point order
+		return strings.Compare(av.data, b.(*JsonString).data)
+	case *JsonInt, *JsonFloat, *JsonNumber:
+		if c := compareNumbers(a, b); c != 2 {
+			return c
+		}
+		aNaN, bNaN := isNaN(a), isNaN(b)
+		switch {
+		case aNaN && bNaN:
+			return 0
+		case aNaN:
+			return -1
+		default:
+			return 1
+		}
+	default:
+		return 0
+	}
+}
+
+// kindRank returns the position of the kind of v in the order used by Compare.
+func kindRank(v JsonValue) int {
+	switch tv := v.(type) {
+	case nil, *JsonNull:
+		return 0
+	case *JsonBool:
+		if tv.data {
+			return 2
+		}
+		return 1
+	case *JsonInt, *JsonFloat, *JsonNumber:
+		return 3
+	case *JsonString:
+		return 4
+	case *JsonArray:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// sortedKeys returns the keys of obj in ascending order.
+func sortedKeys(obj *JsonObject) []string {
+	keys := make([]string, len(obj.keys))
+	copy(keys, obj.keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// isNaN reports whether v is a NaN float.
+func isNaN(v JsonValue) bool {
+	f, ok := floatData(v)
+	return ok && math.IsNaN(f)
+}
+
+// floatData returns the value of a JsonFloat, or of a JsonInt that
+// NewJsonInt kept as a float64.
+func floatData(v JsonValue) (float64, bool) {
+	switch nv := v.(type) {
+	case *JsonFloat:
+		return nv.data, true
+	case *JsonInt:
+		return nv.fdata, nv.float
+	default:
+		return 0, false
+	}
+}
+
+// compareNumbers compares two numeric JsonValues by value and returns -1, 0
+// or +1. It returns 2 if either value is not a number or is NaN.
+func compareNumbers(a, b JsonValue) int {
+	// Fast paths for the common representations
+	switch av := a.(type) {
+	case *JsonInt:
+		if bv, ok := b.(*JsonInt); ok && !av.unsigned && !bv.unsigned && !av.float && !bv.float {
+			return compareOrdered(av.data, bv.data)
+		}
+	case *JsonFloat:
+		if bv, ok := b.(*JsonFloat); ok {
+			if math.IsNaN(av.data) || math.IsNaN(bv.data) {
+				return 2
+			}
+			return compareOrdered(av.data, bv.data)
+		}
+	}
+
+	ar, ok := numberToRat(a)
+	if !ok {
+		return 2
+	}
+	br, ok := numberToRat(b)
+	if !ok {
+		return 2
+	}
+	if ar == nil || br == nil {
+		// At least one side is an infinite float
+		return compareOrdered(infSign(a), infSign(b))
+	}
+	return ar.Cmp(br)
+}
+
+// numberToRat returns the exact value of a numeric JsonValue.
+// Infinite floats report ok with a nil result; non-numbers and NaN report !ok.
+func numberToRat(v JsonValue) (*big.Rat, bool) {
+	if f, ok := floatData(v); ok {
+		if math.IsNaN(f) {
+			return nil, false
+		}
+		if math.IsInf(f, 0) {
+			return nil, true
+		}
+		return new(big.Rat).SetFloat64(f), true
+	}
+	switch nv := v.(type) {
+	case *JsonInt:
+		if nv.unsigned {
+			return new(big.Rat).SetInt(new(big.Int).SetUint64(nv.udata)), true
+		}
+		return new(big.Rat).SetInt64(nv.data), true
+	case *JsonNumber:
+		r, err := nv.AsBigRat()
+		return r, err == nil
+	default:
+		return nil, false
+	}
+}
+
+// infSign returns +1 or -1 for infinite floats and 0 for any other value.
+func infSign(v JsonValue) int64 {
+	if f, ok := floatData(v); ok && math.IsInf(f, 0) {
+		if f > 0 {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// compareOrdered returns -1, 0 or +1 depending on the order of a and b.
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -1,6 +1,11 @@
 package aaronjson
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 var (
 	ErrEmptyData                 = errors.New("empty data")
@@ -16,4 +21,238 @@ var (
 	ErrUnmarshalTargetNotPointer   = errors.New("unmarshal target must be a pointer")
 	ErrUnmarshalTargetNotSettable  = errors.New("unmarshal target cannot be set")
 	ErrUnmarshalTargetTypeMismatch = errors.New("unmarshal target type mismatch")
+
+	ErrMaxDepthExceeded         = errors.New("maximum nesting depth exceeded")
+	ErrMaxBytesExceeded         = errors.New("maximum input size exceeded")
+	ErrMaxStringLengthExceeded  = errors.New("maximum string length exceeded")
+	ErrMaxObjectMembersExceeded = errors.New("maximum object member count exceeded")
+	ErrMaxArrayElementsExceeded = errors.New("maximum array element count exceeded")
+
+	ErrDuplicateKey = errors.New("duplicate object key")
+
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	ErrPathMismatch   = errors.New("path segment does not match value")
+
+	ErrInvalidJSONPath = errors.New("invalid JSONPath expression")
+
+	ErrInvalidPatch    = errors.New("invalid JSON patch")
+	ErrPatchTestFailed = errors.New("JSON patch test failed")
+
+	ErrMergePatchNull = errors.New("null member cannot be expressed in a JSON merge patch")
 )
+
+// SyntaxError describes malformed JSON input and where it was found.
+// It matches ErrInvalidJsonFormat with errors.Is.
+type SyntaxError struct {
+	Msg     string // description of the problem
+	Offset  int    // byte offset of the problem in the input
+	Line    int    // 1-based line number
+	Column  int    // 1-based column, counted in characters
+	Token   string // the offending input token, empty at end of input
+	Snippet string // the offending line with a caret under the problem
+	Err     error  // underlying cause, if any
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+	}
+	return fmt.Sprintf("%s at line %d, column %d (offset %d)", e.Msg, e.Line, e.Column, e.Offset)
+}
+
+// Unwrap returns ErrInvalidJsonFormat and the underlying cause, if any.
+func (e *SyntaxError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrInvalidJsonFormat, e.Err}
+	}
+	return []error{ErrInvalidJsonFormat}
+}
+
+// newSyntaxError creates a SyntaxError at the given offset.
+// The line, column, token and snippet are filled in by withContext.
+func newSyntaxError(offset int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Msg:    fmt.Sprintf(format, args...),
+		Offset: offset,
+	}
+}
+
+// withErr records the underlying cause of the error.
+func (e *SyntaxError) withErr(err error) *SyntaxError {
+	e.Err = err
+	return e
+}
+
+// maxSnippetWidth bounds the number of bytes of the offending line shown in a snippet.
+const maxSnippetWidth = 60
+
+// withContext fills in the line, column, token and snippet of the error from data.
+func (e *SyntaxError) withContext(data []byte) *SyntaxError {
+	offset := e.Offset
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	var lineStart int
+	e.Line, e.Column, lineStart = lineColumn(data, offset)
+	e.Token = tokenAt(data, offset)
+
+	lineEnd := lineStart
+	for lineEnd < len(data) && data[lineEnd] != '\n' && data[lineEnd] != '\r' {
+		lineEnd++
+	}
+
+	// Keep the snippet short on long (e.g. minified) lines
+	snippetStart, snippetEnd := lineStart, lineEnd
+	if offset-snippetStart > maxSnippetWidth/2 {
+		snippetStart = offset - maxSnippetWidth/2
+	}
+	if snippetEnd-snippetStart > maxSnippetWidth {
+		snippetEnd = snippetStart + maxSnippetWidth
+	}
+	for snippetStart < offset && !utf8.RuneStart(data[snippetStart]) {
+		snippetStart++
+	}
+	for snippetEnd < lineEnd && !utf8.RuneStart(data[snippetEnd]) {
+		snippetEnd++
+	}
+
+	var sb strings.Builder
+	sb.Write(data[snippetStart:snippetEnd])
+	sb.WriteByte('\n')
+	for _, r := range string(data[snippetStart:offset]) {
+		if r == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte('^')
+	e.Snippet = sb.String()
+	return e
+}
+
+// lineColumn returns the 1-based line and character column of offset in data,
+// along with the offset at which that line starts.
+func lineColumn(data []byte, offset int) (line, column, lineStart int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line = 1
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCount(data[lineStart:offset]) + 1, lineStart
+}
+
+// maxTokenWidth bounds the number of bytes reported as the offending token.
+const maxTokenWidth = 32
+
+// tokenAt returns the input token starting at offset, or "" at end of input.
+func tokenAt(data []byte, offset int) string {
+	if offset >= len(data) {
+		return ""
+	}
+
+	end := offset
+	switch c := data[offset]; {
+	case c == '"':
+		end++
+		for end < len(data) && data[end] != '"' && data[end] != '\n' && end-offset < maxTokenWidth {
+			if data[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end < len(data) && data[end] == '"' {
+			end++
+		}
+	case isTokenChar(c):
+		for end < len(data) && isTokenChar(data[end]) && end-offset < maxTokenWidth {
+			end++
+		}
+	default:
+		_, size := utf8.DecodeRune(data[offset:])
+		end += size
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+	return string(data[offset:end])
+}
+
+// isTokenChar reports whether c can be part of a bare word or number token.
+func isTokenChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '+' || c == '.' || c == '_'
+}
+
+// quoteChar formats an input byte for an error message.
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c >= 0x20 && c < 0x7f {
+		return "'" + string(c) + "'"
+	}
+	return fmt.Sprintf("0x%02x", c)
+}
+
+// LimitError reports input rejected because it exceeds a ParseOptions limit.
+// Err is one of the ErrMax... sentinel errors and can be matched with errors.Is.
+type LimitError struct {
+	Err    error // the limit that was exceeded
+	Limit  int   // the configured limit
+	Offset int   // byte offset at which the limit was exceeded
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v (limit %d) at offset %d", e.Err, e.Limit, e.Offset)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// newLimitError creates a LimitError for the given sentinel error.
+func newLimitError(err error, limit, offset int) *LimitError {
+	return &LimitError{
+		Err:    err,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
+
+// DuplicateKeyError reports an object key that appears more than once when
+// parsing with DuplicateKeyReject. It matches ErrDuplicateKey with errors.Is.
+type DuplicateKeyError struct {
+	Key         string // the repeated key
+	FirstOffset int    // byte offset of the first occurrence of the key
+	Offset      int    // byte offset of the repeated occurrence of the key
+	FirstLine   int    // 1-based line of the first occurrence
+	FirstColumn int    // 1-based column of the first occurrence
+	Line        int    // 1-based line of the repeated occurrence
+	Column      int    // 1-based column of the repeated occurrence
+}
+
+func (e *DuplicateKeyError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("duplicate key %q at offset %d (first defined at offset %d)", e.Key, e.Offset, e.FirstOffset)
+	}
+	return fmt.Sprintf("duplicate key %q at line %d, column %d (offset %d); first defined at line %d, column %d (offset %d)",
+		e.Key, e.Line, e.Column, e.Offset, e.FirstLine, e.FirstColumn, e.FirstOffset)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// withContext fills in the lines and columns of both occurrences from data.
+func (e *DuplicateKeyError) withContext(data []byte) *DuplicateKeyError {
+	e.FirstLine, e.FirstColumn, _ = lineColumn(data, e.FirstOffset)
+	e.Line, e.Column, _ = lineColumn(data, e.Offset)
+	return e
+}
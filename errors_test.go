@@ -1,6 +1,7 @@
 package aaronjson
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -160,3 +161,97 @@ func TestErrorUsage(t *testing.T) {
 		t.Error("Expected error when unmarshaling to non-pointer")
 	}
 }
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		offset  int
+		line    int
+		column  int
+		token   string
+		snippet string
+	}{
+		{
+			name:    "bad literal on second line",
+			input:   "{\n  \"a\": tru\n}",
+			offset:  9,
+			line:    2,
+			column:  8,
+			token:   "tru",
+			snippet: "  \"a\": tru\n       ^",
+		},
+		{
+			name:    "missing comma",
+			input:   `[1 2]`,
+			offset:  3,
+			line:    1,
+			column:  4,
+			token:   "2",
+			snippet: "[1 2]\n   ^",
+		},
+		{
+			name:    "unexpected end of input",
+			input:   `{"a":`,
+			offset:  5,
+			line:    1,
+			column:  6,
+			token:   "",
+			snippet: "{\"a\":\n     ^",
+		},
+		{
+			name:    "trailing data",
+			input:   "{}\n\txyz",
+			offset:  4,
+			line:    2,
+			column:  2,
+			token:   "xyz",
+			snippet: "\txyz\n\t^",
+		},
+		{
+			name:    "column counts characters",
+			input:   `["é", x]`,
+			offset:  7,
+			line:    1,
+			column:  7,
+			token:   "x",
+			snippet: "[\"é\", x]\n      ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatal("Parse() should return error")
+			}
+			if !errors.Is(err, ErrInvalidJsonFormat) {
+				t.Errorf("errors.Is(err, ErrInvalidJsonFormat) = false for %v", err)
+			}
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("errors.As(err, *SyntaxError) = false for %v", err)
+			}
+			if se.Offset != tt.offset || se.Line != tt.line || se.Column != tt.column {
+				t.Errorf("position = offset %d line %d column %d, want offset %d line %d column %d",
+					se.Offset, se.Line, se.Column, tt.offset, tt.line, tt.column)
+			}
+			if se.Token != tt.token {
+				t.Errorf("Token = %q, want %q", se.Token, tt.token)
+			}
+			if se.Snippet != tt.snippet {
+				t.Errorf("Snippet = %q, want %q", se.Snippet, tt.snippet)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorEmptyData(t *testing.T) {
+	_, err := Parse("  ")
+	if !errors.Is(err, ErrEmptyData) {
+		t.Errorf("errors.Is(err, ErrEmptyData) = false for %v", err)
+	}
+	if !errors.Is(err, ErrInvalidJsonFormat) {
+		t.Errorf("errors.Is(err, ErrInvalidJsonFormat) = false for %v", err)
+	}
+}
@@ -43,7 +43,7 @@ func TestCompleteWorkflow(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get('name') error = %v", err)
 	}
-	if name.String() != "John Doe" {
+	if name.String() != `"John Doe"` {
 		t.Errorf("Name = %v, want John Doe", name.String())
 	}
 
@@ -196,7 +196,7 @@ func TestEdgeCases(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get('e') error = %v", err)
 	}
-	if val.String() != "deep" {
+	if val.String() != `"deep"` {
 		t.Errorf("Deep value = %v, want deep", val.String())
 	}
 
@@ -0,0 +1,1728 @@
+package jq
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// builtin is a function callable from a program. Arguments are passed
+// unevaluated, as jq passes filters, together with the caller's scope.
+type builtin struct {
+	eval func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error
+	// paths evaluates the function as a path expression; nil if it is not one.
+	paths func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error
+}
+
+// builtins maps "name/arity" to its implementation.
+var builtins map[string]*builtin
+
+// lookupBuiltin returns the function name with the given number of arguments, or nil.
+func lookupBuiltin(name string, arity int) *builtin {
+	return builtins[name+"/"+strconv.Itoa(arity)]
+}
+
+// unary adapts a function of the input alone.
+func unary(fn func(in aaronjson.JsonValue) (aaronjson.JsonValue, error)) *builtin {
+	return &builtin{eval: func(_ []node, _ *scope, in aaronjson.JsonValue, emit emitFunc) error {
+		v, err := fn(in)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	}}
+}
+
+// withValues adapts a function of the input and the values of its arguments,
+// called for every combination of the arguments' outputs.
+func withValues(fn func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error)) *builtin {
+	return &builtin{eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+		return cartesian(args, env, in, nil, func(values []aaronjson.JsonValue) error {
+			v, err := fn(in, values)
+			if err != nil {
+				return err
+			}
+			return emit(v)
+		})
+	}}
+}
+
+// cartesian calls fn with every combination of the outputs of args, the
+// first argument varying slowest.
+func cartesian(args []node, env *scope, in aaronjson.JsonValue, values []aaronjson.JsonValue, fn func([]aaronjson.JsonValue) error) error {
+	if len(args) == 0 {
+		return fn(values)
+	}
+	return eval(args[0], env, in, func(v aaronjson.JsonValue) error {
+		return cartesian(args[1:], env, in, append(values[:len(values):len(values)], v), fn)
+	})
+}
+
+// selectBuiltin returns a builtin that passes its input through when keep
+// reports true, both as a filter and as a path expression.
+func selectBuiltin(keep func(aaronjson.JsonValue) bool) *builtin {
+	return &builtin{
+		eval: func(_ []node, _ *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			if keep(in) {
+				return emit(in)
+			}
+			return nil
+		},
+		paths: func(_ []node, _ *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+			if keep(in) {
+				return emit(path, in)
+			}
+			return nil
+		},
+	}
+}
+
+// mathBuiltin adapts a float64 function of a number.
+func mathBuiltin(name string, fn func(float64) float64) *builtin {
+	return unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+		if !isNumber(in) {
+			return nil, runtimeErrorf("%s number required for %s", describe(in), name)
+		}
+		return makeNumber(fn(floatValue(in))), nil
+	})
+}
+
+// stringBuiltin adapts a function of a string input and string arguments.
+// Non-string values are reported as errors mentioning name.
+func stringBuiltin(name string, fn func(s string, args []string) (aaronjson.JsonValue, error)) *builtin {
+	return withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+		s, err := in.AsString()
+		if err != nil || !in.IsString() {
+			return nil, runtimeErrorf("%s cannot be used as input to %s", describe(in), name)
+		}
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			if strs[i], err = arg.AsString(); err != nil || !arg.IsString() {
+				return nil, runtimeErrorf("%s cannot be used as an argument to %s", describe(arg), name)
+			}
+		}
+		return fn(s, strs)
+	})
+}
+
+func init() {
+	builtins = map[string]*builtin{
+		"empty/0": {
+			eval: func([]node, *scope, aaronjson.JsonValue, emitFunc) error { return nil },
+			paths: func([]node, *scope, aaronjson.JsonValue, []aaronjson.JsonValue, pathFunc) error {
+				return nil
+			},
+		},
+		"error/0": {
+			eval: func(_ []node, _ *scope, in aaronjson.JsonValue, _ emitFunc) error {
+				return &RuntimeError{Value: in}
+			},
+		},
+		"error/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, _ emitFunc) error {
+				return eval(args[0], env, in, func(v aaronjson.JsonValue) error {
+					return &RuntimeError{Value: v}
+				})
+			},
+		},
+		"not/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonBool(!truthy(in)), nil
+		}),
+		"type/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(typeName(in)), nil
+		}),
+		"length/0": unary(length),
+		"utf8bytelength/0": stringBuiltin("utf8bytelength", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonInt64(int64(len(s))), nil
+		}),
+		"keys/0":          unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return keys(in, true) }),
+		"keys_unsorted/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return keys(in, false) }),
+		"has/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return has(in, args[0])
+		}),
+		"in/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return has(args[0], in)
+		}),
+		"contains/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return containsTop(in, args[0])
+		}),
+		"inside/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return containsTop(args[0], in)
+		}),
+
+		"select/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				return eval(args[0], env, in, func(c aaronjson.JsonValue) error {
+					if truthy(c) {
+						return emit(in)
+					}
+					return nil
+				})
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				return eval(args[0], env, in, func(c aaronjson.JsonValue) error {
+					if truthy(c) {
+						return emit(path, in)
+					}
+					return nil
+				})
+			},
+		},
+		"values/0":    selectBuiltin(func(v aaronjson.JsonValue) bool { return !v.IsNull() }),
+		"nulls/0":     selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsNull() }),
+		"booleans/0":  selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsBool() }),
+		"numbers/0":   selectBuiltin(isNumber),
+		"strings/0":   selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsString() }),
+		"arrays/0":    selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsArray() }),
+		"objects/0":   selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsObject() }),
+		"iterables/0": selectBuiltin(func(v aaronjson.JsonValue) bool { return v.IsArray() || v.IsObject() }),
+		"scalars/0":   selectBuiltin(func(v aaronjson.JsonValue) bool { return !v.IsArray() && !v.IsObject() }),
+
+		"map/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var result []aaronjson.JsonValue
+			err := iterate(in, func(_, v aaronjson.JsonValue) error {
+				return eval(args[0], env, v, func(out aaronjson.JsonValue) error {
+					result = append(result, out)
+					return nil
+				})
+			})
+			if err != nil {
+				return err
+			}
+			return emit(newArray(result))
+		}},
+		"map_values/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			result, err := mapValues(in, func(v aaronjson.JsonValue) (aaronjson.JsonValue, bool, error) {
+				return firstOutput(args[0], env, v)
+			})
+			if err != nil {
+				return err
+			}
+			return emit(result)
+		}},
+		"add/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			var sum aaronjson.JsonValue = aaronjson.NewJsonNull()
+			if in.IsNull() {
+				return sum, nil
+			}
+			err := iterate(in, func(_, v aaronjson.JsonValue) error {
+				var err error
+				sum, err = arithmetic("+", sum, v)
+				return err
+			})
+			return sum, err
+		}),
+		"add/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var sum aaronjson.JsonValue = aaronjson.NewJsonNull()
+			err := eval(args[0], env, in, func(v aaronjson.JsonValue) error {
+				var err error
+				sum, err = arithmetic("+", sum, v)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return emit(sum)
+		}},
+		"any/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return anyAll(in, true) }),
+		"all/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return anyAll(in, false) }),
+		"any/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return generatorAnyAll(iterateNode{target: identityNode{}}, args[0], env, in, true, emit)
+		}},
+		"all/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return generatorAnyAll(iterateNode{target: identityNode{}}, args[0], env, in, false, emit)
+		}},
+		"any/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return generatorAnyAll(args[0], args[1], env, in, true, emit)
+		}},
+		"all/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return generatorAnyAll(args[0], args[1], env, in, false, emit)
+		}},
+		"IN/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return generatorAnyAll(args[0], binaryNode{op: "==", left: identityNode{}, right: literalNode{in}}, env, in, true, emit)
+		}},
+		"IN/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			found := false
+			stop := &stopError{}
+			err := eval(args[0], env, in, func(v aaronjson.JsonValue) error {
+				return eval(args[1], env, in, func(candidate aaronjson.JsonValue) error {
+					if aaronjson.Equal(v, candidate) {
+						found = true
+						return stop
+					}
+					return nil
+				})
+			})
+			if err != nil && err != stop {
+				return err
+			}
+			return emit(aaronjson.NewJsonBool(found))
+		}},
+		"isempty/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			_, found, err := firstOutput(args[0], env, in)
+			if err != nil {
+				return err
+			}
+			return emit(aaronjson.NewJsonBool(!found))
+		}},
+		"range/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return cartesian(args, env, in, nil, func(v []aaronjson.JsonValue) error {
+				return rangeValues(aaronjson.NewJsonInt64(0), v[0], aaronjson.NewJsonInt64(1), emit)
+			})
+		}},
+		"range/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return cartesian(args, env, in, nil, func(v []aaronjson.JsonValue) error {
+				return rangeValues(v[0], v[1], aaronjson.NewJsonInt64(1), emit)
+			})
+		}},
+		"range/3": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return cartesian(args, env, in, nil, func(v []aaronjson.JsonValue) error {
+				return rangeValues(v[0], v[1], v[2], emit)
+			})
+		}},
+
+		"floor/0": mathBuiltin("floor", math.Floor),
+		"ceil/0":  mathBuiltin("ceil", math.Ceil),
+		"round/0": mathBuiltin("round", math.Round),
+		"trunc/0": mathBuiltin("trunc", math.Trunc),
+		"sqrt/0":  mathBuiltin("sqrt", math.Sqrt),
+		"fabs/0":  mathBuiltin("fabs", math.Abs),
+		"log/0":   mathBuiltin("log", math.Log),
+		"log2/0":  mathBuiltin("log2", math.Log2),
+		"log10/0": mathBuiltin("log10", math.Log10),
+		"exp/0":   mathBuiltin("exp", math.Exp),
+		"exp2/0":  mathBuiltin("exp2", math.Exp2),
+		"exp10/0": mathBuiltin("exp10", func(x float64) float64 { return math.Pow(10, x) }),
+		"abs/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !isNumber(in) {
+				return nil, runtimeErrorf("%s has no absolute value", describe(in))
+			}
+			if n, ok := intValue(in); ok && n < 0 && n != math.MinInt64 {
+				return aaronjson.NewJsonInt64(-n), nil
+			}
+			if floatValue(in) < 0 {
+				return makeNumber(-floatValue(in)), nil
+			}
+			return in, nil
+		}),
+		"pow/2": withValues(func(_ aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !isNumber(args[0]) || !isNumber(args[1]) {
+				return nil, runtimeErrorf("pow requires number arguments")
+			}
+			return makeNumber(math.Pow(floatValue(args[0]), floatValue(args[1]))), nil
+		}),
+		"infinite/0": unary(func(aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonFloat(math.Inf(1)), nil
+		}),
+		"nan/0": unary(func(aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonFloat(math.NaN()), nil
+		}),
+		"isnan/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !isNumber(in) {
+				return nil, runtimeErrorf("%s number required for isnan", describe(in))
+			}
+			return aaronjson.NewJsonBool(math.IsNaN(floatValue(in))), nil
+		}),
+		"isinfinite/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !isNumber(in) {
+				return nil, runtimeErrorf("%s number required for isinfinite", describe(in))
+			}
+			return aaronjson.NewJsonBool(math.IsInf(floatValue(in), 0)), nil
+		}),
+
+		"tostring/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(toString(in)), nil
+		}),
+		"tonumber/0": unary(toNumber),
+		"tojson/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(jsonText(in)), nil
+		}),
+		"fromjson/0": stringBuiltin("fromjson", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			v, err := aaronjson.Parse(s)
+			if err != nil {
+				return nil, runtimeErrorf("%s (while parsing '%s')", err.Error(), s)
+			}
+			return v, nil
+		}),
+		"ascii_downcase/0": stringBuiltin("ascii_downcase", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(mapASCII(s, 'A', 'Z', 'a'-'A')), nil
+		}),
+		"ascii_upcase/0": stringBuiltin("ascii_upcase", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(mapASCII(s, 'a', 'z', 'A'-'a')), nil
+		}),
+		"ltrimstr/1": trimBuiltin(strings.TrimPrefix),
+		"rtrimstr/1": trimBuiltin(strings.TrimSuffix),
+		"startswith/1": stringBuiltin("startswith", func(s string, args []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonBool(strings.HasPrefix(s, args[0])), nil
+		}),
+		"endswith/1": stringBuiltin("endswith", func(s string, args []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonBool(strings.HasSuffix(s, args[0])), nil
+		}),
+		"trim/0": stringBuiltin("trim", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(strings.TrimSpace(s)), nil
+		}),
+		"ltrim/0": stringBuiltin("ltrim", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(strings.TrimLeftFunc(s, unicode.IsSpace)), nil
+		}),
+		"rtrim/0": stringBuiltin("rtrim", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			return aaronjson.NewJsonString(strings.TrimRightFunc(s, unicode.IsSpace)), nil
+		}),
+		"split/1": stringBuiltin("split", func(s string, args []string) (aaronjson.JsonValue, error) {
+			return splitString(aaronjson.NewJsonString(s), aaronjson.NewJsonString(args[0])), nil
+		}),
+		"join/1": withValues(join),
+		"explode/0": stringBuiltin("explode", func(s string, _ []string) (aaronjson.JsonValue, error) {
+			var codes []aaronjson.JsonValue
+			for _, r := range s {
+				codes = append(codes, aaronjson.NewJsonInt64(int64(r)))
+			}
+			return newArray(codes), nil
+		}),
+		"implode/0": unary(implode),
+		"test/1":    regexBuiltin(false, testRegex),
+		"test/2":    regexBuiltin(true, testRegex),
+		"capture/1": regexBuiltin(false, captureRegex),
+		"capture/2": regexBuiltin(true, captureRegex),
+		"match/1":   matchBuiltin(false, false, matchObject),
+		"match/2":   matchBuiltin(true, false, matchObject),
+		"scan/1":    matchBuiltin(false, true, scanResult),
+		"scan/2":    matchBuiltin(true, true, scanResult),
+		"split/2": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return splitRegex(in, args[0], args[1])
+		}),
+		"splits/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return eval(args[0], env, in, func(re aaronjson.JsonValue) error {
+				parts, err := splitRegex(in, re, aaronjson.NewJsonNull())
+				if err != nil {
+					return err
+				}
+				return iterate(parts, func(_, v aaronjson.JsonValue) error { return emit(v) })
+			})
+		}},
+		"splits/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return cartesian(args, env, in, nil, func(values []aaronjson.JsonValue) error {
+				parts, err := splitRegex(in, values[0], values[1])
+				if err != nil {
+					return err
+				}
+				return iterate(parts, func(_, v aaronjson.JsonValue) error { return emit(v) })
+			})
+		}},
+		"sub/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return substitute(args, env, in, false, emit)
+		}},
+		"sub/3": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return substitute(args, env, in, false, emit)
+		}},
+		"gsub/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return substitute(args, env, in, true, emit)
+		}},
+		"gsub/3": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return substitute(args, env, in, true, emit)
+		}},
+		"indices/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return indices(in, args[0])
+		}),
+		"index/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return pickIndex(in, args[0], true)
+		}),
+		"rindex/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return pickIndex(in, args[0], false)
+		}),
+
+		"sort/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			elems, err := sortable(in, "sorted")
+			if err != nil {
+				return nil, err
+			}
+			sorted := append([]aaronjson.JsonValue(nil), elems...)
+			sort.SliceStable(sorted, func(i, j int) bool { return aaronjson.Compare(sorted[i], sorted[j]) < 0 })
+			return newArray(sorted), nil
+		}),
+		"sort_by/1": byKeys(func(elems []aaronjson.JsonValue, _ []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return newArray(elems), nil
+		}),
+		"group_by/1": byKeys(func(elems []aaronjson.JsonValue, keys []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			var groups []aaronjson.JsonValue
+			for _, group := range groupRuns(elems, keys) {
+				groups = append(groups, newArray(group))
+			}
+			return newArray(groups), nil
+		}),
+		"unique_by/1": byKeys(func(elems []aaronjson.JsonValue, keys []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			var firsts []aaronjson.JsonValue
+			for _, group := range groupRuns(elems, keys) {
+				firsts = append(firsts, group[0])
+			}
+			return newArray(firsts), nil
+		}),
+		"unique/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			elems, err := sortable(in, "sorted")
+			if err != nil {
+				return nil, err
+			}
+			sorted := append([]aaronjson.JsonValue(nil), elems...)
+			sort.SliceStable(sorted, func(i, j int) bool { return aaronjson.Compare(sorted[i], sorted[j]) < 0 })
+			var unique []aaronjson.JsonValue
+			for i, v := range sorted {
+				if i == 0 || aaronjson.Compare(sorted[i-1], v) != 0 {
+					unique = append(unique, v)
+				}
+			}
+			return newArray(unique), nil
+		}),
+		"min/0":     unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return extreme(in, nil, false) }),
+		"max/0":     unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return extreme(in, nil, true) }),
+		"min_by/1":  extremeBy(false),
+		"max_by/1":  extremeBy(true),
+		"reverse/0": unary(reverse),
+		"flatten/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return flatten(in, -1) }),
+		"flatten/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !isNumber(args[0]) || floatValue(args[0]) < 0 {
+				return nil, runtimeErrorf("flatten depth must not be negative")
+			}
+			return flatten(in, int(floatValue(args[0])))
+		}),
+		"transpose/0": unary(transpose),
+		"toarray/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if in.IsArray() {
+				return in, nil
+			}
+			return newArray([]aaronjson.JsonValue{in}), nil
+		}),
+
+		"to_entries/0":   unary(toEntries),
+		"from_entries/0": unary(fromEntries),
+		"with_entries/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			entries, err := toEntries(in)
+			if err != nil {
+				return err
+			}
+			var mapped []aaronjson.JsonValue
+			err = iterate(entries, func(_, entry aaronjson.JsonValue) error {
+				return eval(args[0], env, entry, func(v aaronjson.JsonValue) error {
+					mapped = append(mapped, v)
+					return nil
+				})
+			})
+			if err != nil {
+				return err
+			}
+			result, err := fromEntries(newArray(mapped))
+			if err != nil {
+				return err
+			}
+			return emit(result)
+		}},
+
+		"recurse/0": {
+			eval: func(_ []node, _ *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				return recurseValues(in, emit)
+			},
+			paths: func(_ []node, _ *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				return recursePaths(in, path, emit)
+			},
+		},
+		"recurse/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				var walk func(v aaronjson.JsonValue) error
+				walk = func(v aaronjson.JsonValue) error {
+					if err := emit(v); err != nil {
+						return err
+					}
+					return eval(args[0], env, v, walk)
+				}
+				return walk(in)
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				var walk func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error
+				walk = func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+					if err := emit(p, v); err != nil {
+						return err
+					}
+					return evalPaths(args[0], env, v, p, walk)
+				}
+				return walk(path, in)
+			},
+		},
+		"recurse/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var walk func(v aaronjson.JsonValue) error
+			walk = func(v aaronjson.JsonValue) error {
+				if err := emit(v); err != nil {
+					return err
+				}
+				return eval(args[0], env, v, func(next aaronjson.JsonValue) error {
+					return eval(args[1], env, next, func(c aaronjson.JsonValue) error {
+						if truthy(c) {
+							return walk(next)
+						}
+						return nil
+					})
+				})
+			}
+			return walk(in)
+		}},
+		"repeat/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var loop func(v aaronjson.JsonValue) error
+			loop = func(v aaronjson.JsonValue) error {
+				if err := emit(v); err != nil {
+					return err
+				}
+				return eval(args[0], env, v, loop)
+			}
+			return loop(in)
+		}},
+		"walk/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return walk(args[0], env, in, emit)
+		}},
+		"first/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return indexValue(in, aaronjson.NewJsonInt64(0))
+		}),
+		"last/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return indexValue(in, aaronjson.NewJsonInt64(-1))
+		}),
+		"nth/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			return indexValue(in, args[0])
+		}),
+		"first/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				return limitOutputs(1, args[0], env, in, emit)
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				return limitPaths(1, args[0], env, in, path, emit)
+			},
+		},
+		"last/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				values, err := collect(args[0], env, in)
+				if err != nil || len(values) == 0 {
+					return err
+				}
+				return emit(values[len(values)-1])
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				var lastPath []aaronjson.JsonValue
+				var lastValue aaronjson.JsonValue
+				err := evalPaths(args[0], env, in, path, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+					lastPath, lastValue = p, v
+					return nil
+				})
+				if err != nil || lastValue == nil {
+					return err
+				}
+				return emit(lastPath, lastValue)
+			},
+		},
+		"limit/2": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				return eval(args[0], env, in, func(n aaronjson.JsonValue) error {
+					if !isNumber(n) {
+						return runtimeErrorf("Invalid limit %s: must be a number", describe(n))
+					}
+					return limitOutputs(int(floatValue(n)), args[1], env, in, emit)
+				})
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				return eval(args[0], env, in, func(n aaronjson.JsonValue) error {
+					if !isNumber(n) {
+						return runtimeErrorf("Invalid limit %s: must be a number", describe(n))
+					}
+					return limitPaths(int(floatValue(n)), args[1], env, in, path, emit)
+				})
+			},
+		},
+		"nth/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return eval(args[0], env, in, func(n aaronjson.JsonValue) error {
+				if !isNumber(n) || floatValue(n) < 0 {
+					return runtimeErrorf("Out of bounds negative array index")
+				}
+				want := int(floatValue(n))
+				count := 0
+				stop := &stopError{}
+				err := eval(args[1], env, in, func(v aaronjson.JsonValue) error {
+					if count == want {
+						if err := emit(v); err != nil {
+							return err
+						}
+						return stop
+					}
+					count++
+					return nil
+				})
+				if err == stop {
+					return nil
+				}
+				return err
+			})
+		}},
+		"until/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var loop func(v aaronjson.JsonValue) error
+			loop = func(v aaronjson.JsonValue) error {
+				return eval(args[0], env, v, func(c aaronjson.JsonValue) error {
+					if truthy(c) {
+						return emit(v)
+					}
+					return eval(args[1], env, v, loop)
+				})
+			}
+			return loop(in)
+		}},
+		"while/2": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			var loop func(v aaronjson.JsonValue) error
+			loop = func(v aaronjson.JsonValue) error {
+				return eval(args[0], env, v, func(c aaronjson.JsonValue) error {
+					if !truthy(c) {
+						return nil
+					}
+					if err := emit(v); err != nil {
+						return err
+					}
+					return eval(args[1], env, v, loop)
+				})
+			}
+			return loop(in)
+		}},
+
+		"path/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return evalPaths(args[0], env, in, nil, func(p []aaronjson.JsonValue, _ aaronjson.JsonValue) error {
+				return emit(newArray(p))
+			})
+		}},
+		"paths/0": {eval: func(_ []node, _ *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return recursePaths(in, nil, func(p []aaronjson.JsonValue, _ aaronjson.JsonValue) error {
+				if len(p) == 0 {
+					return nil
+				}
+				return emit(newArray(p))
+			})
+		}},
+		"paths/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return recursePaths(in, nil, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+				if len(p) == 0 {
+					return nil
+				}
+				return eval(args[0], env, v, func(c aaronjson.JsonValue) error {
+					if truthy(c) {
+						return emit(newArray(p))
+					}
+					return nil
+				})
+			})
+		}},
+		"leaf_paths/0": {eval: func(_ []node, _ *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			return recursePaths(in, nil, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+				if len(p) == 0 || v.IsArray() || v.IsObject() {
+					return nil
+				}
+				return emit(newArray(p))
+			})
+		}},
+		"getpath/1": {
+			eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+				return eval(args[0], env, in, func(p aaronjson.JsonValue) error {
+					path, err := pathArg(p)
+					if err != nil {
+						return err
+					}
+					v, err := getPath(in, path)
+					if err != nil {
+						return err
+					}
+					return emit(v)
+				})
+			},
+			paths: func(args []node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+				return eval(args[0], env, in, func(p aaronjson.JsonValue) error {
+					rel, err := pathArg(p)
+					if err != nil {
+						return err
+					}
+					v, err := getPath(in, rel)
+					if err != nil {
+						return err
+					}
+					return emit(append(path[:len(path):len(path)], rel...), v)
+				})
+			},
+		},
+		"setpath/2": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			path, err := pathArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return setPath(in, path, args[1])
+		}),
+		"delpaths/1": withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+			if !args[0].IsArray() {
+				return nil, runtimeErrorf("Paths must be specified as an array")
+			}
+			var paths [][]aaronjson.JsonValue
+			for _, p := range arrayElems(args[0].(*aaronjson.JsonArray)) {
+				path, err := pathArg(p)
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, path)
+			}
+			return deletePaths(in, paths)
+		}),
+		"del/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			paths, err := collectPaths(args[0], env, in)
+			if err != nil {
+				return err
+			}
+			result, err := deletePaths(in, paths)
+			if err != nil {
+				return err
+			}
+			return emit(result)
+		}},
+		"pick/1": {eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+			paths, err := collectPaths(args[0], env, in)
+			if err != nil {
+				return err
+			}
+			var result aaronjson.JsonValue = aaronjson.NewJsonNull()
+			for _, p := range paths {
+				v, err := getPath(in, p)
+				if err != nil {
+					return err
+				}
+				if result, err = setPath(result, p, v); err != nil {
+					return err
+				}
+			}
+			return emit(result)
+		}},
+		"debug/0": unary(func(in aaronjson.JsonValue) (aaronjson.JsonValue, error) { return in, nil }),
+	}
+}
+
+// length implements length for every type but booleans.
+func length(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch {
+	case in.IsNull():
+		return aaronjson.NewJsonInt64(0), nil
+	case in.IsBool():
+		return nil, runtimeErrorf("%s has no length", describe(in))
+	case isNumber(in):
+		if n, ok := intValue(in); ok && n < 0 && n != math.MinInt64 {
+			return aaronjson.NewJsonInt64(-n), nil
+		}
+		if floatValue(in) < 0 {
+			return makeNumber(-floatValue(in)), nil
+		}
+		return in, nil
+	case in.IsString():
+		s, _ := in.AsString()
+		return aaronjson.NewJsonInt64(int64(utf8.RuneCountInString(s))), nil
+	case in.IsArray():
+		return aaronjson.NewJsonInt64(int64(len(arrayElems(in.(*aaronjson.JsonArray))))), nil
+	default:
+		return aaronjson.NewJsonInt64(int64(len(objectKeys(in.(*aaronjson.JsonObject))))), nil
+	}
+}
+
+// keys returns the keys of an object, sorted or in insertion order, or the indices of an array.
+func keys(in aaronjson.JsonValue, sorted bool) (aaronjson.JsonValue, error) {
+	switch tv := in.(type) {
+	case *aaronjson.JsonObject:
+		names := objectKeys(tv)
+		if sorted {
+			sort.Strings(names)
+		}
+		result := make([]aaronjson.JsonValue, len(names))
+		for i, name := range names {
+			result[i] = aaronjson.NewJsonString(name)
+		}
+		return newArray(result), nil
+	case *aaronjson.JsonArray:
+		result := make([]aaronjson.JsonValue, len(arrayElems(tv)))
+		for i := range result {
+			result[i] = aaronjson.NewJsonInt64(int64(i))
+		}
+		return newArray(result), nil
+	default:
+		return nil, runtimeErrorf("%s has no keys", describe(in))
+	}
+}
+
+// has reports whether the object has the key or the array has the index.
+func has(container, key aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch {
+	case container.IsObject() && key.IsString():
+		k, _ := key.AsString()
+		_, ok := objectGet(container.(*aaronjson.JsonObject), k)
+		return aaronjson.NewJsonBool(ok), nil
+	case container.IsArray() && isNumber(key):
+		i := floatValue(key)
+		return aaronjson.NewJsonBool(i >= 0 && i < float64(len(arrayElems(container.(*aaronjson.JsonArray))))), nil
+	}
+	return nil, runtimeErrorf("Cannot check whether %s has a %s key", typeName(container), typeName(key))
+}
+
+// containsTop implements contains, which requires both values to have the same type.
+func containsTop(a, b aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if typeName(a) != typeName(b) {
+		return nil, runtimeErrorf("%s and %s cannot have their containment checked", describe(a), describe(b))
+	}
+	return aaronjson.NewJsonBool(contains(a, b)), nil
+}
+
+// contains reports whether b is contained in a: substrings for strings,
+// recursive containment for arrays and objects and equality otherwise.
+func contains(a, b aaronjson.JsonValue) bool {
+	switch {
+	case a.IsObject() && b.IsObject():
+		for _, key := range objectKeys(b.(*aaronjson.JsonObject)) {
+			av, ok := objectGet(a.(*aaronjson.JsonObject), key)
+			bv, _ := objectGet(b.(*aaronjson.JsonObject), key)
+			if !ok || !contains(av, bv) {
+				return false
+			}
+		}
+		return true
+	case a.IsArray() && b.IsArray():
+	outer:
+		for _, bv := range arrayElems(b.(*aaronjson.JsonArray)) {
+			for _, av := range arrayElems(a.(*aaronjson.JsonArray)) {
+				if contains(av, bv) {
+					continue outer
+				}
+			}
+			return false
+		}
+		return true
+	case a.IsString() && b.IsString():
+		as, _ := a.AsString()
+		bs, _ := b.AsString()
+		return strings.Contains(as, bs)
+	default:
+		return typeName(a) == typeName(b) && aaronjson.Equal(a, b)
+	}
+}
+
+// mapValues applies fn to every value of an object or array, dropping the
+// members for which fn produces nothing.
+func mapValues(in aaronjson.JsonValue, fn func(aaronjson.JsonValue) (aaronjson.JsonValue, bool, error)) (aaronjson.JsonValue, error) {
+	switch tv := in.(type) {
+	case *aaronjson.JsonObject:
+		result := aaronjson.NewJsonObject()
+		for _, key := range objectKeys(tv) {
+			v, _ := objectGet(tv, key)
+			mapped, ok, err := fn(v)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				_, _ = result.Set(key, mapped)
+			}
+		}
+		return result, nil
+	case *aaronjson.JsonArray:
+		var result []aaronjson.JsonValue
+		for _, v := range arrayElems(tv) {
+			mapped, ok, err := fn(v)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, mapped)
+			}
+		}
+		return newArray(result), nil
+	default:
+		return nil, runtimeErrorf("Cannot iterate over %s", describe(in))
+	}
+}
+
+// anyAll implements any and all over the values of an array or object.
+func anyAll(in aaronjson.JsonValue, any bool) (aaronjson.JsonValue, error) {
+	result := !any
+	err := iterate(in, func(_, v aaronjson.JsonValue) error {
+		if truthy(v) == any {
+			result = any
+		}
+		return nil
+	})
+	return aaronjson.NewJsonBool(result), err
+}
+
+// generatorAnyAll implements any(gen; cond) and all(gen; cond), stopping at
+// the first output that decides the result.
+func generatorAnyAll(gen, cond node, env *scope, in aaronjson.JsonValue, any bool, emit emitFunc) error {
+	stop := &stopError{}
+	err := eval(gen, env, in, func(v aaronjson.JsonValue) error {
+		return eval(cond, env, v, func(c aaronjson.JsonValue) error {
+			if truthy(c) == any {
+				return stop
+			}
+			return nil
+		})
+	})
+	switch err {
+	case nil:
+		return emit(aaronjson.NewJsonBool(!any))
+	case stop:
+		return emit(aaronjson.NewJsonBool(any))
+	default:
+		return err
+	}
+}
+
+// rangeValues emits from, from+step, ... up to but excluding upto.
+func rangeValues(from, upto, step aaronjson.JsonValue, emit emitFunc) error {
+	if !isNumber(from) || !isNumber(upto) || !isNumber(step) {
+		return runtimeErrorf("Range bounds must be numeric")
+	}
+	s := floatValue(step)
+	if s == 0 {
+		return nil
+	}
+	current := from
+	for {
+		f := floatValue(current)
+		if (s > 0 && f >= floatValue(upto)) || (s < 0 && f <= floatValue(upto)) {
+			return nil
+		}
+		if err := emit(current); err != nil {
+			return err
+		}
+		next, err := arithmetic("+", current, step)
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+}
+
+// toString returns strings unchanged and the JSON encoding of other values.
+func toString(v aaronjson.JsonValue) string {
+	if v.IsString() {
+		s, _ := v.AsString()
+		return s
+	}
+	return jsonText(v)
+}
+
+// toNumber implements tonumber.
+func toNumber(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if isNumber(in) {
+		return in, nil
+	}
+	if !in.IsString() {
+		return nil, runtimeErrorf("%s cannot be parsed as a number", describe(in))
+	}
+	s, _ := in.AsString()
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return aaronjson.NewJsonInt64(n), nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.TrimLeft(s, "+-.0123456789eE") == "" {
+		return aaronjson.NewJsonFloat(f), nil
+	}
+	return nil, runtimeErrorf("Cannot parse '%s' as a number", s)
+}
+
+// mapASCII shifts the ASCII letters between lo and hi by delta.
+func mapASCII(s string, lo, hi byte, delta int) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= lo && c <= hi {
+			b[i] = byte(int(c) + delta)
+		}
+	}
+	return string(b)
+}
+
+// trimBuiltin implements ltrimstr and rtrimstr, which leave non-string inputs unchanged.
+func trimBuiltin(trim func(s, affix string) string) *builtin {
+	return withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+		if !in.IsString() || !args[0].IsString() {
+			return in, nil
+		}
+		s, _ := in.AsString()
+		affix, _ := args[0].AsString()
+		return aaronjson.NewJsonString(trim(s, affix)), nil
+	})
+}
+
+// join implements join(separator).
+func join(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if !args[0].IsString() {
+		return nil, runtimeErrorf("%s cannot be used as a separator", describe(args[0]))
+	}
+	sep, _ := args[0].AsString()
+	var sb strings.Builder
+	i := 0
+	err := iterate(in, func(_, v aaronjson.JsonValue) error {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		i++
+		switch {
+		case v.IsNull():
+		case v.IsArray() || v.IsObject():
+			return runtimeErrorf("Cannot join with %s", typeName(v))
+		default:
+			sb.WriteString(toString(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aaronjson.NewJsonString(sb.String()), nil
+}
+
+// implode converts an array of code points into a string.
+func implode(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if !in.IsArray() {
+		return nil, runtimeErrorf("%s cannot be imploded", describe(in))
+	}
+	var sb strings.Builder
+	for _, v := range arrayElems(in.(*aaronjson.JsonArray)) {
+		if !isNumber(v) {
+			return nil, runtimeErrorf("Unicode codepoint must be numeric")
+		}
+		sb.WriteRune(rune(floatValue(v)))
+	}
+	return aaronjson.NewJsonString(sb.String()), nil
+}
+
+// indices implements indices(s) for substrings of strings, sub-arrays of
+// arrays and elements of arrays. String positions count code points.
+func indices(in, target aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	var result []aaronjson.JsonValue
+	switch {
+	case in.IsNull():
+		return in, nil
+	case in.IsString() && target.IsString():
+		s, _ := in.AsString()
+		sub, _ := target.AsString()
+		if sub == "" {
+			return aaronjson.NewJsonNull(), nil
+		}
+		for offset := 0; ; {
+			i := strings.Index(s[offset:], sub)
+			if i < 0 {
+				break
+			}
+			result = append(result, aaronjson.NewJsonInt64(int64(utf8.RuneCountInString(s[:offset+i]))))
+			_, size := utf8.DecodeRuneInString(s[offset+i:])
+			offset += i + size
+		}
+	case in.IsArray() && target.IsArray():
+		elems := arrayElems(in.(*aaronjson.JsonArray))
+		sub := arrayElems(target.(*aaronjson.JsonArray))
+		if len(sub) == 0 {
+			return aaronjson.NewJsonNull(), nil
+		}
+	next:
+		for i := 0; i+len(sub) <= len(elems); i++ {
+			for j := range sub {
+				if !aaronjson.Equal(elems[i+j], sub[j]) {
+					continue next
+				}
+			}
+			result = append(result, aaronjson.NewJsonInt64(int64(i)))
+		}
+	case in.IsArray():
+		for i, v := range arrayElems(in.(*aaronjson.JsonArray)) {
+			if aaronjson.Equal(v, target) {
+				result = append(result, aaronjson.NewJsonInt64(int64(i)))
+			}
+		}
+	default:
+		return nil, runtimeErrorf("Cannot determine indices of %s in %s", describe(target), describe(in))
+	}
+	return newArray(result), nil
+}
+
+// pickIndex implements index and rindex on top of indices.
+func pickIndex(in, target aaronjson.JsonValue, first bool) (aaronjson.JsonValue, error) {
+	all, err := indices(in, target)
+	if err != nil || !all.IsArray() {
+		return all, err
+	}
+	elems := arrayElems(all.(*aaronjson.JsonArray))
+	switch {
+	case len(elems) == 0:
+		return aaronjson.NewJsonNull(), nil
+	case first:
+		return elems[0], nil
+	default:
+		return elems[len(elems)-1], nil
+	}
+}
+
+// sortable returns the elements of an array input to be sorted.
+func sortable(in aaronjson.JsonValue, verb string) ([]aaronjson.JsonValue, error) {
+	if !in.IsArray() {
+		return nil, runtimeErrorf("%s cannot be %s, as it is not an array", describe(in), verb)
+	}
+	return arrayElems(in.(*aaronjson.JsonArray)), nil
+}
+
+// byKeys adapts sort_by-style functions: the elements of the input array are
+// stably sorted by the array of outputs of the argument for each element, and
+// then passed to fn along with their keys.
+func byKeys(fn func(elems, keys []aaronjson.JsonValue) (aaronjson.JsonValue, error)) *builtin {
+	return &builtin{eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+		elems, keys, err := sortByKeys(args[0], env, in)
+		if err != nil {
+			return err
+		}
+		result, err := fn(elems, keys)
+		if err != nil {
+			return err
+		}
+		return emit(result)
+	}}
+}
+
+// sortByKeys sorts the elements of the input array by the outputs of f.
+func sortByKeys(f node, env *scope, in aaronjson.JsonValue) ([]aaronjson.JsonValue, []aaronjson.JsonValue, error) {
+	elems, err := sortable(in, "sorted")
+	if err != nil {
+		return nil, nil, err
+	}
+	type keyed struct {
+		key, value aaronjson.JsonValue
+	}
+	items := make([]keyed, len(elems))
+	for i, v := range elems {
+		outputs, err := collect(f, env, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[i] = keyed{key: newArray(outputs), value: v}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return aaronjson.Compare(items[i].key, items[j].key) < 0
+	})
+	sorted := make([]aaronjson.JsonValue, len(items))
+	keys := make([]aaronjson.JsonValue, len(items))
+	for i, item := range items {
+		sorted[i], keys[i] = item.value, item.key
+	}
+	return sorted, keys, nil
+}
+
+// groupRuns splits sorted elements into runs with equal keys.
+func groupRuns(elems, keys []aaronjson.JsonValue) [][]aaronjson.JsonValue {
+	var groups [][]aaronjson.JsonValue
+	for i, v := range elems {
+		if i == 0 || aaronjson.Compare(keys[i-1], keys[i]) != 0 {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], v)
+	}
+	return groups
+}
+
+// extreme returns the minimum or maximum element of an array by keys, or
+// by the elements themselves if keys is nil. Ties go to the first minimum
+// and the last maximum, as in jq.
+func extreme(in aaronjson.JsonValue, keys []aaronjson.JsonValue, max bool) (aaronjson.JsonValue, error) {
+	elems, err := sortable(in, "compared")
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = elems
+	}
+	best := -1
+	for i := range elems {
+		if best < 0 {
+			best = i
+			continue
+		}
+		c := aaronjson.Compare(keys[i], keys[best])
+		if (max && c >= 0) || (!max && c < 0) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return aaronjson.NewJsonNull(), nil
+	}
+	return elems[best], nil
+}
+
+// extremeBy implements min_by and max_by.
+func extremeBy(max bool) *builtin {
+	return &builtin{eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+		elems, err := sortable(in, "compared")
+		if err != nil {
+			return err
+		}
+		keys := make([]aaronjson.JsonValue, len(elems))
+		for i, v := range elems {
+			outputs, err := collect(args[0], env, v)
+			if err != nil {
+				return err
+			}
+			keys[i] = newArray(outputs)
+		}
+		result, err := extreme(in, keys, max)
+		if err != nil {
+			return err
+		}
+		return emit(result)
+	}}
+}
+
+// reverse reverses an array or a string; null reverses to an empty array.
+func reverse(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch {
+	case in.IsNull():
+		return aaronjson.NewJsonArray(), nil
+	case in.IsString():
+		s, _ := in.AsString()
+		runes := []rune(s)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return aaronjson.NewJsonString(string(runes)), nil
+	case in.IsArray():
+		elems := arrayElems(in.(*aaronjson.JsonArray))
+		result := make([]aaronjson.JsonValue, len(elems))
+		for i, v := range elems {
+			result[len(elems)-1-i] = v
+		}
+		return newArray(result), nil
+	default:
+		return nil, runtimeErrorf("Cannot reverse %s", describe(in))
+	}
+}
+
+// flatten flattens nested arrays up to depth levels, or fully if depth is negative.
+func flatten(in aaronjson.JsonValue, depth int) (aaronjson.JsonValue, error) {
+	if !in.IsArray() {
+		return nil, runtimeErrorf("Cannot flatten %s", describe(in))
+	}
+	var result []aaronjson.JsonValue
+	var add func(elems []aaronjson.JsonValue, depth int)
+	add = func(elems []aaronjson.JsonValue, depth int) {
+		for _, v := range elems {
+			if arr, ok := v.(*aaronjson.JsonArray); ok && depth != 0 {
+				add(arrayElems(arr), depth-1)
+			} else {
+				result = append(result, v)
+			}
+		}
+	}
+	add(arrayElems(in.(*aaronjson.JsonArray)), depth)
+	return newArray(result), nil
+}
+
+// transpose transposes an array of arrays, padding short rows with nulls.
+func transpose(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	rows, err := sortable(in, "transposed")
+	if err != nil {
+		return nil, err
+	}
+	width := 0
+	for _, row := range rows {
+		if !row.IsArray() {
+			return nil, runtimeErrorf("Cannot transpose %s", describe(row))
+		}
+		width = max(width, len(arrayElems(row.(*aaronjson.JsonArray))))
+	}
+	columns := make([]aaronjson.JsonValue, width)
+	for j := range columns {
+		column := make([]aaronjson.JsonValue, len(rows))
+		for i, row := range rows {
+			elems := arrayElems(row.(*aaronjson.JsonArray))
+			if j < len(elems) {
+				column[i] = elems[j]
+			} else {
+				column[i] = aaronjson.NewJsonNull()
+			}
+		}
+		columns[j] = newArray(column)
+	}
+	return newArray(columns), nil
+}
+
+// toEntries converts an object into an array of {"key", "value"} objects.
+func toEntries(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	obj, ok := in.(*aaronjson.JsonObject)
+	if !ok {
+		return nil, runtimeErrorf("%s has no keys", describe(in))
+	}
+	var entries []aaronjson.JsonValue
+	for _, key := range objectKeys(obj) {
+		v, _ := objectGet(obj, key)
+		entry := aaronjson.NewJsonObject()
+		_, _ = entry.Set("key", aaronjson.NewJsonString(key))
+		_, _ = entry.Set("value", v)
+		entries = append(entries, entry)
+	}
+	return newArray(entries), nil
+}
+
+// fromEntries builds an object from entries, accepting the key names key, k,
+// name, Name, K and Key and the value names value, v and Value.
+func fromEntries(in aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	result := aaronjson.NewJsonObject()
+	err := iterate(in, func(_, entry aaronjson.JsonValue) error {
+		obj, ok := entry.(*aaronjson.JsonObject)
+		if !ok {
+			return runtimeErrorf("Cannot use %s as an entry", describe(entry))
+		}
+		var key aaronjson.JsonValue = aaronjson.NewJsonNull()
+		for _, name := range []string{"key", "k", "name", "Name", "K", "Key"} {
+			if v, ok := objectGet(obj, name); ok && truthy(v) {
+				key = v
+				break
+			}
+		}
+		var value aaronjson.JsonValue = aaronjson.NewJsonNull()
+		for _, name := range []string{"value", "v", "Value"} {
+			if v, ok := objectGet(obj, name); ok {
+				value = v
+				break
+			}
+		}
+		_, err := result.Set(toString(key), value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// walk applies f bottom-up to every value inside in and then to in itself.
+func walk(f node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+	var walked aaronjson.JsonValue = in
+	switch in.(type) {
+	case *aaronjson.JsonObject:
+		var err error
+		walked, err = mapValues(in, func(v aaronjson.JsonValue) (aaronjson.JsonValue, bool, error) {
+			var first aaronjson.JsonValue
+			stop := &stopError{}
+			err := walk(f, env, v, func(out aaronjson.JsonValue) error {
+				first = out
+				return stop
+			})
+			if err != nil && err != stop {
+				return nil, false, err
+			}
+			return first, first != nil, nil
+		})
+		if err != nil {
+			return err
+		}
+	case *aaronjson.JsonArray:
+		var elems []aaronjson.JsonValue
+		for _, v := range arrayElems(in.(*aaronjson.JsonArray)) {
+			err := walk(f, env, v, func(out aaronjson.JsonValue) error {
+				elems = append(elems, out)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		walked = newArray(elems)
+	}
+	return eval(f, env, walked, emit)
+}
+
+// limitOutputs emits the first n outputs of f.
+func limitOutputs(n int, f node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+	if n <= 0 {
+		return nil
+	}
+	count := 0
+	stop := &stopError{}
+	err := eval(f, env, in, func(v aaronjson.JsonValue) error {
+		if err := emit(v); err != nil {
+			return err
+		}
+		if count++; count == n {
+			return stop
+		}
+		return nil
+	})
+	if err == stop {
+		return nil
+	}
+	return err
+}
+
+// limitPaths emits the first n outputs of the path expression f.
+func limitPaths(n int, f node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+	if n <= 0 {
+		return nil
+	}
+	count := 0
+	stop := &stopError{}
+	err := evalPaths(f, env, in, path, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+		if err := emit(p, v); err != nil {
+			return err
+		}
+		if count++; count == n {
+			return stop
+		}
+		return nil
+	})
+	if err == stop {
+		return nil
+	}
+	return err
+}
+
+// pathArg converts a path given as a JSON array into its components.
+func pathArg(p aaronjson.JsonValue) ([]aaronjson.JsonValue, error) {
+	if !p.IsArray() {
+		return nil, runtimeErrorf("Path must be specified as an array")
+	}
+	return arrayElems(p.(*aaronjson.JsonArray)), nil
+}
+
+// regexBuiltin adapts a function of a string input and a compiled regular
+// expression; withFlags is set when the second argument holds the flags.
+func regexBuiltin(withFlags bool, fn func(s string, re *regexp.Regexp) aaronjson.JsonValue) *builtin {
+	return withValues(func(in aaronjson.JsonValue, args []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+		if !in.IsString() {
+			return nil, runtimeErrorf("%s cannot be matched, as it is not a string", describe(in))
+		}
+		flags := aaronjson.JsonValue(aaronjson.NewJsonNull())
+		if withFlags {
+			flags = args[1]
+		}
+		re, _, err := compileRegex(args[0], flags)
+		if err != nil {
+			return nil, err
+		}
+		s, _ := in.AsString()
+		return fn(s, re), nil
+	})
+}
+
+// compileRegex compiles a pattern with jq's flags: g (global), i (ignore
+// case), s (dot matches newlines), l (longest match) and n (ignore empty
+// matches). It reports whether the g flag was given.
+func compileRegex(pattern, flags aaronjson.JsonValue) (*regexp.Regexp, bool, error) {
+	if !pattern.IsString() {
+		return nil, false, runtimeErrorf("%s cannot be matched, as it is not a string", describe(pattern))
+	}
+	expr, _ := pattern.AsString()
+	var prefix string
+	global, longest := false, false
+	if !flags.IsNull() {
+		f, err := flags.AsString()
+		if err != nil || !flags.IsString() {
+			return nil, false, runtimeErrorf("%s is not a string", describe(flags))
+		}
+		for _, c := range f {
+			switch c {
+			case 'g':
+				global = true
+			case 'i':
+				prefix += "i"
+			case 's':
+				prefix += "s"
+			case 'l':
+				longest = true
+			case 'n':
+			default:
+				return nil, false, runtimeErrorf("%s is not a valid modifier string", f)
+			}
+		}
+	}
+	if prefix != "" {
+		expr = "(?" + prefix + ")" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, false, runtimeErrorf("%s (at offset 0) is not a valid regex: %v", expr, err)
+	}
+	if longest {
+		re.Longest()
+	}
+	return re, global, nil
+}
+
+func testRegex(s string, re *regexp.Regexp) aaronjson.JsonValue {
+	return aaronjson.NewJsonBool(re.MatchString(s))
+}
+
+func captureRegex(s string, re *regexp.Regexp) aaronjson.JsonValue {
+	m := re.FindStringSubmatchIndex(s)
+	if m == nil {
+		return aaronjson.NewJsonNull()
+	}
+	return captureObject(s, re, m)
+}
+
+// captureObject returns the named groups of a match as an object, with null
+// for groups that did not participate.
+func captureObject(s string, re *regexp.Regexp, m []int) *aaronjson.JsonObject {
+	obj := aaronjson.NewJsonObject()
+	for i, name := range re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		var v aaronjson.JsonValue = aaronjson.NewJsonNull()
+		if m[2*i] >= 0 {
+			v = aaronjson.NewJsonString(s[m[2*i]:m[2*i+1]])
+		}
+		_, _ = obj.Set(name, v)
+	}
+	return obj
+}
+
+// substitute implements sub and gsub. The replacement is a filter run on
+// the object of named captures of each match; each of its outputs yields a
+// result.
+func substitute(args []node, env *scope, in aaronjson.JsonValue, global bool, emit emitFunc) error {
+	if !in.IsString() {
+		return runtimeErrorf("%s cannot be matched, as it is not a string", describe(in))
+	}
+	s, _ := in.AsString()
+	flagArgs := args[:1]
+	if len(args) == 3 {
+		flagArgs = []node{args[0], args[2]}
+	}
+	return cartesian(flagArgs, env, in, nil, func(values []aaronjson.JsonValue) error {
+		flags := aaronjson.JsonValue(aaronjson.NewJsonNull())
+		if len(values) == 2 {
+			flags = values[1]
+		}
+		re, g, err := compileRegex(values[0], flags)
+		if err != nil {
+			return err
+		}
+		limit := 1
+		if global || g {
+			limit = -1
+		}
+		matches := re.FindAllStringSubmatchIndex(s, limit)
+
+		var build func(i, last int, prefix string) error
+		build = func(i, last int, prefix string) error {
+			if i == len(matches) {
+				return emit(aaronjson.NewJsonString(prefix + s[last:]))
+			}
+			m := matches[i]
+			return eval(args[1], env, captureObject(s, re, m), func(r aaronjson.JsonValue) error {
+				if !r.IsString() {
+					return runtimeErrorf("%s cannot be added to a string", describe(r))
+				}
+				rs, _ := r.AsString()
+				return build(i+1, m[1], prefix+s[last:m[0]]+rs)
+			})
+		}
+		return build(0, 0, "")
+	})
+}
+
+// matchBuiltin adapts match and scan: fn is called for the first match of
+// the regular expression in the input string, or for every match if all is
+// set or the g flag is given.
+func matchBuiltin(withFlags, all bool, fn func(s string, re *regexp.Regexp, m []int) aaronjson.JsonValue) *builtin {
+	return &builtin{eval: func(args []node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+		if !in.IsString() {
+			return runtimeErrorf("%s cannot be matched, as it is not a string", describe(in))
+		}
+		s, _ := in.AsString()
+		return cartesian(args, env, in, nil, func(values []aaronjson.JsonValue) error {
+			flags := aaronjson.JsonValue(aaronjson.NewJsonNull())
+			if withFlags {
+				flags = values[1]
+			}
+			re, global, err := compileRegex(values[0], flags)
+			if err != nil {
+				return err
+			}
+			limit := 1
+			if global || all {
+				limit = -1
+			}
+			for _, m := range re.FindAllStringSubmatchIndex(s, limit) {
+				if err := emit(fn(s, re, m)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}}
+}
+
+// matchObject describes a match as jq does, with offsets and lengths in code points.
+func matchObject(s string, re *regexp.Regexp, m []int) aaronjson.JsonValue {
+	describeSpan := func(start, end int) *aaronjson.JsonObject {
+		obj := aaronjson.NewJsonObject()
+		if start < 0 {
+			_, _ = obj.Set("offset", aaronjson.NewJsonInt64(-1))
+			_, _ = obj.Set("length", aaronjson.NewJsonInt64(0))
+			_, _ = obj.Set("string", aaronjson.NewJsonNull())
+			return obj
+		}
+		_, _ = obj.Set("offset", aaronjson.NewJsonInt64(int64(utf8.RuneCountInString(s[:start]))))
+		_, _ = obj.Set("length", aaronjson.NewJsonInt64(int64(utf8.RuneCountInString(s[start:end]))))
+		_, _ = obj.Set("string", aaronjson.NewJsonString(s[start:end]))
+		return obj
+	}
+	result := describeSpan(m[0], m[1])
+	var captures []aaronjson.JsonValue
+	for i, name := range re.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		capture := describeSpan(m[2*i], m[2*i+1])
+		var v aaronjson.JsonValue = aaronjson.NewJsonNull()
+		if name != "" {
+			v = aaronjson.NewJsonString(name)
+		}
+		_, _ = capture.Set("name", v)
+		captures = append(captures, capture)
+	}
+	_, _ = result.Set("captures", newArray(captures))
+	return result
+}
+
+// scanResult returns the matched text, or the array of captured texts if the
+// expression has groups.
+func scanResult(s string, re *regexp.Regexp, m []int) aaronjson.JsonValue {
+	if re.NumSubexp() == 0 {
+		return aaronjson.NewJsonString(s[m[0]:m[1]])
+	}
+	groups := make([]aaronjson.JsonValue, re.NumSubexp())
+	for i := range groups {
+		groups[i] = aaronjson.NewJsonNull()
+		if start := m[2*i+2]; start >= 0 {
+			groups[i] = aaronjson.NewJsonString(s[start:m[2*i+3]])
+		}
+	}
+	return newArray(groups)
+}
+
+// splitRegex splits a string around the matches of a regular expression.
+func splitRegex(in, pattern, flags aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if !in.IsString() {
+		return nil, runtimeErrorf("%s cannot be matched, as it is not a string", describe(in))
+	}
+	re, _, err := compileRegex(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+	s, _ := in.AsString()
+	var parts []aaronjson.JsonValue
+	last := 0
+	for _, m := range re.FindAllStringIndex(s, -1) {
+		parts = append(parts, aaronjson.NewJsonString(s[last:m[0]]))
+		last = m[1]
+	}
+	parts = append(parts, aaronjson.NewJsonString(s[last:]))
+	return newArray(parts), nil
+}
@@ -0,0 +1,112 @@
+package jq
+
+import "testing"
+
+func TestBuiltins(t *testing.T) {
+	runCases(t, []struct{ expr, input, want string }{
+		// Types and structure
+		{`map(length)`, `[null, -2, "héllo", [1,2], {"a":1}]`, `[0,2,5,2,1]`},
+		{`utf8bytelength`, `"héllo"`, `6`},
+		{`map(type)`, `[null, true, 1, "s", [], {}]`, `["null","boolean","number","string","array","object"]`},
+		{`keys, keys_unsorted`, `{"b":1,"a":2}`, `["a","b"] ["b","a"]`},
+		{`keys`, `[5,6]`, `[0,1]`},
+		{`has("a"), has("z")`, `{"a":null}`, `true false`},
+		{`map(in({"a":1}))`, `["a","b"]`, `[true,false]`},
+		{`contains({a: [1]}), contains({b: "y"})`, `{"a":[1,2],"b":"xyz"}`, `true true`},
+		{`contains("baz"), inside("foobarbaz")`, `"bar"`, `false true`},
+		{`[.[] | numbers], [.[] | strings], [.[] | iterables], [.[] | scalars]`, `[1,"a",[],{},null]`, `[1] ["a"] [[],{}] [1,"a",null]`},
+		{`[.[] | values], [.[] | nulls], [.[] | booleans]`, `[1,null,false]`, `[1,false] [null] [false]`},
+		{`to_entries`, `{"a":1,"b":2}`, `[{"key":"a","value":1},{"key":"b","value":2}]`},
+		{`from_entries`, `[{"key":"a","value":1},{"k":"b","v":2},{"name":1,"value":3},{"key":"c"}]`, `{"a":1,"b":2,"1":3,"c":null}`},
+		{`with_entries(.value += 1)`, `{"a":1,"b":2}`, `{"a":2,"b":3}`},
+		{`map_values(. * 10), map_values(empty)`, `{"a":1,"b":2}`, `{"a":10,"b":20} {}`},
+		{`add, (map(tostring) | add), ([] | add)`, `[1,2,3]`, `6 "123" null`},
+		{`add(.[].a)`, `[{"a":1},{"a":2}]`, `3`},
+		{`any, all`, `[true,false]`, `true false`},
+		{`any(. > 2), all(. > 0)`, `[1,2,3]`, `true true`},
+		{`any(.[]; . == 2), all(empty; false)`, `[1,2]`, `true true`},
+		{`IN(2, 3), IN(.[]; 5, 1)`, `[1]`, `false true`},
+		{`isempty(empty), isempty(1, error("x"))`, `null`, `true false`},
+		{`[range(3)], [range(1; 3)], [range(0; 10; 4)], [range(3; 0; -1)]`, `null`, `[0,1,2] [1,2] [0,4,8] [3,2,1]`},
+		{`flatten, flatten(1)`, `[1,[2,[3,[4]]]]`, `[1,2,3,4] [1,2,[3,[4]]]`},
+		{`reverse, ("abc" | reverse), (null | reverse)`, `[1,2,3]`, `[3,2,1] "cba" []`},
+		{`transpose`, `[[1,2],[3]]`, `[[1,3],[2,null]]`},
+		{`toarray, (1 | toarray)`, `[1]`, `[1] [1]`},
+		{`walk(if type == "array" then sort else . end)`, `{"a":[3,1],"b":[{"c":[2,1]}]}`, `{"a":[1,3],"b":[{"c":[1,2]}]}`},
+
+		// Math
+		{`map(floor), map(ceil), map(round), map(fabs)`, `[-1.5, 2.5]`, `[-2,2] [-1,3] [-2,3] [1.5,2.5]`},
+		{`sqrt, pow(.; 2), log10, (2 | exp10)`, `100`, `10 10000 2 100`},
+		{`abs, (-5 | abs)`, `-2.5`, `2.5 5`},
+		{`[infinite, -infinite, nan] | map(isinfinite), map(isnan)`, `null`, `[true,true,false] [false,false,true]`},
+
+		// Conversion and strings
+		{`map(tostring)`, `[1, "a", [1], null]`, `["1","a","[1]","null"]`},
+		{`map(tonumber)`, `["1", "-1.5", 3]`, `[1,-1.5,3]`},
+		{`tojson, (tojson | fromjson)`, `{"a":[1,"x"]}`, `"{\"a\":[1,\"x\"]}" {"a":[1,"x"]}`},
+		{`ascii_downcase, ascii_upcase`, `"AbC é"`, `"abc é" "ABC é"`},
+		{`ltrimstr("ab"), rtrimstr("yz"), (1 | ltrimstr("a"))`, `"abxyz"`, `"xyz" "abx" 1`},
+		{`startswith("ab"), endswith("z")`, `"abc"`, `true false`},
+		{`trim, ltrim, rtrim`, `"  a b  "`, `"a b" "a b  " "  a b"`},
+		{`split(", "), (split(", ") | join("-"))`, `"a, b, c"`, `["a","b","c"] "a-b-c"`},
+		{`join(",")`, `["a", 1, null, true]`, `"a,1,,true"`},
+		{`explode, (explode | implode)`, `"aé"`, `[97,233] "aé"`},
+		{`indices(", "), index(", "), rindex(", ")`, `"a, b, c"`, `[1,4] 1 4`},
+		{`indices(1), indices([1,2])`, `[0,1,2,1,2]`, `[1,3] [1,3]`},
+
+		// Regular expressions
+		{`test("B"), test("B"; "i")`, `"abc"`, `false true`},
+		{`[match("a+"; "g") | .offset]`, `"aa b aaa"`, `[0,5]`},
+		{`match("(?<x>\\d)(\\w)?") | .captures | map(.name)`, `"é1"`, `["x",null]`},
+		{`match("\\d") | .offset`, `"éé7"`, `2`},
+		{`capture("(?<year>\\d+)-(?<month>\\d+)")`, `"2024-05"`, `{"year":"2024","month":"05"}`},
+		{`[scan("\\d+")], [scan("(a)(b)")]`, `"12 ab 3 ab"`, `["12","3"] [["a","b"],["a","b"]]`},
+		{`split(", *"; null), [splits("a")]`, `"x, y,z"`, `["x","y","z"] ["x, y,z"]`},
+		{`sub("a"; "X"), gsub("a"; "X"), gsub("A"; "x"; "i")`, `"banana"`, `"bXnana" "bXnXnX" "bxnxnx"`},
+		{`gsub("(?<d>\\d)"; "<\(.d)>")`, `"a1b2"`, `"a<1>b<2>"`},
+		{`[sub("a"; "x", "y")]`, `"a"`, `["x","y"]`},
+
+		// Sorting and grouping
+		{`sort`, `[3, "a", null, true, false, [1], {"a":1}, 1.5]`, `[null,false,true,1.5,3,"a",[1],{"a":1}]`},
+		{`sort_by(.a), sort_by(.a, .b) | map(.b)`, `[{"a":2,"b":1},{"a":1,"b":2},{"a":1,"b":0}]`, `[2,0,1] [0,2,1]`},
+		{`group_by(.a) | map(map(.b))`, `[{"a":2,"b":1},{"a":1,"b":2},{"a":2,"b":3}]`, `[[2],[1,3]]`},
+		{`unique, unique_by(length)`, `["b","a","bb","a"]`, `["a","b","bb"] ["b","bb"]`},
+		{`min, max, ([] | min)`, `[3,1,2]`, `1 3 null`},
+		{`min_by(.a).b, max_by(.a).b`, `[{"a":1,"b":"x"},{"a":1,"b":"y"},{"a":0,"b":"z"},{"a":2,"b":"u"},{"a":2,"b":"v"}]`, `"z" "v"`},
+
+		// Generators and control flow
+		{`[.[] | select(. > 1)]`, `[1,2,3]`, `[2,3]`},
+		{`[empty], [1, empty, 2]`, `null`, `[] [1,2]`},
+		{`try error catch ., try error("x") catch ., try error(null) catch .`, `"in"`, `"in" "x" null`},
+		{`([recurse] | length), [recurse(.[]?; . != 2)]`, `[1,[2]]`, `4 [[1,[2]],1,[2]]`},
+		{`[recurse(if . < 3 then . + 1 else empty end)]`, `0`, `[0,1,2,3]`},
+		{`[limit(3; repeat(. * 2))]`, `1`, `[1,2,4]`},
+		{`first, last, nth(1)`, `[1,2,3]`, `1 3 2`},
+		{`first(range(10; 20)), last(range(10; 20)), nth(2; range(10; 20))`, `null`, `10 19 12`},
+		{`[limit(2; .[])], [limit(0; .[])], [first(empty)]`, `[1,2,3]`, `[1,2] [] []`},
+		{`until(. > 100; . * 2), [while(. < 10; . + 4)]`, `1`, `128 [1,5,9]`},
+		{`debug`, `1`, `1`},
+
+		// Paths
+		{`path(.a[0].b), [paths], [leaf_paths]`, `{"a":[{"b":1}]}`, `["a",0,"b"] [["a"],["a",0],["a",0,"b"]] [["a",0,"b"]]`},
+		{`[paths(type == "number")]`, `{"a":1,"b":{"c":2},"d":"x"}`, `[["a"],["b","c"]]`},
+		{`path(..)`, `[[1]]`, `[] [0] [0,0]`},
+		{`[path(.[] | select(. > 1))], path(first(.[]))`, `[1,2,3]`, `[[1],[2]] [0]`},
+		{`path(getpath(["a","b"]))`, `null`, `["a","b"]`},
+		{`[path(.a[1:2])]`, `{"a":[1,2,3]}`, `[["a",{"start":1,"end":2}]]`},
+		{`getpath(["a","b"]), getpath(["x","y"])`, `{"a":{"b":1}}`, `1 null`},
+		{`setpath(["a","b"]; 2), setpath([]; 3)`, `{"a":{"b":1}}`, `{"a":{"b":2}} 3`},
+		{`delpaths([["a","b"], ["c"]])`, `{"a":{"b":1,"x":2},"c":3}`, `{"a":{"x":2}}`},
+		{`del(.a), del(.[]), del(..)`, `{"a":1,"b":2}`, `{"b":2} {} null`},
+		{`pick(.a.b, .c[1])`, `{"a":{"b":1,"x":2},"c":[1,2,3]}`, `{"a":{"b":1},"c":[null,2]}`},
+
+		// Formats
+		{`@text, @json`, `[1,"a"]`, `"[1,\"a\"]" "[1,\"a\"]"`},
+		{`@html`, `"<a href='x'>&</a>"`, `"&lt;a href=&#39;x&#39;&gt;&amp;&lt;/a&gt;"`},
+		{`@uri`, `"a b/é"`, `"a%20b%2F%C3%A9"`},
+		{`@csv, @tsv`, `[1, "a\"b", null, "c\td"]`, `"1,\"a\"\"b\",,\"c\td\"" "1\ta\"b\t\tc\\td"`},
+		{`@sh, (["a", 1] | @sh)`, `"it's"`, `"'it'\\''s'" "'a' 1"`},
+		{`@base64, (@base64 | @base64d)`, `"héllo"`, `"aMOpbGxv" "héllo"`},
+		{`@sh "echo \(.)"`, `"$HOME"`, `"echo '$HOME'"`},
+	})
+}
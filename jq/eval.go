@@ -0,0 +1,546 @@
+package jq
+
+import (
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// emitFunc receives the outputs of an expression one at a time. Returning an
+// error stops the expression.
+type emitFunc func(aaronjson.JsonValue) error
+
+// pathFunc receives the outputs of an expression evaluated as a path
+// expression: the path of each output within the input, and its value.
+type pathFunc func(path []aaronjson.JsonValue, v aaronjson.JsonValue) error
+
+// scope is a linked list of variable bindings, innermost first.
+type scope struct {
+	name   string
+	value  aaronjson.JsonValue
+	parent *scope
+}
+
+// bind returns a scope in which name is bound to value.
+func (s *scope) bind(name string, value aaronjson.JsonValue) *scope {
+	return &scope{name: name, value: value, parent: s}
+}
+
+// lookup returns the value bound to name. The parser guarantees it exists.
+func (s *scope) lookup(name string) aaronjson.JsonValue {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s.value
+		}
+	}
+	return aaronjson.NewJsonNull()
+}
+
+// eval runs the expression n on the input in, passing each output to emit.
+func eval(n node, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+	switch n := n.(type) {
+	case identityNode:
+		return emit(in)
+	case recurseNode:
+		return recurseValues(in, emit)
+	case literalNode:
+		return emit(n.value)
+	case varNode:
+		return emit(env.lookup(n.name))
+	case formatNode:
+		s, err := applyFormat(n.name, in)
+		if err != nil {
+			return err
+		}
+		return emit(aaronjson.NewJsonString(s))
+	case indexNode:
+		return eval(n.target, env, in, func(target aaronjson.JsonValue) error {
+			return eval(n.index, env, in, func(index aaronjson.JsonValue) error {
+				v, err := indexValue(target, index)
+				if err != nil {
+					return err
+				}
+				return emit(v)
+			})
+		})
+	case sliceNode:
+		return eval(n.target, env, in, func(target aaronjson.JsonValue) error {
+			return evalBounds(n, env, in, func(from, to aaronjson.JsonValue) error {
+				v, err := sliceValue(target, from, to)
+				if err != nil {
+					return err
+				}
+				return emit(v)
+			})
+		})
+	case iterateNode:
+		return eval(n.target, env, in, func(target aaronjson.JsonValue) error {
+			return iterate(target, func(_, v aaronjson.JsonValue) error {
+				return emit(v)
+			})
+		})
+	case pipeNode:
+		return eval(n.left, env, in, func(v aaronjson.JsonValue) error {
+			return eval(n.right, env, v, emit)
+		})
+	case commaNode:
+		if err := eval(n.left, env, in, emit); err != nil {
+			return err
+		}
+		return eval(n.right, env, in, emit)
+	case negateNode:
+		return eval(n.operand, env, in, func(v aaronjson.JsonValue) error {
+			if !isNumber(v) {
+				return runtimeErrorf("%s cannot be negated", describe(v))
+			}
+			result, err := arithmetic("-", aaronjson.NewJsonInt64(0), v)
+			if err != nil {
+				return err
+			}
+			return emit(result)
+		})
+	case binaryNode:
+		// As in jq, the right operand is the outer loop
+		return eval(n.right, env, in, func(r aaronjson.JsonValue) error {
+			return eval(n.left, env, in, func(l aaronjson.JsonValue) error {
+				v, err := binary(n.op, l, r)
+				if err != nil {
+					return err
+				}
+				return emit(v)
+			})
+		})
+	case andNode:
+		return eval(n.left, env, in, func(l aaronjson.JsonValue) error {
+			if !truthy(l) {
+				return emit(aaronjson.NewJsonBool(false))
+			}
+			return eval(n.right, env, in, func(r aaronjson.JsonValue) error {
+				return emit(aaronjson.NewJsonBool(truthy(r)))
+			})
+		})
+	case orNode:
+		return eval(n.left, env, in, func(l aaronjson.JsonValue) error {
+			if truthy(l) {
+				return emit(aaronjson.NewJsonBool(true))
+			}
+			return eval(n.right, env, in, func(r aaronjson.JsonValue) error {
+				return emit(aaronjson.NewJsonBool(truthy(r)))
+			})
+		})
+	case alternativeNode:
+		// Errors on the left count as producing no value
+		var values []aaronjson.JsonValue
+		_ = eval(n.left, env, in, func(v aaronjson.JsonValue) error {
+			if truthy(v) {
+				values = append(values, v)
+			}
+			return nil
+		})
+		if len(values) == 0 {
+			return eval(n.right, env, in, emit)
+		}
+		for _, v := range values {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case assignNode:
+		return evalAssign(n, env, in, emit)
+	case arrayNode:
+		arr := aaronjson.NewJsonArray()
+		if n.body != nil {
+			err := eval(n.body, env, in, func(v aaronjson.JsonValue) error {
+				_, err := arr.Append(v)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return emit(arr)
+	case objectNode:
+		return buildObject(n.entries, env, in, nil, emit)
+	case stringNode:
+		return buildString(n, len(n.parts), env, in, "", emit)
+	case bindNode:
+		return eval(n.source, env, in, func(v aaronjson.JsonValue) error {
+			return eval(n.body, env.bind(n.name, v), in, emit)
+		})
+	case reduceNode:
+		return eval(n.init, env, in, func(acc aaronjson.JsonValue) error {
+			err := eval(n.source, env, in, func(item aaronjson.JsonValue) error {
+				next := aaronjson.JsonValue(aaronjson.NewJsonNull())
+				err := eval(n.update, env.bind(n.name, item), acc, func(v aaronjson.JsonValue) error {
+					next = v
+					return nil
+				})
+				acc = next
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return emit(acc)
+		})
+	case foreachNode:
+		return eval(n.init, env, in, func(acc aaronjson.JsonValue) error {
+			return eval(n.source, env, in, func(item aaronjson.JsonValue) error {
+				itemEnv := env.bind(n.name, item)
+				return eval(n.update, itemEnv, acc, func(v aaronjson.JsonValue) error {
+					acc = v
+					if n.extract == nil {
+						return emit(v)
+					}
+					return eval(n.extract, itemEnv, v, emit)
+				})
+			})
+		})
+	case ifNode:
+		return eval(n.cond, env, in, func(c aaronjson.JsonValue) error {
+			switch {
+			case truthy(c):
+				return eval(n.then, env, in, emit)
+			case n.otherwise != nil:
+				return eval(n.otherwise, env, in, emit)
+			default:
+				return emit(in)
+			}
+		})
+	case tryNode:
+		var passed *consumerError
+		err := eval(n.body, env, in, func(v aaronjson.JsonValue) error {
+			if err := emit(v); err != nil {
+				passed = &consumerError{err: err}
+				return passed
+			}
+			return nil
+		})
+		if err == nil {
+			return nil
+		}
+		if passed != nil && err == passed {
+			return passed.err
+		}
+		re, ok := err.(*RuntimeError)
+		if !ok {
+			return err
+		}
+		if n.handler == nil {
+			return nil
+		}
+		return eval(n.handler, env, re.Value, emit)
+	case callNode:
+		return n.fn.eval(n.args, env, in, emit)
+	default:
+		return runtimeErrorf("unsupported expression %T", n)
+	}
+}
+
+// evalBounds evaluates the bounds of a slice, using null for a missing bound.
+func evalBounds(n sliceNode, env *scope, in aaronjson.JsonValue, fn func(from, to aaronjson.JsonValue) error) error {
+	bound := func(b node, fn func(aaronjson.JsonValue) error) error {
+		if b == nil {
+			return fn(aaronjson.NewJsonNull())
+		}
+		return eval(b, env, in, fn)
+	}
+	return bound(n.to, func(to aaronjson.JsonValue) error {
+		return bound(n.from, func(from aaronjson.JsonValue) error {
+			return fn(from, to)
+		})
+	})
+}
+
+// recurseValues emits v and then, depth first, every value inside it.
+func recurseValues(v aaronjson.JsonValue, emit emitFunc) error {
+	if err := emit(v); err != nil {
+		return err
+	}
+	if !v.IsObject() && !v.IsArray() {
+		return nil
+	}
+	return iterate(v, func(_, child aaronjson.JsonValue) error {
+		return recurseValues(child, emit)
+	})
+}
+
+// buildObject emits an object for every combination of the outputs of the
+// keys and values of entries. pairs holds the keys and values chosen so far.
+func buildObject(entries []objectEntry, env *scope, in aaronjson.JsonValue, pairs []aaronjson.JsonValue, emit emitFunc) error {
+	if len(entries) == 0 {
+		obj := aaronjson.NewJsonObject()
+		for i := 0; i < len(pairs); i += 2 {
+			key, _ := pairs[i].AsString()
+			_, _ = obj.Set(key, pairs[i+1])
+		}
+		return emit(obj)
+	}
+	entry := entries[0]
+	return eval(entry.key, env, in, func(key aaronjson.JsonValue) error {
+		if !key.IsString() {
+			return runtimeErrorf("Object keys must be strings, not %s", describe(key))
+		}
+		return eval(entry.value, env, in, func(value aaronjson.JsonValue) error {
+			return buildObject(entries[1:], env, in, append(pairs[:len(pairs):len(pairs)], key, value), emit)
+		})
+	})
+}
+
+// buildString emits a string for every combination of the outputs of the
+// interpolations among the first end parts of n; suffix holds the text built
+// from the parts after them. As in jq, later interpolations vary slowest.
+func buildString(n stringNode, end int, env *scope, in aaronjson.JsonValue, suffix string, emit emitFunc) error {
+	if end == 0 {
+		return emit(aaronjson.NewJsonString(suffix))
+	}
+	part := n.parts[end-1]
+	if part.expr == nil {
+		return buildString(n, end-1, env, in, part.text+suffix, emit)
+	}
+	return eval(part.expr, env, in, func(v aaronjson.JsonValue) error {
+		format := n.format
+		if format == "" {
+			format = "text"
+		}
+		s, err := applyFormat(format, v)
+		if err != nil {
+			return err
+		}
+		return buildString(n, end-1, env, in, s+suffix, emit)
+	})
+}
+
+// evalPaths runs the path expression n on the input in, located at path
+// within the document being updated, passing the path and value of each
+// output to emit. Missing members have the value null.
+func evalPaths(n node, env *scope, in aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+	switch n := n.(type) {
+	case identityNode:
+		return emit(path, in)
+	case recurseNode:
+		return recursePaths(in, path, emit)
+	case indexNode:
+		return evalPaths(n.target, env, in, path, func(p []aaronjson.JsonValue, target aaronjson.JsonValue) error {
+			return eval(n.index, env, in, func(index aaronjson.JsonValue) error {
+				v, err := indexValue(target, index)
+				if err != nil {
+					return err
+				}
+				return emit(appendPath(p, index), v)
+			})
+		})
+	case sliceNode:
+		return evalPaths(n.target, env, in, path, func(p []aaronjson.JsonValue, target aaronjson.JsonValue) error {
+			return evalBounds(n, env, in, func(from, to aaronjson.JsonValue) error {
+				v, err := sliceValue(target, from, to)
+				if err != nil {
+					return err
+				}
+				key := aaronjson.NewJsonObject()
+				_, _ = key.Set("start", from)
+				_, _ = key.Set("end", to)
+				return emit(appendPath(p, key), v)
+			})
+		})
+	case iterateNode:
+		return evalPaths(n.target, env, in, path, func(p []aaronjson.JsonValue, target aaronjson.JsonValue) error {
+			return iterate(target, func(key, v aaronjson.JsonValue) error {
+				return emit(appendPath(p, key), v)
+			})
+		})
+	case pipeNode:
+		return evalPaths(n.left, env, in, path, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+			return evalPaths(n.right, env, v, p, emit)
+		})
+	case commaNode:
+		if err := evalPaths(n.left, env, in, path, emit); err != nil {
+			return err
+		}
+		return evalPaths(n.right, env, in, path, emit)
+	case alternativeNode:
+		type found struct {
+			path  []aaronjson.JsonValue
+			value aaronjson.JsonValue
+		}
+		var results []found
+		_ = evalPaths(n.left, env, in, path, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+			if truthy(v) {
+				results = append(results, found{p, v})
+			}
+			return nil
+		})
+		if len(results) == 0 {
+			return evalPaths(n.right, env, in, path, emit)
+		}
+		for _, r := range results {
+			if err := emit(r.path, r.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ifNode:
+		return eval(n.cond, env, in, func(c aaronjson.JsonValue) error {
+			switch {
+			case truthy(c):
+				return evalPaths(n.then, env, in, path, emit)
+			case n.otherwise != nil:
+				return evalPaths(n.otherwise, env, in, path, emit)
+			default:
+				return emit(path, in)
+			}
+		})
+	case tryNode:
+		if n.handler != nil {
+			break
+		}
+		var passed *consumerError
+		err := evalPaths(n.body, env, in, path, func(p []aaronjson.JsonValue, v aaronjson.JsonValue) error {
+			if err := emit(p, v); err != nil {
+				passed = &consumerError{err: err}
+				return passed
+			}
+			return nil
+		})
+		if passed != nil && err == passed {
+			return passed.err
+		}
+		if _, ok := err.(*RuntimeError); ok {
+			return nil
+		}
+		return err
+	case bindNode:
+		return eval(n.source, env, in, func(v aaronjson.JsonValue) error {
+			return evalPaths(n.body, env.bind(n.name, v), in, path, emit)
+		})
+	case callNode:
+		if n.fn.paths != nil {
+			return n.fn.paths(n.args, env, in, path, emit)
+		}
+	}
+	// Not a path expression; report the value it produces, as jq does
+	v, ok, err := firstOutput(n, env, in)
+	if err != nil || !ok {
+		return err
+	}
+	return runtimeErrorf("Invalid path expression with result %s", shorten(v))
+}
+
+// recursePaths emits the path and value of v and of every value inside it.
+func recursePaths(v aaronjson.JsonValue, path []aaronjson.JsonValue, emit pathFunc) error {
+	if err := emit(path, v); err != nil {
+		return err
+	}
+	if !v.IsObject() && !v.IsArray() {
+		return nil
+	}
+	return iterate(v, func(key, child aaronjson.JsonValue) error {
+		return recursePaths(child, appendPath(path, key), emit)
+	})
+}
+
+// appendPath returns path extended by key without modifying path.
+func appendPath(path []aaronjson.JsonValue, key aaronjson.JsonValue) []aaronjson.JsonValue {
+	return append(path[:len(path):len(path)], key)
+}
+
+// collectPaths returns the paths selected by the path expression n on in.
+func collectPaths(n node, env *scope, in aaronjson.JsonValue) ([][]aaronjson.JsonValue, error) {
+	var paths [][]aaronjson.JsonValue
+	err := evalPaths(n, env, in, nil, func(p []aaronjson.JsonValue, _ aaronjson.JsonValue) error {
+		paths = append(paths, p)
+		return nil
+	})
+	return paths, err
+}
+
+// evalAssign evaluates the assignment operators.
+func evalAssign(n assignNode, env *scope, in aaronjson.JsonValue, emit emitFunc) error {
+	paths, err := collectPaths(n.lhs, env, in)
+	if err != nil {
+		return err
+	}
+
+	if n.op == "|=" {
+		// Each path is updated with the first output of the right side,
+		// and deleted if there is none
+		result := in
+		for _, p := range paths {
+			current, err := getPath(result, p)
+			if err != nil {
+				return err
+			}
+			updated, found, err := firstOutput(n.rhs, env, current)
+			if err != nil {
+				return err
+			}
+			if found {
+				result, err = setPath(result, p, updated)
+			} else {
+				result, err = deletePaths(result, [][]aaronjson.JsonValue{p})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return emit(result)
+	}
+
+	// The right side is evaluated against the original input, once per output
+	return eval(n.rhs, env, in, func(rhs aaronjson.JsonValue) error {
+		result := in
+		for _, p := range paths {
+			value := rhs
+			if n.op != "=" {
+				current, err := getPath(result, p)
+				if err != nil {
+					return err
+				}
+				op := n.op[:len(n.op)-1]
+				if op == "//" {
+					if truthy(current) {
+						value = current
+					}
+				} else if value, err = arithmetic(op, current, rhs); err != nil {
+					return err
+				}
+			}
+			var err error
+			if result, err = setPath(result, p, value); err != nil {
+				return err
+			}
+		}
+		return emit(result)
+	})
+}
+
+// stopError ends a generator early once a consumer has seen enough outputs.
+// Each use creates its own value so that nested generators stop independently.
+type stopError struct{}
+
+func (*stopError) Error() string {
+	return "stop"
+}
+
+// firstOutput returns the first output of n on in, if there is one.
+func firstOutput(n node, env *scope, in aaronjson.JsonValue) (aaronjson.JsonValue, bool, error) {
+	var first aaronjson.JsonValue
+	stop := &stopError{}
+	err := eval(n, env, in, func(v aaronjson.JsonValue) error {
+		first = v
+		return stop
+	})
+	if err != nil && err != stop {
+		return nil, false, err
+	}
+	return first, first != nil, nil
+}
+
+// collect returns all outputs of n on in.
+func collect(n node, env *scope, in aaronjson.JsonValue) ([]aaronjson.JsonValue, error) {
+	var values []aaronjson.JsonValue
+	err := eval(n, env, in, func(v aaronjson.JsonValue) error {
+		values = append(values, v)
+		return nil
+	})
+	return values, err
+}
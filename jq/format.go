@@ -0,0 +1,118 @@
+package jq
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// formats maps the name of each @format to its implementation.
+var formats = map[string]func(v aaronjson.JsonValue) (string, error){
+	"text": func(v aaronjson.JsonValue) (string, error) { return toString(v), nil },
+	"json": func(v aaronjson.JsonValue) (string, error) { return jsonText(v), nil },
+	"html": func(v aaronjson.JsonValue) (string, error) {
+		return htmlEscaper.Replace(toString(v)), nil
+	},
+	"uri": func(v aaronjson.JsonValue) (string, error) {
+		var sb strings.Builder
+		for _, c := range []byte(toString(v)) {
+			if isUnreserved(c) {
+				sb.WriteByte(c)
+			} else {
+				fmt.Fprintf(&sb, "%%%02X", c)
+			}
+		}
+		return sb.String(), nil
+	},
+	"csv": func(v aaronjson.JsonValue) (string, error) {
+		return formatRow(v, "csv", ",", func(s string) string {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		})
+	},
+	"tsv": func(v aaronjson.JsonValue) (string, error) {
+		return formatRow(v, "tsv", "\t", tsvEscaper.Replace)
+	},
+	"sh": func(v aaronjson.JsonValue) (string, error) {
+		quote := func(v aaronjson.JsonValue) (string, error) {
+			switch {
+			case v.IsArray() || v.IsObject():
+				return "", runtimeErrorf("%s can not be escaped for shell", describe(v))
+			case v.IsString():
+				s, _ := v.AsString()
+				return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'", nil
+			default:
+				return jsonText(v), nil
+			}
+		}
+		if !v.IsArray() {
+			return quote(v)
+		}
+		var words []string
+		for _, elem := range arrayElems(v.(*aaronjson.JsonArray)) {
+			word, err := quote(elem)
+			if err != nil {
+				return "", err
+			}
+			words = append(words, word)
+		}
+		return strings.Join(words, " "), nil
+	},
+	"base64": func(v aaronjson.JsonValue) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(toString(v))), nil
+	},
+	"base64d": func(v aaronjson.JsonValue) (string, error) {
+		s := strings.TrimRight(toString(v), "=")
+		decoded, err := base64.RawStdEncoding.DecodeString(s)
+		if err != nil {
+			return "", runtimeErrorf("%s is not valid base64 data", describe(v))
+		}
+		return string(decoded), nil
+	},
+}
+
+var (
+	htmlEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;", "'", "&#39;", `"`, "&quot;")
+	tsvEscaper  = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\r", `\r`, "\n", `\n`)
+)
+
+// isFormat reports whether name is a supported @format.
+func isFormat(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// applyFormat converts v to a string with the named @format.
+func applyFormat(name string, v aaronjson.JsonValue) (string, error) {
+	return formats[name](v)
+}
+
+// isUnreserved reports whether c may appear unescaped in a URI (RFC 3986).
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+// formatRow implements @csv and @tsv: the input must be an array of scalars,
+// whose strings are escaped with quote and joined by sep.
+func formatRow(v aaronjson.JsonValue, name, sep string, quote func(string) string) (string, error) {
+	if !v.IsArray() {
+		return "", runtimeErrorf("%s cannot be %s-formatted, only an array can be", describe(v), name)
+	}
+	var fields []string
+	for _, elem := range arrayElems(v.(*aaronjson.JsonArray)) {
+		switch {
+		case elem.IsNull():
+			fields = append(fields, "")
+		case elem.IsString():
+			s, _ := elem.AsString()
+			fields = append(fields, quote(s))
+		case elem.IsArray() || elem.IsObject():
+			return "", runtimeErrorf("%s is not valid in a %s row", describe(elem), name)
+		default:
+			fields = append(fields, jsonText(elem))
+		}
+	}
+	return strings.Join(fields, sep), nil
+}
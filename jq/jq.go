@@ -0,0 +1,140 @@
+// Package jq implements a subset of the jq language over aaronjson values.
+//
+// A query is compiled once with Compile and can then be run against any
+// number of inputs, each producing zero or more outputs:
+//
+//	q, err := jq.Compile(`.users[] | select(.age >= 18) | {name, city: .address.city}`)
+//	outputs, err := q.Run(doc)
+//
+// The supported language covers paths (.a, .[0], .[1:3], .[], ..), pipes and
+// commas, arithmetic and comparison, and/or/not, the alternative operator //,
+// object and array construction, string interpolation and @formats,
+// if/elif/else, try/catch and the ? operator, variable binding with "as",
+// reduce and foreach, the assignment operators (=, |=, +=, -=, *=, /=, %=,
+// //=) and the commonly used builtins such as map, select, group_by,
+// sort_by, to_entries, with_entries, path, del, test and sub. Function
+// definitions, labels and I/O builtins are not supported.
+//
+// Values keep the library's representations and comparison semantics:
+// equality is aaronjson.Equal, ordering is aaronjson.Compare, and outputs are
+// ordinary JsonValues that can be serialized or passed to Unmarshal. Inputs
+// are never modified; outputs may share unchanged parts with the input.
+// Arithmetic is exact on integers while it fits in an int64 and uses float64
+// otherwise, as jq does.
+package jq
+
+import (
+	"fmt"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// Query is a compiled jq program. A Query is safe for concurrent use.
+type Query struct {
+	expr      string
+	root      node
+	variables []string
+}
+
+// Compile parses a jq program. The program may refer to the named variables
+// (given without the leading '$'), whose values are supplied to Run.
+func Compile(expr string, variables ...string) (*Query, error) {
+	p := &parser{expr: expr, vars: append([]string(nil), variables...)}
+	root, err := p.parseProgram()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{expr: expr, root: root, variables: variables}, nil
+}
+
+// String returns the source of the program.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Run applies the program to input and collects its outputs. values supplies
+// the variables named in Compile, in the same order. If the program fails,
+// Run returns the outputs produced before the error along with the error.
+func (q *Query) Run(input aaronjson.JsonValue, values ...aaronjson.JsonValue) ([]aaronjson.JsonValue, error) {
+	var outputs []aaronjson.JsonValue
+	err := q.Stream(input, func(v aaronjson.JsonValue) error {
+		outputs = append(outputs, v)
+		return nil
+	}, values...)
+	return outputs, err
+}
+
+// Stream applies the program to input, passing each output to emit as soon as
+// it is produced. An error returned by emit stops the program and is
+// returned from Stream.
+func (q *Query) Stream(input aaronjson.JsonValue, emit func(aaronjson.JsonValue) error, values ...aaronjson.JsonValue) error {
+	if len(values) != len(q.variables) {
+		return fmt.Errorf("jq: program has %d variable(s), got %d value(s)", len(q.variables), len(values))
+	}
+	var env *scope
+	for i, name := range q.variables {
+		if values[i] == nil {
+			return fmt.Errorf("jq: nil value for variable $%s", name)
+		}
+		env = env.bind(name, values[i])
+	}
+	if input == nil {
+		input = aaronjson.NewJsonNull()
+	}
+
+	// Errors from emit pass through the program untouched
+	consumer := &consumerError{}
+	err := eval(q.root, env, input, func(v aaronjson.JsonValue) error {
+		if err := emit(finite(v)); err != nil {
+			consumer.err = err
+			return consumer
+		}
+		return nil
+	})
+	if err == consumer {
+		return consumer.err
+	}
+	return err
+}
+
+// CompileError reports a malformed program.
+type CompileError struct {
+	Expr   string // the program being compiled
+	Offset int    // byte offset of the problem in Expr
+	Msg    string // description of the problem
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("jq: compile error: %s at offset %d", e.Msg, e.Offset)
+}
+
+// RuntimeError is an error raised while running a program, either by the
+// error builtin or by an invalid operation such as adding a number to a
+// string. It is the error that try/catch handles.
+type RuntimeError struct {
+	// Value is the value passed to error, or a string describing the failure.
+	Value aaronjson.JsonValue
+}
+
+func (e *RuntimeError) Error() string {
+	if s, err := e.Value.AsString(); err == nil && e.Value.IsString() {
+		return "jq: error: " + s
+	}
+	return fmt.Sprintf("jq: error (not a string): %s", e.Value.String())
+}
+
+// runtimeErrorf returns a RuntimeError with a formatted message.
+func runtimeErrorf(format string, args ...interface{}) error {
+	return &RuntimeError{Value: aaronjson.NewJsonString(fmt.Sprintf(format, args...))}
+}
+
+// consumerError carries an error returned by the consumer of a generator's
+// outputs back through the generator, so that try and the alternative
+// operator do not mistake it for an error of their own body.
+type consumerError struct {
+	err error
+}
+
+func (e *consumerError) Error() string {
+	return e.err.Error()
+}
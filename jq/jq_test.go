@@ -0,0 +1,353 @@
+package jq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+const testDoc = `{
+	"users": [
+		{"name": "ann", "age": 31, "tags": ["admin", "dev"], "address": {"city": "Oslo"}},
+		{"name": "bob", "age": 17, "tags": [], "address": null},
+		{"name": "cy", "age": 45, "tags": ["dev"], "address": {"city": "Rome"}}
+	],
+	"count": 3,
+	"ratio": 0.5
+}`
+
+// mustParse parses a JSON document or fails the test.
+func mustParse(t *testing.T, doc string) aaronjson.JsonValue {
+	t.Helper()
+	v, err := aaronjson.Parse(doc)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", doc, err)
+	}
+	return v
+}
+
+// formatOutputs renders program outputs separated by spaces.
+func formatOutputs(values []aaronjson.JsonValue) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// runCases compiles and runs every program against its input and compares
+// the outputs.
+func runCases(t *testing.T, tests []struct{ expr, input, want string }) {
+	t.Helper()
+	for _, tt := range tests {
+		q, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) error: %v", tt.expr, err)
+			continue
+		}
+		got, err := q.Run(mustParse(t, tt.input))
+		if err != nil {
+			t.Errorf("%q on %s: unexpected error %v", tt.expr, tt.input, err)
+			continue
+		}
+		if s := formatOutputs(got); s != tt.want {
+			t.Errorf("%q on %s = %s, want %s", tt.expr, tt.input, s, tt.want)
+		}
+	}
+}
+
+func TestQueryRun(t *testing.T) {
+	doc := mustParse(t, testDoc)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `.`, want: doc.String()},
+		{expr: `.count`, want: `3`},
+		{expr: `.users[0].name`, want: `"ann"`},
+		{expr: `.users[-1].name`, want: `"cy"`},
+		{expr: `.users[].name`, want: `"ann" "bob" "cy"`},
+		{expr: `.users[1:].[].name`, want: `"bob" "cy"`},
+		{expr: `.users[0]["address"].city`, want: `"Oslo"`},
+		{expr: `.users[1].address.city`, want: `null`},
+		{expr: `.missing.deeper`, want: `null`},
+		{expr: `[.users[] | select(.age >= 18) | .name]`, want: `["ann","cy"]`},
+		{expr: `.users[] | select(.tags | index("dev")) | .name`, want: `"ann" "cy"`},
+		{expr: `.users | map({name, city: .address.city})`, want: `[{"name":"ann","city":"Oslo"},{"name":"bob","city":null},{"name":"cy","city":"Rome"}]`},
+		{expr: `.users | map(.age) | add / length`, want: `31`},
+		{expr: `[.users[].tags[]] | unique`, want: `["admin","dev"]`},
+		{expr: `.users | sort_by(-.age) | map(.name) | join(",")`, want: `"cy,ann,bob"`},
+		{expr: `.users | group_by(.age >= 18) | map(length)`, want: `[1,2]`},
+		{expr: `.users[] | "\(.name) is \(.age)"`, want: `"ann is 31" "bob is 17" "cy is 45"`},
+		{expr: `.count, .ratio`, want: `3 0.5`},
+		{expr: `.count as $n | [range($n)] | map(. * $n)`, want: `[0,3,6]`},
+		{expr: `reduce .users[] as $u ({}; .[$u.name] = $u.age)`, want: `{"ann":31,"bob":17,"cy":45}`},
+		{expr: `[foreach .users[].age as $a (0; . + $a)]`, want: `[31,48,93]`},
+		{expr: `[foreach .users[] as $u (0; . + 1; {($u.name): .})]`, want: `[{"ann":1},{"bob":2},{"cy":3}]`},
+		{expr: `[paths(type == "string")] | length`, want: `8`},
+		{expr: `[.. | numbers]`, want: `[31,17,45,3,0.5]`},
+		{expr: `.users[1].address // "none"`, want: `"none"`},
+		{expr: `if .count > 2 then "many" elif .count > 0 then "few" else "none" end`, want: `"many"`},
+		{expr: `.users[0] | to_entries | map(.key)`, want: `["name","age","tags","address"]`},
+		{expr: `.users[0] | with_entries(select(.value | type == "string"))`, want: `{"name":"ann"}`},
+		{expr: `.users[0] | del(.tags, .address)`, want: `{"name":"ann","age":31}`},
+		{expr: `.users[0].tags | @csv`, want: `"\"admin\",\"dev\""`},
+		{expr: `.users[0] | @json "user: \(.name)"`, want: `"user: \"ann\""`},
+	}
+
+	for _, tt := range tests {
+		q, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) error: %v", tt.expr, err)
+			continue
+		}
+		got, err := q.Run(doc)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if s := formatOutputs(got); s != tt.want {
+			t.Errorf("%q = %s, want %s", tt.expr, s, tt.want)
+		}
+	}
+}
+
+func TestQueryOperators(t *testing.T) {
+	runCases(t, []struct{ expr, input, want string }{
+		{`1 + 2 * 3 - 4 / 2`, `null`, `5`},
+		{`7 % 3, -7 % 3, 5.5 % 2`, `null`, `1 -1 1`},
+		{`1 / 3`, `null`, `0.3333333333333333`},
+		{`9007199254740993 + 0`, `null`, `9007199254740993`},
+		{`9223372036854775807 + 1`, `null`, `9223372036854775808`},
+		{`infinite, -infinite, 1e1000, 1e308 * 10`, `null`, `1.7976931348623157e+308 -1.7976931348623157e+308 1.7976931348623157e+308 1.7976931348623157e+308`},
+		{`[infinite, {"a": -infinite}], (infinite | tojson, tostring, isinfinite), "\(-infinite)"`, `null`, `[1.7976931348623157e+308,{"a":-1.7976931348623157e+308}] "1.7976931348623157e+308" "1.7976931348623157e+308" true "-1.7976931348623157e+308"`},
+		{`.a + .b`, `{"a":[1],"b":[2]}`, `[1,2]`},
+		{`.a + .b`, `{"a":{"x":1},"b":{"y":2}}`, `{"x":1,"y":2}`},
+		{`. + null`, `"s"`, `"s"`},
+		{`[1,2,3,2] - [2]`, `null`, `[1,3]`},
+		{`{"a":{"b":1,"c":2}} * {"a":{"b":3}}`, `null`, `{"a":{"b":3,"c":2}}`},
+		{`"ab" * 3`, `null`, `"ababab"`},
+		{`"ab" * 0, "ab" * nan, "" * 1e300`, `null`, `null null ""`},
+		{`"a,b,c" / ","`, `null`, `["a","b","c"]`},
+		{`[(1,2) + (10,20)]`, `null`, `[11,12,21,22]`},
+		{`"\(1,2)-\(3,4)"`, `null`, `"1-3" "2-3" "1-4" "2-4"`},
+		{`"\(1)", "a\(null)b", @json "x\("q")", {"k\(2)": 1}`, `null`, `"1" "anullb" "x\"q\"" {"k2":1}`},
+		{`{a:(1,2), b:3}`, `null`, `{"a":1,"b":3} {"a":2,"b":3}`},
+		{`1 == 1.0, 1 != 2, "a" < "b", [] > {}, null < false`, `null`, `true true true false true`},
+		{`{"a":1,"b":2} == {"b":2,"a":1}`, `null`, `true`},
+		{`nan == nan, nan < 1`, `null`, `false true`},
+		{`true and (1, null), false or false`, `null`, `true false false`},
+		{`not`, `null`, `true`},
+		{`.a // .b // "x"`, `{"a":false,"b":null}`, `"x"`},
+		{`[.[] // 0]`, `[null]`, `[0]`},
+		{`(.a, .b) // 9`, `{"a":1,"b":2}`, `1 2`},
+		{`-.a`, `{"a":2}`, `-2`},
+		{`.a?`, `[1]`, ``},
+		{`[.[]?]`, `3`, `[]`},
+		{`.a?.b // "none"`, `"x"`, `"none"`},
+		{`try error("boom") catch .`, `null`, `"boom"`},
+		{`try (1, error("x"), 3) catch "caught"`, `null`, `1 "caught"`},
+		{`[.[] | try tonumber catch "bad"]`, `["1","x"]`, `[1,"bad"]`},
+		{`try error({"code":2}) catch .code`, `null`, `2`},
+	})
+}
+
+func TestQueryAssignment(t *testing.T) {
+	runCases(t, []struct{ expr, input, want string }{
+		{`.a = 1`, `{}`, `{"a":1}`},
+		{`.a.b.c = 1`, `null`, `{"a":{"b":{"c":1}}}`},
+		{`.a = .b`, `{"a":1,"b":2}`, `{"a":2,"b":2}`},
+		{`.a = (1, 2)`, `{}`, `{"a":1} {"a":2}`},
+		{`.[2] = 1`, `[]`, `[null,null,1]`},
+		{`.a |= . + 1`, `{"a":1}`, `{"a":2}`},
+		{`.[] |= . * 2`, `[1,2,3]`, `[2,4,6]`},
+		{`.[] |= empty`, `{"a":1,"b":2}`, `{}`},
+		{`.a |= (1, 2)`, `{"a":0}`, `{"a":1}`},
+		{`.a += 1, .a -= 1, .a *= 2, .a /= 2, .a %= 2`, `{"a":3}`, `{"a":4} {"a":2} {"a":6} {"a":1.5} {"a":1}`},
+		{`.a += .b`, `{"a":1,"b":10}`, `{"a":11,"b":10}`},
+		{`.[] //= "default"`, `[null, false, 1]`, `["default","default",1]`},
+		{`.[1:] = ["x"]`, `[1,2,3]`, `[1,"x"]`},
+		{`.[1:] |= map(. * 10)`, `[1,2,3]`, `[1,20,30]`},
+		{`(.a, .b) = 0`, `{"a":1,"b":2,"c":3}`, `{"a":0,"b":0,"c":3}`},
+		{`(.[] | select(. > 1)) |= 0`, `[1,2,3]`, `[1,0,0]`},
+		{`(.. | numbers) |= . + 1`, `{"a":[1,{"b":2}]}`, `{"a":[2,{"b":3}]}`},
+		{`.a[0].b = 1`, `{}`, `{"a":[{"b":1}]}`},
+		{`del(.[1, 2])`, `[1,2,3,4]`, `[1,4]`},
+		{`del(.[] | select(. == 2))`, `[1,2,3,2]`, `[1,3]`},
+		{`to_entries`, `{"a":1}`, `[{"key":"a","value":1}]`},
+	})
+}
+
+func TestQueryDoesNotModifyInput(t *testing.T) {
+	input := mustParse(t, `{"a":[1,2],"b":{"c":1}}`)
+	before := input.String()
+	for _, expr := range []string{`.a[0] = 9`, `.b.c |= . + 1`, `del(.a[0])`, `.a += [3]`, `setpath(["b","d"]; 1)`} {
+		q, err := Compile(expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) error: %v", expr, err)
+		}
+		if _, err := q.Run(input); err != nil {
+			t.Fatalf("%q: unexpected error %v", expr, err)
+		}
+		if input.String() != before {
+			t.Fatalf("%q modified its input: %s", expr, input.String())
+		}
+	}
+}
+
+func TestQueryVariables(t *testing.T) {
+	q, err := Compile(`.[] | select(.age >= $min) | .name`, "min")
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	input := mustParse(t, `[{"name":"a","age":10},{"name":"b","age":20}]`)
+	got, err := q.Run(input, aaronjson.NewJsonInt64(15))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if s := formatOutputs(got); s != `"b"` {
+		t.Errorf("Run = %s, want \"b\"", s)
+	}
+
+	if _, err := q.Run(input); err == nil {
+		t.Error("expected an error for a missing variable value")
+	}
+	if _, err := Compile(`$min`); err == nil {
+		t.Error("expected an error for an undefined variable")
+	}
+	if _, err := Compile(`(1 as $x | $x), $x`); err == nil {
+		t.Error("expected an error for a variable used out of scope")
+	}
+}
+
+func TestQueryStream(t *testing.T) {
+	q, err := Compile(`range(1000000)`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	stop := errors.New("stop")
+	var got []aaronjson.JsonValue
+	err = q.Stream(aaronjson.NewJsonNull(), func(v aaronjson.JsonValue) error {
+		got = append(got, v)
+		if len(got) == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Stream error = %v, want the consumer's error", err)
+	}
+	if s := formatOutputs(got); s != "0 1 2" {
+		t.Errorf("Stream outputs = %s, want 0 1 2", s)
+	}
+
+	// try must not catch errors raised by the consumer
+	q, err = Compile(`try (1, 2) catch "caught"`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	err = q.Stream(aaronjson.NewJsonNull(), func(aaronjson.JsonValue) error { return stop })
+	if err != stop {
+		t.Errorf("Stream through try error = %v, want the consumer's error", err)
+	}
+}
+
+func TestQueryRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		expr  string
+		input string
+		want  string
+	}{
+		{expr: `.a`, input: `[1]`, want: `jq: error: Cannot index array with "a"`},
+		{expr: `.[0]`, input: `{}`, want: `jq: error: Cannot index object with number`},
+		{expr: `.[]`, input: `1`, want: `jq: error: Cannot iterate over number (1)`},
+		{expr: `1 + "a"`, input: `null`, want: `jq: error: number (1) and string ("a") cannot be added`},
+		{expr: `{} - 1`, input: `null`, want: `jq: error: object ({}) and number (1) cannot be subtracted`},
+		{expr: `1 / 0`, input: `null`, want: `jq: error: number (1) and number (0) cannot be divided because the divisor is zero`},
+		{expr: `error("custom")`, input: `null`, want: `jq: error: custom`},
+		{expr: `error({"a":1})`, input: `null`, want: `jq: error (not a string): {"a":1}`},
+		{expr: `true | length`, input: `null`, want: `jq: error: boolean (true) has no length`},
+		{expr: `.[-5] = 1`, input: `[]`, want: `jq: error: Out of bounds negative array index`},
+		{expr: `.[-1e19] = 1`, input: `[]`, want: `jq: error: Out of bounds negative array index`},
+		{expr: `.[100000000] = 1`, input: `[]`, want: `jq: error: Array index too large`},
+		{expr: `.[1e19] = 1`, input: `null`, want: `jq: error: Array index too large`},
+		{expr: `setpath([0, 1e300]; 1)`, input: `[[]]`, want: `jq: error: Array index too large`},
+		{expr: `.[nan] = 1`, input: `[]`, want: `jq: error: Cannot set array index NaN`},
+		{expr: `"a" * 1e10`, input: `null`, want: `jq: error: Repeat string result too long`},
+		{expr: `"abc" * 1e300`, input: `null`, want: `jq: error: Repeat string result too long`},
+		{expr: `. * 40000000`, input: `"ab"`, want: `jq: error: Repeat string result too long`},
+		{expr: `path(1)`, input: `null`, want: `jq: error: Invalid path expression with result 1`},
+	}
+
+	for _, tt := range tests {
+		q, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) error: %v", tt.expr, err)
+			continue
+		}
+		_, err = q.Run(mustParse(t, tt.input))
+		var re *RuntimeError
+		if !errors.As(err, &re) {
+			t.Errorf("%q: error = %v, want a RuntimeError", tt.expr, err)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error = %q, want %q", tt.expr, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		`.a |`,
+		`.[`,
+		`(1`,
+		`{a:}`,
+		`1 == 2 == 3`,
+		`"unterminated`,
+		`"bad \q escape"`,
+		`if . then 1`,
+		`undefined_function`,
+		`map`,
+		`@nosuchformat`,
+		`def f: 1; f`,
+		`reduce . as $x`,
+		`.a ] `,
+	}
+
+	for _, expr := range tests {
+		_, err := Compile(expr)
+		var ce *CompileError
+		if !errors.As(err, &ce) {
+			t.Errorf("Compile(%q) error = %v, want a CompileError", expr, err)
+			continue
+		}
+		if ce.Expr != expr || ce.Offset < 0 || ce.Offset > len(expr) {
+			t.Errorf("Compile(%q) error has Expr %q and Offset %d", expr, ce.Expr, ce.Offset)
+		}
+	}
+}
+
+func TestOutputsRoundTrip(t *testing.T) {
+	q, err := Compile(`{names: [.users[].name], total: (.users | map(.age) | add)}`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	got, err := q.Run(mustParse(t, testDoc))
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Run = %v, %v", got, err)
+	}
+	var result struct {
+		Names []string `json:"names"`
+		Total int      `json:"total"`
+	}
+	if err := got[0].Unmarshal(&result); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if strings.Join(result.Names, ",") != "ann,bob,cy" || result.Total != 93 {
+		t.Errorf("Unmarshal = %+v", result)
+	}
+}
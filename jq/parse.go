@@ -0,0 +1,1063 @@
+package jq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// node is an element of a parsed program.
+type node interface{}
+
+type (
+	identityNode struct{}
+	recurseNode  struct{} // ..
+
+	literalNode struct {
+		value aaronjson.JsonValue
+	}
+	// indexNode is .[index] applied to the outputs of target; .foo is an
+	// index by the string "foo".
+	indexNode struct {
+		target, index node
+	}
+	// sliceNode is .[from:to]; either bound may be nil.
+	sliceNode struct {
+		target, from, to node
+	}
+	iterateNode struct {
+		target node
+	}
+	pipeNode struct {
+		left, right node
+	}
+	commaNode struct {
+		left, right node
+	}
+	negateNode struct {
+		operand node
+	}
+	// binaryNode is an arithmetic or comparison operator.
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	andNode struct {
+		left, right node
+	}
+	orNode struct {
+		left, right node
+	}
+	alternativeNode struct {
+		left, right node
+	}
+	// assignNode is one of =, |=, +=, -=, *=, /=, %= and //=.
+	assignNode struct {
+		op       string
+		lhs, rhs node
+	}
+	// arrayNode collects the outputs of body; body is nil for [].
+	arrayNode struct {
+		body node
+	}
+	objectNode struct {
+		entries []objectEntry
+	}
+	// stringNode is a string with interpolations, optionally formatted by an
+	// @format.
+	stringNode struct {
+		parts  []stringPart
+		format string
+	}
+	// stringPart is literal text, or an interpolation if expr is set.
+	stringPart struct {
+		text string
+		expr node
+	}
+	formatNode struct {
+		name string
+	}
+	varNode struct {
+		name string
+	}
+	// bindNode is source as $name | body.
+	bindNode struct {
+		source node
+		name   string
+		body   node
+	}
+	reduceNode struct {
+		source       node
+		name         string
+		init, update node
+	}
+	// foreachNode is foreach source as $name (init; update; extract);
+	// extract is nil when omitted.
+	foreachNode struct {
+		source                node
+		name                  string
+		init, update, extract node
+	}
+	// ifNode is if cond then then else otherwise end; otherwise is nil when
+	// there is no else branch.
+	ifNode struct {
+		cond, then, otherwise node
+	}
+	// tryNode is try body catch handler; handler is nil for try without
+	// catch and for the ? operator.
+	tryNode struct {
+		body, handler node
+	}
+	callNode struct {
+		name string
+		args []node
+		fn   *builtin
+	}
+)
+
+// objectEntry is a key: value pair of an object construction.
+type objectEntry struct {
+	key, value node
+}
+
+// parser is a recursive descent parser for the jq grammar. Tokens are read
+// directly from the source as the parser needs them, which lets string
+// interpolations parse their embedded expressions in place.
+type parser struct {
+	expr string
+	pos  int
+	vars []string // variables in scope, innermost last
+
+	// Postfix terms by start position, so that looking for "term as $name"
+	// and then backtracking does not parse nested terms again
+	postfix map[int]postfixResult
+}
+
+type postfixResult struct {
+	term node
+	end  int
+	err  error
+}
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokOp               // punctuation and operators
+	tokIdent            // names and keywords
+	tokField            // .name
+	tokVar              // $name
+	tokFormat           // @name
+	tokNumber
+	tokString // the opening quote of a string
+)
+
+// token is a lexical token of the program.
+type token struct {
+	kind tokenKind
+	text string // for fields, variables and formats, the name without its sigil
+	pos  int
+	end  int
+}
+
+// operators lists the operators, longest first so that scanning finds the longest match.
+var operators = []string{
+	"//=", "|=", "+=", "-=", "*=", "/=", "%=", "==", "!=", "<=", ">=", "//", "..",
+	".", "[", "]", "{", "}", "(", ")", "|", ",", ":", ";", "=", "<", ">", "+", "-", "*", "/", "%", "?",
+}
+
+// keywords cannot be used as function names or object keys without quotes.
+var keywords = map[string]bool{
+	"as": true, "def": true, "if": true, "then": true, "elif": true, "else": true, "end": true,
+	"and": true, "or": true, "reduce": true, "foreach": true, "try": true, "catch": true,
+	"label": true, "import": true, "include": true,
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &CompileError{Expr: p.expr, Offset: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// skipSpace skips whitespace and comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.expr) {
+		switch c := p.expr[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.expr) && p.expr[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() token {
+	p.skipSpace()
+	start := p.pos
+	if start >= len(p.expr) {
+		return token{kind: tokEOF, pos: start, end: start}
+	}
+
+	c := p.expr[start]
+	switch {
+	case c == '"':
+		return token{kind: tokString, text: `"`, pos: start, end: start + 1}
+	case c == '.' && start+1 < len(p.expr) && isIdentStart(p.expr[start+1]):
+		end := scanIdent(p.expr, start+1)
+		return token{kind: tokField, text: p.expr[start+1 : end], pos: start, end: end}
+	case (c == '$' || c == '@') && start+1 < len(p.expr) && isIdentStart(p.expr[start+1]):
+		end := scanIdent(p.expr, start+1)
+		kind := tokVar
+		if c == '@' {
+			kind = tokFormat
+		}
+		return token{kind: kind, text: p.expr[start+1 : end], pos: start, end: end}
+	case isIdentStart(c):
+		end := scanIdent(p.expr, start)
+		return token{kind: tokIdent, text: p.expr[start:end], pos: start, end: end}
+	case c >= '0' && c <= '9':
+		end := start
+		for end < len(p.expr) && (isDigit(p.expr[end]) || p.expr[end] == '.') {
+			end++
+		}
+		if end < len(p.expr) && (p.expr[end] == 'e' || p.expr[end] == 'E') {
+			end++
+			if end < len(p.expr) && (p.expr[end] == '+' || p.expr[end] == '-') {
+				end++
+			}
+			for end < len(p.expr) && isDigit(p.expr[end]) {
+				end++
+			}
+		}
+		return token{kind: tokNumber, text: p.expr[start:end], pos: start, end: end}
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(p.expr[start:], op) {
+			return token{kind: tokOp, text: op, pos: start, end: start + len(op)}
+		}
+	}
+	_, size := utf8.DecodeRuneInString(p.expr[start:])
+	return token{kind: tokOp, text: p.expr[start : start+size], pos: start, end: start + size}
+}
+
+// next consumes and returns the next token.
+func (p *parser) next() token {
+	tok := p.peek()
+	p.pos = tok.end
+	return tok
+}
+
+// isOp reports whether the next token is the operator or keyword text.
+func (p *parser) isOp(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokOp || tok.kind == tokIdent) && tok.text == text
+}
+
+// accept consumes the next token if it is the operator or keyword text.
+func (p *parser) accept(text string) bool {
+	if p.isOp(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// expect consumes the operator or keyword text or fails.
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(fmt.Sprintf("'%s'", text))
+	}
+	return nil
+}
+
+// unexpected reports the next token.
+func (p *parser) unexpected(want string) error {
+	tok := p.peek()
+	if tok.kind == tokEOF {
+		return p.errorf(tok.pos, "unexpected end of program, expected %s", want)
+	}
+	return p.errorf(tok.pos, "unexpected %q, expected %s", p.expr[tok.pos:tok.end], want)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// scanIdent returns the end of the identifier starting at pos.
+func scanIdent(s string, pos int) int {
+	for pos < len(s) && (isIdentStart(s[pos]) || isDigit(s[pos])) {
+		pos++
+	}
+	return pos
+}
+
+// parseProgram parses the whole program.
+func (p *parser) parseProgram() (node, error) {
+	if p.peek().kind == tokEOF {
+		// An empty program is the identity
+		return identityNode{}, nil
+	}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("end of program")
+	}
+	return root, nil
+}
+
+// parsePipe parses a pipeline, including "term as $name | body" bindings.
+func (p *parser) parsePipe() (node, error) {
+	if p.isOp("def") {
+		return nil, p.errorf(p.peek().pos, "function definitions are not supported")
+	}
+
+	// Try a binding first, falling back to a plain expression
+	start := p.pos
+	if source, err := p.parsePostfix(); err == nil && p.isOp("as") {
+		p.next()
+		name, err := p.parseVarName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("|"); err != nil {
+			return nil, err
+		}
+		p.vars = append(p.vars, name)
+		body, err := p.parsePipe()
+		p.vars = p.vars[:len(p.vars)-1]
+		if err != nil {
+			return nil, err
+		}
+		return bindNode{source: source, name: name, body: body}, nil
+	}
+	p.pos = start
+
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("|") {
+		return left, nil
+	}
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return pipeNode{left: left, right: right}, nil
+}
+
+// parseVarName parses $name and returns the name.
+func (p *parser) parseVarName() (string, error) {
+	tok := p.peek()
+	if tok.kind != tokVar {
+		if tok.kind == tokOp && (tok.text == "[" || tok.text == "{") {
+			return "", p.errorf(tok.pos, "destructuring patterns are not supported")
+		}
+		return "", p.unexpected("variable")
+	}
+	p.next()
+	return tok.text, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = commaNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAlternative parses the right-associative // operator.
+func (p *parser) parseAlternative() (node, error) {
+	left, err := p.parseAssign()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("//") {
+		return left, nil
+	}
+	right, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	return alternativeNode{left: left, right: right}, nil
+}
+
+// parseAssign parses the non-associative assignment operators.
+func (p *parser) parseAssign() (node, error) {
+	lhs, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return lhs, nil
+	}
+	switch tok.text {
+	case "=", "|=", "+=", "-=", "*=", "/=", "%=", "//=":
+		p.next()
+		rhs, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		return assignNode{op: tok.text, lhs: lhs, rhs: rhs}, nil
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseComparison parses the non-associative comparison operators.
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next.kind == tokOp {
+			switch next.text {
+			case "==", "!=", "<", "<=", ">", ">=":
+				return nil, p.errorf(next.pos, "comparison operators cannot be chained")
+			}
+		}
+		return binaryNode{op: tok.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokOp || (tok.text != "+" && tok.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokOp || (tok.text != "*" && tok.text != "/" && tok.text != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: tok.text, left: left, right: right}
+	}
+}
+
+// parseUnary parses unary minus.
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a term followed by any number of suffixes:
+// .name, ."name", [index], [from:to], [] and ?.
+func (p *parser) parsePostfix() (node, error) {
+	p.skipSpace()
+	start := p.pos
+	if r, ok := p.postfix[start]; ok {
+		p.pos = r.end
+		return r.term, r.err
+	}
+	term, err := p.parsePostfixUncached()
+	if p.postfix == nil {
+		p.postfix = make(map[int]postfixResult)
+	}
+	p.postfix[start] = postfixResult{term: term, end: p.pos, err: err}
+	return term, err
+}
+
+func (p *parser) parsePostfixUncached() (node, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokField:
+			p.next()
+			term = indexNode{target: term, index: literalNode{aaronjson.NewJsonString(tok.text)}}
+		case tok.kind == tokOp && tok.text == "." && p.peekAfter(tok, '"'):
+			p.next()
+			key, err := p.parseString("")
+			if err != nil {
+				return nil, err
+			}
+			term = indexNode{target: term, index: key}
+		case tok.kind == tokOp && tok.text == "." && p.peekAfter(tok, '['):
+			p.next()
+		case tok.kind == tokOp && tok.text == "[":
+			if term, err = p.parseBracketSuffix(term); err != nil {
+				return nil, err
+			}
+		case tok.kind == tokOp && tok.text == "?":
+			p.next()
+			term = tryNode{body: term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// peekAfter reports whether the character right after tok is c.
+func (p *parser) peekAfter(tok token, c byte) bool {
+	return tok.end < len(p.expr) && p.expr[tok.end] == c
+}
+
+// parseBracketSuffix parses [], [index] or [from:to] applied to target.
+func (p *parser) parseBracketSuffix(target node) (node, error) {
+	p.next() // Move past '['
+	if p.accept("]") {
+		return iterateNode{target: target}, nil
+	}
+
+	var from node
+	if !p.isOp(":") {
+		var err error
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if p.accept("]") {
+			return indexNode{target: target, index: from}, nil
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	var to node
+	if !p.isOp("]") {
+		var err error
+		if to, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if from == nil && to == nil {
+		return nil, p.errorf(p.peek().pos, "slice needs a start or an end")
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return sliceNode{target: target, from: from, to: to}, nil
+}
+
+// parseTerm parses a primary expression.
+func (p *parser) parseTerm() (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokEOF:
+		return nil, p.unexpected("expression")
+	case tokField:
+		p.next()
+		return indexNode{target: identityNode{}, index: literalNode{aaronjson.NewJsonString(tok.text)}}, nil
+	case tokVar:
+		p.next()
+		if !p.inScope(tok.text) {
+			return nil, p.errorf(tok.pos, "$%s is not defined", tok.text)
+		}
+		return varNode{name: tok.text}, nil
+	case tokFormat:
+		p.next()
+		if !isFormat(tok.text) {
+			return nil, p.errorf(tok.pos, "unknown format @%s", tok.text)
+		}
+		if p.peek().kind == tokString {
+			return p.parseString(tok.text)
+		}
+		return formatNode{name: tok.text}, nil
+	case tokNumber:
+		p.next()
+		return p.parseNumber(tok)
+	case tokString:
+		return p.parseString("")
+	case tokIdent:
+		return p.parseIdentTerm(tok)
+	}
+
+	switch tok.text {
+	case ".":
+		p.next()
+		if p.peekAfter(tok, '"') {
+			key, err := p.parseString("")
+			if err != nil {
+				return nil, err
+			}
+			return indexNode{target: identityNode{}, index: key}, nil
+		}
+		return identityNode{}, nil
+	case "..":
+		p.next()
+		return recurseNode{}, nil
+	case "(":
+		p.next()
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return body, nil
+	case "[":
+		p.next()
+		if p.accept("]") {
+			return arrayNode{}, nil
+		}
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return arrayNode{body: body}, nil
+	case "{":
+		return p.parseObject()
+	}
+	return nil, p.unexpected("expression")
+}
+
+// inScope reports whether the variable name is bound.
+func (p *parser) inScope(name string) bool {
+	for _, v := range p.vars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNumber converts a number token into a literal, keeping integers exact.
+func (p *parser) parseNumber(tok token) (node, error) {
+	if !strings.ContainsAny(tok.text, ".eE") {
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return literalNode{aaronjson.NewJsonInt64(n)}, nil
+		}
+		// Keep integers beyond int64 exact, as the parser does
+		if n, err := aaronjson.NewJsonNumber(tok.text); err == nil {
+			return literalNode{n}, nil
+		}
+	}
+	f, err := strconv.ParseFloat(tok.text, 64)
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err != strconv.ErrRange {
+		return nil, p.errorf(tok.pos, "invalid number %q", tok.text)
+	}
+	return literalNode{aaronjson.NewJsonFloat(f)}, nil
+}
+
+// parseIdentTerm parses keywords, literals and function calls.
+func (p *parser) parseIdentTerm(tok token) (node, error) {
+	switch tok.text {
+	case "true", "false":
+		p.next()
+		return literalNode{aaronjson.NewJsonBool(tok.text == "true")}, nil
+	case "null":
+		p.next()
+		return literalNode{aaronjson.NewJsonNull()}, nil
+	case "if":
+		p.next()
+		return p.parseIf()
+	case "try":
+		p.next()
+		body, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept("catch") {
+			return tryNode{body: body}, nil
+		}
+		handler, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return tryNode{body: body, handler: handler}, nil
+	case "reduce", "foreach":
+		p.next()
+		return p.parseFold(tok.text)
+	}
+	if keywords[tok.text] {
+		if tok.text == "label" || tok.text == "import" || tok.text == "include" {
+			return nil, p.errorf(tok.pos, "%s is not supported", tok.text)
+		}
+		return nil, p.unexpected("expression")
+	}
+	p.next()
+	return p.parseCall(tok)
+}
+
+// parseIf parses the rest of if cond then a (elif cond then b)* (else c)? end.
+func (p *parser) parseIf() (node, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	result := ifNode{cond: cond, then: then}
+	switch {
+	case p.accept("elif"):
+		if result.otherwise, err = p.parseIf(); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case p.accept("else"):
+		if result.otherwise, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("end"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseFold parses the rest of reduce or foreach: source as $name (init; update[; extract]).
+func (p *parser) parseFold(keyword string) (node, error) {
+	source, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("as"); err != nil {
+		return nil, err
+	}
+	name, err := p.parseVarName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	init, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+
+	p.vars = append(p.vars, name)
+	defer func() { p.vars = p.vars[:len(p.vars)-1] }()
+	update, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	var extract node
+	if keyword == "foreach" && p.accept(";") {
+		if extract, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if keyword == "reduce" {
+		return reduceNode{source: source, name: name, init: init, update: update}, nil
+	}
+	return foreachNode{source: source, name: name, init: init, update: update, extract: extract}, nil
+}
+
+// parseCall parses the arguments of a call to the function named by tok.
+func (p *parser) parseCall(tok token) (node, error) {
+	var args []node
+	if p.accept("(") {
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fn := lookupBuiltin(tok.text, len(args))
+	if fn == nil {
+		return nil, p.errorf(tok.pos, "%s/%d is not defined", tok.text, len(args))
+	}
+	return callNode{name: tok.text, args: args, fn: fn}, nil
+}
+
+// parseObject parses an object construction.
+func (p *parser) parseObject() (node, error) {
+	p.next() // Move past '{'
+	var obj objectNode
+	if p.accept("}") {
+		return obj, nil
+	}
+	for {
+		entry, err := p.parseObjectEntry()
+		if err != nil {
+			return nil, err
+		}
+		obj.entries = append(obj.entries, entry)
+		if p.accept("}") {
+			return obj, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseObjectEntry parses key: value, or a shorthand such as name, $name or "name".
+func (p *parser) parseObjectEntry() (objectEntry, error) {
+	var key, value node
+	tok := p.peek()
+	switch {
+	case tok.kind == tokVar:
+		p.next()
+		if !p.inScope(tok.text) {
+			return objectEntry{}, p.errorf(tok.pos, "$%s is not defined", tok.text)
+		}
+		if !p.isOp(":") {
+			// {$x} is {x: $x}
+			return objectEntry{key: literalNode{aaronjson.NewJsonString(tok.text)}, value: varNode{name: tok.text}}, nil
+		}
+		key = varNode{name: tok.text}
+	case tok.kind == tokIdent:
+		p.next()
+		key = literalNode{aaronjson.NewJsonString(tok.text)}
+	case tok.kind == tokNumber:
+		p.next()
+		key = literalNode{aaronjson.NewJsonString(tok.text)}
+	case tok.kind == tokString || tok.kind == tokFormat:
+		var err error
+		if key, err = p.parseTerm(); err != nil {
+			return objectEntry{}, err
+		}
+	case tok.kind == tokOp && tok.text == "(":
+		p.next()
+		var err error
+		if key, err = p.parsePipe(); err != nil {
+			return objectEntry{}, err
+		}
+		if err := p.expect(")"); err != nil {
+			return objectEntry{}, err
+		}
+	default:
+		return objectEntry{}, p.unexpected("object key")
+	}
+
+	if !p.accept(":") {
+		// {name} is {name: .name}
+		if tok.kind != tokIdent && tok.kind != tokString {
+			return objectEntry{}, p.unexpected("':'")
+		}
+		return objectEntry{key: key, value: indexNode{target: identityNode{}, index: key}}, nil
+	}
+
+	// Values may be pipelines, but a comma ends the entry
+	value, err := p.parseAlternative()
+	if err != nil {
+		return objectEntry{}, err
+	}
+	for p.accept("|") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return objectEntry{}, err
+		}
+		value = pipeNode{left: value, right: right}
+	}
+	return objectEntry{key: key, value: value}, nil
+}
+
+// parseString parses a string literal starting at its opening quote, with
+// \(...) interpolations. format is the @format applied to interpolated
+// values, or "" for none.
+func (p *parser) parseString(format string) (node, error) {
+	p.skipSpace()
+	start := p.pos
+	p.pos++ // Move past '"'
+
+	var parts []stringPart
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() > 0 {
+			parts = append(parts, stringPart{text: sb.String()})
+			sb.Reset()
+		}
+	}
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			flush()
+			if len(parts) == 0 && format == "" {
+				return literalNode{aaronjson.NewJsonString("")}, nil
+			}
+			if len(parts) == 1 && parts[0].expr == nil && format == "" {
+				return literalNode{aaronjson.NewJsonString(parts[0].text)}, nil
+			}
+			return stringNode{parts: parts, format: format}, nil
+		case c == '\\' && p.pos+1 < len(p.expr) && p.expr[p.pos+1] == '(':
+			flush()
+			p.pos += 2
+			part, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			parts = append(parts, stringPart{expr: part})
+		case c == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return nil, p.errorf(start, "unterminated string")
+}
+
+// parseEscape parses a JSON escape sequence inside a string.
+func (p *parser) parseEscape() (rune, error) {
+	start := p.pos
+	if p.pos+1 >= len(p.expr) {
+		return 0, p.errorf(start, "unterminated escape sequence")
+	}
+	c := p.expr[p.pos+1]
+	p.pos += 2
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, ok := p.parseHex4()
+		if !ok {
+			return 0, p.errorf(start, "invalid unicode escape")
+		}
+		if r >= 0xD800 && r <= 0xDBFF && strings.HasPrefix(p.expr[p.pos:], `\u`) {
+			save := p.pos
+			p.pos += 2
+			if low, ok := p.parseHex4(); ok && low >= 0xDC00 && low <= 0xDFFF {
+				return 0x10000 + (r-0xD800)<<10 + (low - 0xDC00), nil
+			}
+			p.pos = save
+		}
+		if r >= 0xD800 && r <= 0xDFFF {
+			return utf8.RuneError, nil
+		}
+		return r, nil
+	default:
+		return 0, p.errorf(start, "invalid escape sequence '\\%c'", c)
+	}
+}
+
+// parseHex4 parses four hex digits.
+func (p *parser) parseHex4() (rune, bool) {
+	if p.pos+4 > len(p.expr) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += 4
+	return rune(n), true
+}
@@ -0,0 +1,687 @@
+package jq
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	aaronjson "github.com/Aaron-wangyr/aaron-json"
+)
+
+// maxExactFloat is the largest integer up to which every float64 is exact.
+const maxExactFloat = 1 << 53
+
+// maxArrayLength bounds how far an assignment may pad an array with nulls,
+// and maxStringLength bounds the length of a repeated string, so that a
+// small program cannot allocate without limit.
+const (
+	maxArrayLength  = 1 << 24
+	maxStringLength = 1 << 26
+)
+
+// truthy reports whether v counts as true: anything but false and null.
+func truthy(v aaronjson.JsonValue) bool {
+	if v.IsNull() {
+		return false
+	}
+	if v.IsBool() {
+		b, _ := v.AsBool()
+		return b
+	}
+	return true
+}
+
+// isNumber reports whether v is a JSON number.
+func isNumber(v aaronjson.JsonValue) bool {
+	return v.IsInt() || v.IsFloat()
+}
+
+// typeName returns the jq name of the type of v.
+func typeName(v aaronjson.JsonValue) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case v.IsBool():
+		return "boolean"
+	case isNumber(v):
+		return "number"
+	case v.IsString():
+		return "string"
+	case v.IsArray():
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// describe returns the type and a shortened encoding of v for error messages.
+func describe(v aaronjson.JsonValue) string {
+	return typeName(v) + " (" + shorten(v) + ")"
+}
+
+// shorten returns the encoding of v, cut short if it is long.
+func shorten(v aaronjson.JsonValue) string {
+	s := v.String()
+	if len(s) > 11 {
+		cut := 10
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
+
+// intValue returns v as an int64 if it is an integer that fits.
+func intValue(v aaronjson.JsonValue) (int64, bool) {
+	if !v.IsInt() {
+		return 0, false
+	}
+	// A JsonInt that holds a float64 is never an exact int64
+	if _, err := v.AsFloat(); err == nil {
+		return 0, false
+	}
+	n, err := v.AsInt64()
+	return n, err == nil
+}
+
+// floatValue returns the numeric value of v as a float64.
+func floatValue(v aaronjson.JsonValue) float64 {
+	if v.IsInt() {
+		if f, err := v.AsFloat(); err == nil {
+			return f
+		}
+		if n, err := v.AsInt64(); err == nil {
+			return float64(n)
+		}
+		if u, err := v.AsUint64(); err == nil {
+			return float64(u)
+		}
+	}
+	f, _ := v.AsFloat()
+	return f
+}
+
+// makeNumber returns f as an integer when it is integral, so that arithmetic
+// results print as integers, and as a JsonFloat otherwise.
+func makeNumber(f float64) aaronjson.JsonValue {
+	if f == math.Trunc(f) && math.Abs(f) <= maxExactFloat {
+		return aaronjson.NewJsonInt64(int64(f))
+	}
+	// Larger integers print in full up to where floats switch to exponents
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		if n, err := aaronjson.NewJsonNumber(new(big.Float).SetFloat64(f).Text('f', 0)); err == nil {
+			return n
+		}
+	}
+	return aaronjson.NewJsonFloat(f)
+}
+
+// finite returns v with every infinite number replaced by the largest finite
+// number of the same sign, which is how jq writes infinities out. Programs
+// work with the infinities themselves; only outputs and the text made from
+// values are clamped. Containers are copied only if they change.
+func finite(v aaronjson.JsonValue) aaronjson.JsonValue {
+	switch {
+	case v.IsArray():
+		elems := arrayElems(v.(*aaronjson.JsonArray))
+		var out *aaronjson.JsonArray
+		for i, elem := range elems {
+			clamped := finite(elem)
+			if clamped != elem && out == nil {
+				out = aaronjson.NewJsonArray()
+				for _, prev := range elems[:i] {
+					_, _ = out.Append(prev)
+				}
+			}
+			if out != nil {
+				_, _ = out.Append(clamped)
+			}
+		}
+		if out != nil {
+			return out
+		}
+	case v.IsObject():
+		obj := v.(*aaronjson.JsonObject)
+		keys := objectKeys(obj)
+		var out *aaronjson.JsonObject
+		for i, key := range keys {
+			member, _ := objectGet(obj, key)
+			clamped := finite(member)
+			if clamped != member && out == nil {
+				out = aaronjson.NewJsonObject()
+				for _, prev := range keys[:i] {
+					prevMember, _ := objectGet(obj, prev)
+					_, _ = out.Set(prev, prevMember)
+				}
+			}
+			if out != nil {
+				_, _ = out.Set(key, clamped)
+			}
+		}
+		if out != nil {
+			return out
+		}
+	case isNumber(v):
+		if f := floatValue(v); math.IsInf(f, 0) {
+			return aaronjson.NewJsonFloat(math.Copysign(math.MaxFloat64, f))
+		}
+	}
+	return v
+}
+
+// jsonText returns the compact JSON text of v as jq writes it.
+func jsonText(v aaronjson.JsonValue) string {
+	return finite(v).String()
+}
+
+// objectGet returns the member key of obj.
+func objectGet(obj *aaronjson.JsonObject, key string) (aaronjson.JsonValue, bool) {
+	v, err := obj.Get(key)
+	return v, err == nil
+}
+
+// objectKeys returns the keys of obj in insertion order.
+func objectKeys(obj *aaronjson.JsonObject) []string {
+	keys, _ := obj.Keys()
+	return keys
+}
+
+// arrayElems returns the elements of arr. The slice must not be modified.
+func arrayElems(arr *aaronjson.JsonArray) []aaronjson.JsonValue {
+	elems, _ := arr.GetSlice()
+	return elems
+}
+
+// newArray returns an array holding elems.
+func newArray(elems []aaronjson.JsonValue) *aaronjson.JsonArray {
+	arr := aaronjson.NewJsonArray()
+	for _, v := range elems {
+		_, _ = arr.Append(v)
+	}
+	return arr
+}
+
+// copyObject returns a shallow copy of obj with the same key order.
+func copyObject(obj *aaronjson.JsonObject) *aaronjson.JsonObject {
+	result := aaronjson.NewJsonObject()
+	for _, key := range objectKeys(obj) {
+		v, _ := objectGet(obj, key)
+		_, _ = result.Set(key, v)
+	}
+	return result
+}
+
+// indexValue implements .[index] on target.
+func indexValue(target, index aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch {
+	case target.IsNull() && (index.IsString() || isNumber(index) || index.IsObject() || index.IsNull()):
+		return aaronjson.NewJsonNull(), nil
+	case target.IsObject() && index.IsString():
+		key, _ := index.AsString()
+		if v, ok := objectGet(target.(*aaronjson.JsonObject), key); ok {
+			return v, nil
+		}
+		return aaronjson.NewJsonNull(), nil
+	case target.IsArray() && isNumber(index):
+		elems := arrayElems(target.(*aaronjson.JsonArray))
+		i := int(math.Floor(floatValue(index)))
+		if i < 0 {
+			i += len(elems)
+		}
+		if i < 0 || i >= len(elems) {
+			return aaronjson.NewJsonNull(), nil
+		}
+		return elems[i], nil
+	case target.IsObject() || target.IsArray() || target.IsString():
+		if index.IsObject() {
+			// A slice path component such as {"start": 1, "end": 3}
+			from, _ := objectGet(index.(*aaronjson.JsonObject), "start")
+			to, _ := objectGet(index.(*aaronjson.JsonObject), "end")
+			if from != nil && to != nil {
+				return sliceValue(target, from, to)
+			}
+		}
+	}
+	if index.IsString() {
+		return nil, runtimeErrorf("Cannot index %s with %s", typeName(target), index.String())
+	}
+	return nil, runtimeErrorf("Cannot index %s with %s", typeName(target), typeName(index))
+}
+
+// sliceBounds resolves slice bounds against a length as jq does: negative
+// bounds count from the end and the result is clamped to [0, length].
+func sliceBounds(from, to aaronjson.JsonValue, length int) (int, int, error) {
+	resolve := func(b aaronjson.JsonValue, def int, round func(float64) float64) (int, error) {
+		if b.IsNull() {
+			return def, nil
+		}
+		if !isNumber(b) {
+			return 0, runtimeErrorf("Start and end indices of an array slice must be numbers")
+		}
+		f := round(floatValue(b))
+		if f < 0 {
+			f += float64(length)
+		}
+		return int(math.Min(math.Max(f, 0), float64(length))), nil
+	}
+	start, err := resolve(from, 0, math.Floor)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := resolve(to, length, math.Ceil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
+
+// sliceValue implements .[from:to] on arrays and strings, counting strings in code points.
+func sliceValue(target, from, to aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch {
+	case target.IsNull():
+		return aaronjson.NewJsonNull(), nil
+	case target.IsArray():
+		elems := arrayElems(target.(*aaronjson.JsonArray))
+		start, end, err := sliceBounds(from, to, len(elems))
+		if err != nil {
+			return nil, err
+		}
+		return newArray(elems[start:end]), nil
+	case target.IsString():
+		s, _ := target.AsString()
+		runes := []rune(s)
+		start, end, err := sliceBounds(from, to, len(runes))
+		if err != nil {
+			return nil, err
+		}
+		return aaronjson.NewJsonString(string(runes[start:end])), nil
+	default:
+		return nil, runtimeErrorf("Cannot index %s with object", typeName(target))
+	}
+}
+
+// iterate calls fn with the key and value of every member of an object, in
+// insertion order, or the index and value of every element of an array.
+func iterate(v aaronjson.JsonValue, fn func(key, value aaronjson.JsonValue) error) error {
+	switch tv := v.(type) {
+	case *aaronjson.JsonObject:
+		for _, key := range objectKeys(tv) {
+			child, _ := objectGet(tv, key)
+			if err := fn(aaronjson.NewJsonString(key), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *aaronjson.JsonArray:
+		for i, child := range arrayElems(tv) {
+			if err := fn(aaronjson.NewJsonInt64(int64(i)), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return runtimeErrorf("Cannot iterate over %s", describe(v))
+	}
+}
+
+// binary applies an arithmetic or comparison operator.
+func binary(op string, l, r aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	switch op {
+	case "==":
+		return aaronjson.NewJsonBool(aaronjson.Equal(l, r)), nil
+	case "!=":
+		return aaronjson.NewJsonBool(!aaronjson.Equal(l, r)), nil
+	case "<":
+		return aaronjson.NewJsonBool(aaronjson.Compare(l, r) < 0), nil
+	case "<=":
+		return aaronjson.NewJsonBool(aaronjson.Compare(l, r) <= 0), nil
+	case ">":
+		return aaronjson.NewJsonBool(aaronjson.Compare(l, r) > 0), nil
+	case ">=":
+		return aaronjson.NewJsonBool(aaronjson.Compare(l, r) >= 0), nil
+	}
+	return arithmetic(op, l, r)
+}
+
+// arithmetic applies +, -, *, / or % with jq's rules for each type.
+func arithmetic(op string, l, r aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if isNumber(l) && isNumber(r) {
+		return numberArithmetic(op, l, r)
+	}
+
+	switch op {
+	case "+":
+		switch {
+		case l.IsNull():
+			return r, nil
+		case r.IsNull():
+			return l, nil
+		case l.IsString() && r.IsString():
+			ls, _ := l.AsString()
+			rs, _ := r.AsString()
+			return aaronjson.NewJsonString(ls + rs), nil
+		case l.IsArray() && r.IsArray():
+			elems := append([]aaronjson.JsonValue(nil), arrayElems(l.(*aaronjson.JsonArray))...)
+			return newArray(append(elems, arrayElems(r.(*aaronjson.JsonArray))...)), nil
+		case l.IsObject() && r.IsObject():
+			result := copyObject(l.(*aaronjson.JsonObject))
+			_ = iterate(r, func(key, v aaronjson.JsonValue) error {
+				k, _ := key.AsString()
+				_, err := result.Set(k, v)
+				return err
+			})
+			return result, nil
+		}
+		return nil, runtimeErrorf("%s and %s cannot be added", describe(l), describe(r))
+	case "-":
+		if l.IsArray() && r.IsArray() {
+			var elems []aaronjson.JsonValue
+			remove := arrayElems(r.(*aaronjson.JsonArray))
+		next:
+			for _, v := range arrayElems(l.(*aaronjson.JsonArray)) {
+				for _, x := range remove {
+					if aaronjson.Compare(v, x) == 0 {
+						continue next
+					}
+				}
+				elems = append(elems, v)
+			}
+			return newArray(elems), nil
+		}
+		return nil, runtimeErrorf("%s and %s cannot be subtracted", describe(l), describe(r))
+	case "*":
+		switch {
+		case l.IsString() && isNumber(r):
+			return repeatString(l, r)
+		case isNumber(l) && r.IsString():
+			return repeatString(r, l)
+		case l.IsObject() && r.IsObject():
+			return deepMerge(l.(*aaronjson.JsonObject), r.(*aaronjson.JsonObject)), nil
+		}
+		return nil, runtimeErrorf("%s and %s cannot be multiplied", describe(l), describe(r))
+	case "/":
+		if l.IsString() && r.IsString() {
+			return splitString(l, r), nil
+		}
+		return nil, runtimeErrorf("%s and %s cannot be divided", describe(l), describe(r))
+	default:
+		return nil, runtimeErrorf("%s and %s cannot be divided", describe(l), describe(r))
+	}
+}
+
+// numberArithmetic applies an arithmetic operator to two numbers, exactly on
+// int64 values while the result fits and in float64 otherwise.
+func numberArithmetic(op string, l, r aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	a, aInt := intValue(l)
+	b, bInt := intValue(r)
+	exact := aInt && bInt
+	x, y := floatValue(l), floatValue(r)
+
+	switch op {
+	case "+":
+		if sum := a + b; exact && (sum > a) == (b > 0) {
+			return aaronjson.NewJsonInt64(sum), nil
+		}
+		return makeNumber(x + y), nil
+	case "-":
+		if diff := a - b; exact && (diff < a) == (b > 0) {
+			return aaronjson.NewJsonInt64(diff), nil
+		}
+		return makeNumber(x - y), nil
+	case "*":
+		if product := a * b; exact && (a == 0 || (product/a == b && !(a == -1 && b == math.MinInt64))) {
+			return aaronjson.NewJsonInt64(product), nil
+		}
+		return makeNumber(x * y), nil
+	case "/":
+		if y == 0 {
+			return nil, runtimeErrorf("%s and %s cannot be divided because the divisor is zero", describe(l), describe(r))
+		}
+		if exact && b != -1 && a%b == 0 {
+			return aaronjson.NewJsonInt64(a / b), nil
+		}
+		return makeNumber(x / y), nil
+	default: // "%"
+		if !aInt {
+			a = int64(x)
+		}
+		if !bInt {
+			b = int64(y)
+		}
+		if b == 0 {
+			return nil, runtimeErrorf("%s and %s cannot be divided because the divisor is zero", describe(l), describe(r))
+		}
+		if b == -1 {
+			return aaronjson.NewJsonInt64(0), nil
+		}
+		return aaronjson.NewJsonInt64(a % b), nil
+	}
+}
+
+// repeatString implements string * number.
+func repeatString(s, n aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	f := floatValue(n)
+	if math.IsNaN(f) || f < 1 {
+		return aaronjson.NewJsonNull(), nil
+	}
+	str, _ := s.AsString()
+	if str == "" {
+		return s, nil
+	}
+	if f > float64(maxStringLength/len(str)) {
+		return nil, runtimeErrorf("Repeat string result too long")
+	}
+	return aaronjson.NewJsonString(strings.Repeat(str, int(f))), nil
+}
+
+// splitString implements string / string.
+func splitString(s, sep aaronjson.JsonValue) aaronjson.JsonValue {
+	str, _ := s.AsString()
+	sepStr, _ := sep.AsString()
+	if str == "" {
+		return aaronjson.NewJsonArray()
+	}
+	var elems []aaronjson.JsonValue
+	for _, part := range strings.Split(str, sepStr) {
+		elems = append(elems, aaronjson.NewJsonString(part))
+	}
+	return newArray(elems)
+}
+
+// deepMerge implements object * object: members of r replace those of l,
+// except that two objects under the same key are merged recursively.
+func deepMerge(l, r *aaronjson.JsonObject) *aaronjson.JsonObject {
+	result := copyObject(l)
+	for _, key := range objectKeys(r) {
+		rv, _ := objectGet(r, key)
+		lv, ok := objectGet(result, key)
+		lo, lok := lv.(*aaronjson.JsonObject)
+		ro, rok := rv.(*aaronjson.JsonObject)
+		if ok && lok && rok {
+			rv = deepMerge(lo, ro)
+		}
+		_, _ = result.Set(key, rv)
+	}
+	return result
+}
+
+// getPath returns the value at path within v, or null if it does not exist.
+func getPath(v aaronjson.JsonValue, path []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	for _, key := range path {
+		if v.IsNull() {
+			return v, nil
+		}
+		var err error
+		if v, err = indexValue(v, key); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// setPath returns a copy of v with the value at path replaced by x, creating
+// objects and arrays as needed. Only the containers along the path are copied.
+func setPath(v aaronjson.JsonValue, path []aaronjson.JsonValue, x aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if len(path) == 0 {
+		return x, nil
+	}
+	key, rest := path[0], path[1:]
+
+	switch {
+	case key.IsString() && (v.IsObject() || v.IsNull()):
+		k, _ := key.AsString()
+		obj := aaronjson.NewJsonObject()
+		child := aaronjson.JsonValue(aaronjson.NewJsonNull())
+		if v.IsObject() {
+			obj = copyObject(v.(*aaronjson.JsonObject))
+			if c, ok := objectGet(obj, k); ok {
+				child = c
+			}
+		}
+		newChild, err := setPath(child, rest, x)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = obj.Set(k, newChild)
+		return obj, nil
+	case isNumber(key) && (v.IsArray() || v.IsNull()):
+		var elems []aaronjson.JsonValue
+		if v.IsArray() {
+			elems = append(elems, arrayElems(v.(*aaronjson.JsonArray))...)
+		}
+		f := math.Floor(floatValue(key))
+		switch {
+		case math.IsNaN(f):
+			return nil, runtimeErrorf("Cannot set array index NaN")
+		case f >= maxArrayLength:
+			return nil, runtimeErrorf("Array index too large")
+		case f < -float64(len(elems)):
+			return nil, runtimeErrorf("Out of bounds negative array index")
+		}
+		i := int(f)
+		if i < 0 {
+			i += len(elems)
+		}
+		for len(elems) <= i {
+			elems = append(elems, aaronjson.NewJsonNull())
+		}
+		newChild, err := setPath(elems[i], rest, x)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = newChild
+		return newArray(elems), nil
+	case key.IsObject() && (v.IsArray() || v.IsNull()):
+		var elems []aaronjson.JsonValue
+		if v.IsArray() {
+			elems = arrayElems(v.(*aaronjson.JsonArray))
+		}
+		from, _ := objectGet(key.(*aaronjson.JsonObject), "start")
+		to, _ := objectGet(key.(*aaronjson.JsonObject), "end")
+		if from == nil || to == nil {
+			break
+		}
+		start, end, err := sliceBounds(from, to, len(elems))
+		if err != nil {
+			return nil, err
+		}
+		newSlice, err := setPath(newArray(elems[start:end]), rest, x)
+		if err != nil {
+			return nil, err
+		}
+		replacement, ok := newSlice.(*aaronjson.JsonArray)
+		if !ok {
+			return nil, runtimeErrorf("A slice of an array can only be assigned another array")
+		}
+		result := append([]aaronjson.JsonValue(nil), elems[:start]...)
+		result = append(result, arrayElems(replacement)...)
+		return newArray(append(result, elems[end:]...)), nil
+	}
+	if key.IsString() {
+		return nil, runtimeErrorf("Cannot index %s with %s", typeName(v), key.String())
+	}
+	return nil, runtimeErrorf("Cannot update field at object index of %s", typeName(v))
+}
+
+// deletePaths returns a copy of v without the values at paths. Paths are
+// deleted from the last to the first so that array indices stay valid.
+func deletePaths(v aaronjson.JsonValue, paths [][]aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	sorted := make([]aaronjson.JsonValue, len(paths))
+	for i, p := range paths {
+		sorted[i] = newArray(p)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return aaronjson.Compare(sorted[i], sorted[j]) > 0
+	})
+	for _, p := range sorted {
+		var err error
+		if v, err = deletePath(v, arrayElems(p.(*aaronjson.JsonArray))); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// deletePath returns a copy of v without the value at path.
+func deletePath(v aaronjson.JsonValue, path []aaronjson.JsonValue) (aaronjson.JsonValue, error) {
+	if len(path) == 0 {
+		return aaronjson.NewJsonNull(), nil
+	}
+	if v.IsNull() {
+		return v, nil
+	}
+	key := path[0]
+	if len(path) > 1 {
+		child, err := indexValue(v, key)
+		if err != nil {
+			return nil, err
+		}
+		if child.IsNull() {
+			return v, nil
+		}
+		newChild, err := deletePath(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		return setPath(v, path[:1], newChild)
+	}
+
+	switch {
+	case v.IsObject() && key.IsString():
+		k, _ := key.AsString()
+		obj := copyObject(v.(*aaronjson.JsonObject))
+		_, _ = obj.Remove(k)
+		return obj, nil
+	case v.IsArray() && isNumber(key):
+		elems := arrayElems(v.(*aaronjson.JsonArray))
+		i := int(math.Floor(floatValue(key)))
+		if i < 0 {
+			i += len(elems)
+		}
+		if i < 0 || i >= len(elems) {
+			return v, nil
+		}
+		result := append([]aaronjson.JsonValue(nil), elems[:i]...)
+		return newArray(append(result, elems[i+1:]...)), nil
+	case v.IsArray() && key.IsObject():
+		elems := arrayElems(v.(*aaronjson.JsonArray))
+		from, _ := objectGet(key.(*aaronjson.JsonObject), "start")
+		to, _ := objectGet(key.(*aaronjson.JsonObject), "end")
+		if from == nil || to == nil {
+			break
+		}
+		start, end, err := sliceBounds(from, to, len(elems))
+		if err != nil {
+			return nil, err
+		}
+		result := append([]aaronjson.JsonValue(nil), elems[:start]...)
+		return newArray(append(result, elems[end:]...)), nil
+	}
+	return nil, runtimeErrorf("Cannot delete field at index %s of %s", key.String(), typeName(v))
+}
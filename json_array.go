@@ -144,58 +144,73 @@ func (array *JsonArray) unmarshalToArray(rv reflect.Value) error {
 	return nil
 }
 
+// String returns the compact JSON encoding of the array.
 func (array *JsonArray) String() string {
-	if len(array.data) == 0 {
-		return "[]"
-	}
+	return string(Compact(array))
+}
 
-	result := "["
-	for i, item := range array.data {
-		if i > 0 {
-			result += ", "
-		}
-		
-		// Format the value based on its type
-		if _, ok := item.(*JsonString); ok {
-			result += fmt.Sprintf("\"%s\"", escapeString(item.String()))
-		} else {
-			result += item.String()
-		}
-	}
-	result += "]"
-	return result
+// MarshalJSON returns the compact JSON encoding of the array.
+func (array *JsonArray) MarshalJSON() ([]byte, error) {
+	return Compact(array), nil
 }
 
 // PrettyString returns a pretty-printed JSON array
 func (array *JsonArray) PrettyString() string {
-	return array.prettyStringWithIndent(0)
+	return prettyString(array, DefaultPrettyOptions())
+}
+
+// PrettyStringWithOptions returns the JSON array formatted according to opts
+func (array *JsonArray) PrettyStringWithOptions(opts PrettyOptions) string {
+	return prettyString(array, opts)
+}
+
+// GetPath returns the value addressed by a path of object keys (strings) and
+// array indices (ints), e.g. GetPath("users", 0, "name"). A failure is reported
+// as a *PathError naming the segment that could not be resolved.
+func (array *JsonArray) GetPath(path ...interface{}) (JsonValue, error) {
+	return Path(path).Get(array)
 }
 
-// prettyStringWithIndent returns a pretty-printed JSON array with specified indentation
-func (array *JsonArray) prettyStringWithIndent(indent int) string {
-	if len(array.data) == 0 {
-		return "[]"
+// SetPath stores value at the location a path of object keys and array indices
+// addresses within the array, creating missing intermediate objects and arrays.
+func (array *JsonArray) SetPath(path Path, value JsonValue) error {
+	return path.Set(array, value, SetPathOptions{})
+}
+
+// SetPathWithOptions is like SetPath but can refuse to create missing
+// containers or to replace values that are not containers.
+func (array *JsonArray) SetPathWithOptions(path Path, value JsonValue, opts SetPathOptions) error {
+	return path.Set(array, value, opts)
+}
+
+// GetPointer returns the value the JSON Pointer (RFC 6901) refers to within the array.
+func (array *JsonArray) GetPointer(pointer string) (JsonValue, error) {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
 	}
+	return ptr.Get(array)
+}
 
-	indentStr := ""
-	for i := 0; i < indent; i++ {
-		indentStr += "  "
+// SetPointer stores value at the location the JSON Pointer refers to within the array.
+// The token "-" appends to an array. The empty pointer cannot be set.
+func (array *JsonArray) SetPointer(pointer string, value JsonValue) error {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return err
 	}
-	nextIndentStr := indentStr + "  "
+	if len(ptr) == 0 {
+		return fmt.Errorf("cannot replace the array itself through an empty pointer")
+	}
+	_, err = ptr.Set(array, value)
+	return err
+}
 
-	result := "[\n"
-	for i, item := range array.data {
-		result += nextIndentStr
-		if prettyItem, ok := item.(interface{ prettyStringWithIndent(int) string }); ok {
-			result += prettyItem.prettyStringWithIndent(indent + 1)
-		} else {
-			result += item.PrettyString()
-		}
-		if i < len(array.data)-1 {
-			result += ","
-		}
-		result += "\n"
+// DeletePointer removes the value the JSON Pointer refers to within the array and returns it.
+func (array *JsonArray) DeletePointer(pointer string) (JsonValue, error) {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
 	}
-	result += indentStr + "]"
-	return result
+	return ptr.Delete(array)
 }
@@ -52,7 +52,7 @@ func TestJsonArrayIndex(t *testing.T) {
 	if err != nil {
 		t.Errorf("Index() error = %v", err)
 	}
-	if val.String() != "first" {
+	if val.String() != `"first"` {
 		t.Errorf("Index(0) = %v, want first", val.String())
 	}
 	
@@ -79,13 +79,13 @@ func TestJsonArraySetByIndex(t *testing.T) {
 	if err != nil {
 		t.Errorf("SetByIndex() error = %v", err)
 	}
-	if val.String() != "updated" {
+	if val.String() != `"updated"` {
 		t.Errorf("SetByIndex() = %v, want updated", val.String())
 	}
 	
 	// Verify the change
 	val, _ = arr.Index(0)
-	if val.String() != "updated" {
+	if val.String() != `"updated"` {
 		t.Errorf("Index(0) after SetByIndex = %v, want updated", val.String())
 	}
 	
@@ -107,7 +107,7 @@ func TestJsonArrayRemoveByIndex(t *testing.T) {
 	if err != nil {
 		t.Errorf("RemoveByIndex() error = %v", err)
 	}
-	if val.String() != "second" {
+	if val.String() != `"second"` {
 		t.Errorf("RemoveByIndex(1) = %v, want second", val.String())
 	}
 	
@@ -119,7 +119,7 @@ func TestJsonArrayRemoveByIndex(t *testing.T) {
 	
 	// Verify remaining elements
 	val, _ = arr.Index(1)
-	if val.String() != "third" {
+	if val.String() != `"third"` {
 		t.Errorf("Index(1) after remove = %v, want third", val.String())
 	}
 	
@@ -169,7 +169,7 @@ func TestJsonArrayGetSlice(t *testing.T) {
 	if len(slice) != 2 {
 		t.Errorf("GetSlice() length = %v, want 2", len(slice))
 	}
-	if slice[0].String() != "item1" {
+	if slice[0].String() != `"item1"` {
 		t.Errorf("GetSlice()[0] = %v, want item1", slice[0].String())
 	}
 }
@@ -251,7 +251,7 @@ func TestJsonArrayString(t *testing.T) {
 	_, _ = arr.Append(NewJsonInt(42))
 	_, _ = arr.Append(NewJsonBool(true))
 	
-	expected := "[\"hello\", 42.000000, true]"
+	expected := "[\"hello\",42,true]"
 	if arr.String() != expected {
 		t.Errorf("Array String() = %v, want %v", arr.String(), expected)
 	}
@@ -43,6 +43,20 @@ func (jb *JsonBool) AsInt() (int, error) {
 	return 0, nil
 }
 
+func (jb *JsonBool) AsInt64() (int64, error) {
+	if jb.data {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (jb *JsonBool) AsUint64() (uint64, error) {
+	if jb.data {
+		return 1, nil
+	}
+	return 0, nil
+}
+
 func (jb *JsonBool) AsFloat() (float64, error) {
 	if jb.data {
 		return 1.0, nil
@@ -58,6 +72,11 @@ func (jb *JsonBool) String() string {
 	return "false"
 }
 
+// MarshalJSON returns the JSON representation of the boolean.
+func (jb *JsonBool) MarshalJSON() ([]byte, error) {
+	return []byte(jb.String()), nil
+}
+
 // PrettyString returns a pretty-printed JSON boolean
 func (jb *JsonBool) PrettyString() string {
 	if jb.data {
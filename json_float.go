@@ -22,9 +22,14 @@ func (jn *JsonFloat) IsFloat() bool {
 	return true
 }
 
-// String returns the string representation of the number.
+// String returns the shortest JSON representation that round-trips the number.
 func (jn *JsonFloat) String() string {
-	return fmt.Sprintf("%f", jn.data)
+	return string(appendFloat(nil, jn.data))
+}
+
+// MarshalJSON returns the shortest JSON representation that round-trips the number.
+func (jn *JsonFloat) MarshalJSON() ([]byte, error) {
+	return appendFloat(nil, jn.data), nil
 }
 
 // AsInt returns the number as an integer.
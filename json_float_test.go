@@ -80,22 +80,22 @@ func TestJsonFloatString(t *testing.T) {
 		{
 			name:  "positive float",
 			value: 3.14,
-			want:  "3.140000",
+			want:  "3.14",
 		},
 		{
 			name:  "negative float",
 			value: -2.5,
-			want:  "-2.500000",
+			want:  "-2.5",
 		},
 		{
 			name:  "zero",
 			value: 0.0,
-			want:  "0.000000",
+			want:  "0.0",
 		},
 		{
 			name:  "integer-like float",
 			value: 42.0,
-			want:  "42.000000",
+			want:  "42.0",
 		},
 	}
 
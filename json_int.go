@@ -2,39 +2,134 @@ package aaronjson
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 )
 
 type JsonInt struct {
 	jsonNode
-	data float64
+	data int64
+	// udata holds values above math.MaxInt64 when unsigned is set.
+	udata    uint64
+	unsigned bool
+	// fdata holds a value given to NewJsonInt that is not an integer in the
+	// int64 or uint64 range when float is set, so that it is kept exactly.
+	fdata float64
+	float bool
 }
 
-// NewJsonInt creates a new JsonInt instance.
+// NewJsonInt creates a new JsonInt instance. Integral values are stored
+// exactly; any other value (a fraction, NaN, an infinity or an integer
+// beyond the uint64 range) is kept as given and formatted like a JsonFloat.
+// Use NewJsonInt64 or NewJsonUint64 for integers that cannot be represented
+// exactly as a float64.
 func NewJsonInt(value float64) *JsonInt {
+	switch {
+	case value != math.Trunc(value):
+		// A fraction or NaN
+	case value >= -(1<<63) && value < 1<<63:
+		return NewJsonInt64(int64(value))
+	case value >= 0 && value < 1<<64:
+		return NewJsonUint64(uint64(value))
+	}
+	return &JsonInt{
+		jsonNode: jsonNode{},
+		fdata:    value,
+		float:    true,
+	}
+}
+
+// NewJsonInt64 creates a new JsonInt instance holding an exact int64 value.
+func NewJsonInt64(value int64) *JsonInt {
 	return &JsonInt{
 		jsonNode: jsonNode{},
 		data:     value,
 	}
 }
 
+// NewJsonUint64 creates a new JsonInt instance holding an exact uint64 value.
+func NewJsonUint64(value uint64) *JsonInt {
+	if value <= math.MaxInt64 {
+		return NewJsonInt64(int64(value))
+	}
+	return &JsonInt{
+		jsonNode: jsonNode{},
+		udata:    value,
+		unsigned: true,
+	}
+}
+
 func (jn *JsonInt) IsInt() bool {
 	return true
 }
 
 // String returns the string representation of the number.
 func (jn *JsonInt) String() string {
-	return fmt.Sprintf("%f", jn.data)
+	if jn.float {
+		return string(appendFloat(nil, jn.fdata))
+	}
+	if jn.unsigned {
+		return strconv.FormatUint(jn.udata, 10)
+	}
+	return strconv.FormatInt(jn.data, 10)
+}
+
+// MarshalJSON returns the exact decimal representation of the number.
+func (jn *JsonInt) MarshalJSON() ([]byte, error) {
+	return Compact(jn), nil
 }
 
 // AsInt returns the number as an integer.
+// A fraction given to NewJsonInt is truncated toward zero.
 func (jn *JsonInt) AsInt() (int, error) {
-	return int(jn.data), nil
+	value, err := jn.AsInt64()
+	if err != nil || int64(int(value)) != value {
+		return 0, fmt.Errorf("int %s overflows int", jn.String())
+	}
+	return int(value), nil
+}
+
+// AsInt64 returns the number as an int64.
+// A fraction given to NewJsonInt is truncated toward zero.
+func (jn *JsonInt) AsInt64() (int64, error) {
+	if jn.float {
+		if !(jn.fdata >= -(1<<63) && jn.fdata < 1<<63) {
+			return 0, fmt.Errorf("int %s overflows int64", jn.String())
+		}
+		return int64(jn.fdata), nil
+	}
+	if jn.unsigned {
+		return 0, fmt.Errorf("int %s overflows int64", jn.String())
+	}
+	return jn.data, nil
 }
 
-// AsInt returns the number as a float64.
+// AsUint64 returns the number as a uint64.
+// A fraction given to NewJsonInt is truncated toward zero.
+func (jn *JsonInt) AsUint64() (uint64, error) {
+	if jn.float {
+		if !(jn.fdata > -1 && jn.fdata < 1<<64) {
+			return 0, fmt.Errorf("cannot convert int %s to uint64", jn.String())
+		}
+		return uint64(jn.fdata), nil
+	}
+	if jn.unsigned {
+		return jn.udata, nil
+	}
+	if jn.data < 0 {
+		return 0, fmt.Errorf("cannot convert negative int %s to uint64", jn.String())
+	}
+	return uint64(jn.data), nil
+}
+
+// AsFloat returns a value that NewJsonInt kept as a float64.
+// Integers report an error.
 func (jn *JsonInt) AsFloat() (float64, error) {
-	return 0, fmt.Errorf("cannot convert int %f to float64", jn.data)
+	if jn.float {
+		return jn.fdata, nil
+	}
+	return 0, fmt.Errorf("cannot convert int %s to float64", jn.String())
 }
 
 // Unmarshal implementation for JsonInt
@@ -55,16 +150,43 @@ func (jn *JsonInt) Unmarshal(v interface{}) error {
 
 	switch rv.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		rv.SetInt(int64(jn.data))
+		value, err := jn.AsInt64()
+		if err != nil || rv.OverflowInt(value) {
+			return fmt.Errorf("int %s overflows %v", jn.String(), rv.Type())
+		}
+		rv.SetInt(value)
 		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		rv.SetUint(uint64(jn.data))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value, err := jn.AsUint64()
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal int %s into %v", jn.String(), rv.Type())
+		}
+		if rv.OverflowUint(value) {
+			return fmt.Errorf("int %s overflows %v", jn.String(), rv.Type())
+		}
+		rv.SetUint(value)
 		return nil
 	case reflect.Float32, reflect.Float64:
-		rv.SetFloat(jn.data)
+		switch {
+		case jn.float:
+			rv.SetFloat(jn.fdata)
+		case jn.unsigned:
+			rv.SetFloat(float64(jn.udata))
+		default:
+			rv.SetFloat(float64(jn.data))
+		}
 		return nil
 	case reflect.Interface:
-		rv.Set(reflect.ValueOf(int(jn.data)))
+		switch {
+		case jn.float:
+			rv.Set(reflect.ValueOf(jn.fdata))
+		case jn.unsigned:
+			rv.Set(reflect.ValueOf(jn.udata))
+		case int64(int(jn.data)) == jn.data:
+			rv.Set(reflect.ValueOf(int(jn.data)))
+		default:
+			rv.Set(reflect.ValueOf(jn.data))
+		}
 		return nil
 	default:
 		return fmt.Errorf("cannot unmarshal int into %v", rv.Type())
@@ -73,9 +195,5 @@ func (jn *JsonInt) Unmarshal(v interface{}) error {
 
 // PrettyString returns a pretty-printed JSON number
 func (jn *JsonInt) PrettyString() string {
-	// Check if it's an integer value
-	if jn.data == float64(int64(jn.data)) {
-		return fmt.Sprintf("%.0f", jn.data)
-	}
-	return fmt.Sprintf("%g", jn.data)
+	return jn.String()
 }
@@ -1,6 +1,7 @@
 package aaronjson
 
 import (
+	"math"
 	"testing"
 )
 
@@ -75,22 +76,22 @@ func TestJsonIntString(t *testing.T) {
 		{
 			name:  "positive integer",
 			value: 42,
-			want:  "42.000000",
+			want:  "42",
 		},
 		{
 			name:  "negative integer",
 			value: -10,
-			want:  "-10.000000",
+			want:  "-10",
 		},
 		{
 			name:  "zero",
 			value: 0,
-			want:  "0.000000",
+			want:  "0",
 		},
 		{
 			name:  "float value",
 			value: 3.14,
-			want:  "3.140000",
+			want:  "3.14",
 		},
 	}
 
@@ -183,3 +184,153 @@ func TestJsonIntUnmarshal(t *testing.T) {
 	// Note: The current implementation of JsonInt.Unmarshal() returns nil without doing anything
 	// This might be incomplete implementation
 }
+
+func TestJsonInt64Precision(t *testing.T) {
+	tests := []struct {
+		name    string
+		jsonStr string
+		want    string
+	}{
+		{
+			name:    "max int64",
+			jsonStr: `9223372036854775807`,
+			want:    "9223372036854775807",
+		},
+		{
+			name:    "min int64",
+			jsonStr: `-9223372036854775808`,
+			want:    "-9223372036854775808",
+		},
+		{
+			name:    "above 2^53",
+			jsonStr: `9007199254740993`,
+			want:    "9007199254740993",
+		},
+		{
+			name:    "max uint64",
+			jsonStr: `18446744073709551615`,
+			want:    "18446744073709551615",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.jsonStr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !got.IsInt() {
+				t.Fatalf("Parse(%s) should return an int", tt.jsonStr)
+			}
+			if got.String() != tt.want {
+				t.Errorf("String() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJsonIntAsInt64AndUint64(t *testing.T) {
+	snowflake := NewJsonInt64(1234567890123456789)
+	i, err := snowflake.AsInt64()
+	if err != nil || i != 1234567890123456789 {
+		t.Errorf("AsInt64() = %v, %v, want 1234567890123456789", i, err)
+	}
+	u, err := snowflake.AsUint64()
+	if err != nil || u != 1234567890123456789 {
+		t.Errorf("AsUint64() = %v, %v, want 1234567890123456789", u, err)
+	}
+
+	hash := NewJsonUint64(18446744073709551615)
+	if _, err := hash.AsInt64(); err == nil {
+		t.Error("AsInt64() should return error for value above max int64")
+	}
+	if _, err := hash.AsInt(); err == nil {
+		t.Error("AsInt() should return error for value above max int64")
+	}
+	u, err = hash.AsUint64()
+	if err != nil || u != 18446744073709551615 {
+		t.Errorf("AsUint64() = %v, %v, want 18446744073709551615", u, err)
+	}
+
+	if _, err := NewJsonInt64(-1).AsUint64(); err == nil {
+		t.Error("AsUint64() should return error for negative value")
+	}
+}
+
+func TestJsonIntUnmarshalSized(t *testing.T) {
+	var i8 int8
+	if err := NewJsonInt64(127).Unmarshal(&i8); err != nil || i8 != 127 {
+		t.Errorf("Unmarshal() into int8 = %v, %v, want 127", i8, err)
+	}
+	if err := NewJsonInt64(128).Unmarshal(&i8); err == nil {
+		t.Error("Unmarshal() should return overflow error for int8")
+	}
+
+	var u8 uint8
+	if err := NewJsonInt64(-1).Unmarshal(&u8); err == nil {
+		t.Error("Unmarshal() should return error for negative value into uint8")
+	}
+	if err := NewJsonInt64(256).Unmarshal(&u8); err == nil {
+		t.Error("Unmarshal() should return overflow error for uint8")
+	}
+
+	var i64 int64
+	if err := NewJsonUint64(18446744073709551615).Unmarshal(&i64); err == nil {
+		t.Error("Unmarshal() should return overflow error for int64")
+	}
+
+	var u64 uint64
+	if err := NewJsonUint64(18446744073709551615).Unmarshal(&u64); err != nil || u64 != 18446744073709551615 {
+		t.Errorf("Unmarshal() into uint64 = %v, %v, want 18446744073709551615", u64, err)
+	}
+
+	var iface interface{}
+	if err := NewJsonUint64(18446744073709551615).Unmarshal(&iface); err != nil {
+		t.Errorf("Unmarshal() into interface{} error = %v", err)
+	}
+	if iface != uint64(18446744073709551615) {
+		t.Errorf("Unmarshal() into interface{} = %v (%T), want uint64", iface, iface)
+	}
+}
+
+func TestNewJsonIntRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		want      string
+		fitsInt64 bool
+	}{
+		{name: "integral", value: 1e15, want: "1000000000000000", fitsInt64: true},
+		{name: "min int64", value: -(1 << 63), want: "-9223372036854775808", fitsInt64: true},
+		{name: "above max int64", value: 1 << 63, want: "9223372036854775808"},
+		{name: "beyond uint64", value: 1e20 * 1e5, want: "1e+25"},
+		{name: "negative beyond int64", value: -1e19, want: "-10000000000000000000.0"},
+		{name: "fraction", value: -2.5, want: "-2.5", fitsInt64: true},
+		{name: "infinity", value: math.Inf(1), want: "null"},
+		{name: "nan", value: math.NaN(), want: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num := NewJsonInt(tt.value)
+			if got := num.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+			if _, err := num.AsInt64(); (err == nil) != tt.fitsInt64 {
+				t.Errorf("AsInt64() error = %v, want ok %v", err, tt.fitsInt64)
+			}
+		})
+	}
+
+	var i int64
+	if err := NewJsonInt(math.Inf(-1)).Unmarshal(&i); err == nil {
+		t.Error("Unmarshal() of -Inf into int64 should return an overflow error")
+	}
+	var f float64
+	if err := NewJsonInt(3.14).Unmarshal(&f); err != nil || f != 3.14 {
+		t.Errorf("Unmarshal() into float64 = %v, %v, want 3.14", f, err)
+	}
+	if !Equal(NewJsonInt(2.5), NewJsonFloat(2.5)) || Equal(NewJsonInt(2.5), NewJsonInt(2)) {
+		t.Error("Equal() should compare a fractional JsonInt by value")
+	}
+}
@@ -8,6 +8,8 @@ type JsonValue interface {
 
 	AsString() (string, error)
 	AsInt() (int, error)
+	AsInt64() (int64, error)
+	AsUint64() (uint64, error)
 	AsFloat() (float64, error)
 	AsBool() (bool, error)
 	AsObject() (*JsonObject, error)
@@ -23,6 +25,8 @@ type JsonValue interface {
 
 	Unmarshal(v interface{}) error
 
+	MarshalJSON() ([]byte, error)
+
 	String() string
 	PrettyString() string
 }
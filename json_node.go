@@ -27,6 +27,10 @@ func (n *jsonNode) PrettyString() string {
 	return n.String()
 }
 
+func (n *jsonNode) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("cannot marshal %s to JSON", n.String())
+}
+
 func (n *jsonNode) AsString() (string, error) {
 	return "", fmt.Errorf("cannot convert %s to string", n.String())
 }
@@ -35,6 +39,14 @@ func (n *jsonNode) AsInt() (int, error) {
 	return 0, fmt.Errorf("cannot convert %s to int", n.String())
 }
 
+func (n *jsonNode) AsInt64() (int64, error) {
+	return 0, fmt.Errorf("cannot convert %s to int64", n.String())
+}
+
+func (n *jsonNode) AsUint64() (uint64, error) {
+	return 0, fmt.Errorf("cannot convert %s to uint64", n.String())
+}
+
 func (n *jsonNode) AsFloat() (float64, error) {
 	return 0, fmt.Errorf("cannot convert %s to float", n.String())
 }
@@ -27,6 +27,11 @@ func (jn *JsonNull) String() string {
 	return "null"
 }
 
+// MarshalJSON returns the JSON representation of null.
+func (jn *JsonNull) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
 // PrettyString returns a pretty-printed JSON null
 func (jn *JsonNull) PrettyString() string {
 	return "null"
@@ -0,0 +1,275 @@
+package aaronjson
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// JsonNumber holds a JSON number as its original lexeme, so that no precision
+// is lost by converting it to float64. Parsing with ParseOptions.UseNumber
+// produces JsonNumber values instead of JsonInt and JsonFloat.
+type JsonNumber struct {
+	jsonNode
+	data string
+}
+
+// NewJsonNumber creates a new JsonNumber instance from a numeric lexeme.
+// It returns an error if value is not a valid JSON number.
+func NewJsonNumber(value string) (*JsonNumber, error) {
+	data := []byte(value)
+	end, _, err := scanNumber(data, 0)
+	if err != nil {
+		return nil, withErrorContext(err, data)
+	}
+	if end != len(value) {
+		return nil, newSyntaxError(end, "invalid number '%s'", value).withContext(data)
+	}
+	return &JsonNumber{
+		jsonNode: jsonNode{},
+		data:     value,
+	}, nil
+}
+
+// isIntegral reports whether the lexeme has neither a fraction nor an exponent.
+func (jn *JsonNumber) isIntegral() bool {
+	return !strings.ContainsAny(jn.data, ".eE")
+}
+
+// IsInt reports whether the number was written as an integer.
+func (jn *JsonNumber) IsInt() bool {
+	return jn.isIntegral()
+}
+
+// IsFloat reports whether the number was written with a fraction or exponent.
+func (jn *JsonNumber) IsFloat() bool {
+	return !jn.isIntegral()
+}
+
+// String returns the original lexeme of the number.
+func (jn *JsonNumber) String() string {
+	return jn.data
+}
+
+// PrettyString returns the original lexeme of the number.
+func (jn *JsonNumber) PrettyString() string {
+	return jn.data
+}
+
+// MarshalJSON returns the original lexeme of the number.
+func (jn *JsonNumber) MarshalJSON() ([]byte, error) {
+	return []byte(jn.data), nil
+}
+
+// AsInt returns the number as an integer.
+func (jn *JsonNumber) AsInt() (int, error) {
+	i, err := jn.AsInt64()
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(i)) != i {
+		return 0, fmt.Errorf("number %s overflows int", jn.data)
+	}
+	return int(i), nil
+}
+
+// AsInt64 returns the number as an int64.
+func (jn *JsonNumber) AsInt64() (int64, error) {
+	if jn.isIntegral() {
+		if i, err := strconv.ParseInt(jn.data, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	bi, err := jn.AsBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !bi.IsInt64() {
+		return 0, fmt.Errorf("number %s overflows int64", jn.data)
+	}
+	return bi.Int64(), nil
+}
+
+// AsUint64 returns the number as a uint64.
+func (jn *JsonNumber) AsUint64() (uint64, error) {
+	if jn.isIntegral() {
+		if u, err := strconv.ParseUint(jn.data, 10, 64); err == nil {
+			return u, nil
+		}
+	}
+	bi, err := jn.AsBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !bi.IsUint64() {
+		return 0, fmt.Errorf("number %s overflows uint64", jn.data)
+	}
+	return bi.Uint64(), nil
+}
+
+// AsFloat returns the number as a float64.
+func (jn *JsonNumber) AsFloat() (float64, error) {
+	f, err := strconv.ParseFloat(jn.data, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert number %s to float64: %v", jn.data, err)
+	}
+	return f, nil
+}
+
+// AsBigInt returns the number as a *big.Int.
+// Numbers written with a fraction or exponent are accepted if their value is integral.
+func (jn *JsonNumber) AsBigInt() (*big.Int, error) {
+	if jn.isIntegral() {
+		bi, ok := new(big.Int).SetString(jn.data, 10)
+		if !ok {
+			return nil, fmt.Errorf("cannot convert number %s to big.Int", jn.data)
+		}
+		return bi, nil
+	}
+	r, err := jn.AsBigRat()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("number %s is not an integer", jn.data)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// AsBigFloat returns the number as a *big.Float.
+// The precision grows with the length of the lexeme so that every written digit is kept.
+func (jn *JsonNumber) AsBigFloat() (*big.Float, error) {
+	prec := uint(len(jn.data))*4 + 64
+	f, _, err := big.ParseFloat(jn.data, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert number %s to big.Float: %v", jn.data, err)
+	}
+	return f, nil
+}
+
+// AsBigRat returns the exact value of the number as a *big.Rat.
+func (jn *JsonNumber) AsBigRat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(jn.data)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert number %s to big.Rat", jn.data)
+	}
+	return r, nil
+}
+
+// Unmarshal implementation for JsonNumber.
+// Besides the numeric kinds it supports big.Int, big.Float and big.Rat targets
+// (by value or pointer) and string kinds, which receive the original lexeme.
+func (jn *JsonNumber) Unmarshal(v interface{}) error {
+	if v == nil {
+		return fmt.Errorf("cannot unmarshal into nil interface")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("unmarshal target must be a pointer")
+	}
+
+	rv = rv.Elem()
+	if !rv.CanSet() {
+		return fmt.Errorf("unmarshal target cannot be set")
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		switch rv.Type().Elem() {
+		case bigIntType, bigFloatType, bigRatType:
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+	}
+
+	switch rv.Type() {
+	case bigIntType:
+		bi, err := jn.AsBigInt()
+		if err != nil {
+			return err
+		}
+		rv.Addr().Interface().(*big.Int).Set(bi)
+		return nil
+	case bigFloatType:
+		f, err := jn.AsBigFloat()
+		if err != nil {
+			return err
+		}
+		target := rv.Addr().Interface().(*big.Float)
+		target.SetPrec(f.Prec()).Set(f)
+		return nil
+	case bigRatType:
+		r, err := jn.AsBigRat()
+		if err != nil {
+			return err
+		}
+		rv.Addr().Interface().(*big.Rat).Set(r)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := jn.AsInt64()
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("number %s overflows %v", jn.data, rv.Type())
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := jn.AsUint64()
+		if err != nil {
+			return err
+		}
+		if rv.OverflowUint(u) {
+			return fmt.Errorf("number %s overflows %v", jn.data, rv.Type())
+		}
+		rv.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(jn.data, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal number %s into %v: %v", jn.data, rv.Type(), err)
+		}
+		rv.SetFloat(f)
+		return nil
+	case reflect.String:
+		rv.SetString(jn.data)
+		return nil
+	case reflect.Interface:
+		// Integers that fit become int, larger integers *big.Int and
+		// everything else an exact *big.Rat.
+		if jn.isIntegral() {
+			if i, err := jn.AsInt(); err == nil {
+				rv.Set(reflect.ValueOf(i))
+				return nil
+			}
+			bi, err := jn.AsBigInt()
+			if err != nil {
+				return err
+			}
+			rv.Set(reflect.ValueOf(bi))
+			return nil
+		}
+		r, err := jn.AsBigRat()
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(r))
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal number into %v", rv.Type())
+	}
+}
@@ -0,0 +1,184 @@
+package aaronjson
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestNewJsonNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name:  "integer",
+			value: "123",
+		},
+		{
+			name:  "decimal",
+			value: "-0.000001",
+		},
+		{
+			name:  "exponent",
+			value: "1.5E+300",
+		},
+		{
+			name:    "empty",
+			value:   "",
+			wantErr: true,
+		},
+		{
+			name:    "trailing characters",
+			value:   "12abc",
+			wantErr: true,
+		},
+		{
+			name:    "leading plus",
+			value:   "+1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num, err := NewJsonNumber(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewJsonNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && num.String() != tt.value {
+				t.Errorf("String() = %v, want %v", num.String(), tt.value)
+			}
+		})
+	}
+}
+
+func TestParseUseNumber(t *testing.T) {
+	input := `{"amount": 12345678901234567890.123456789, "id": 123456789012345678901234567890, "small": 7}`
+	parsed, err := ParseWithOptions(input, ParseOptions{UseNumber: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	amount, _ := parsed.Get("amount")
+	if _, ok := amount.(*JsonNumber); !ok {
+		t.Fatalf("amount should be a JsonNumber, got %T", amount)
+	}
+	if amount.String() != "12345678901234567890.123456789" {
+		t.Errorf("amount = %v, want original lexeme", amount.String())
+	}
+	if !amount.IsFloat() || amount.IsInt() {
+		t.Error("amount should report IsFloat")
+	}
+
+	id, _ := parsed.Get("id")
+	if !id.IsInt() {
+		t.Error("id should report IsInt")
+	}
+	bi, err := id.(*JsonNumber).AsBigInt()
+	if err != nil {
+		t.Fatalf("AsBigInt() error = %v", err)
+	}
+	if bi.String() != "123456789012345678901234567890" {
+		t.Errorf("AsBigInt() = %v", bi.String())
+	}
+	if _, err := id.AsInt64(); err == nil {
+		t.Error("AsInt64() should return overflow error")
+	}
+
+	small, _ := parsed.Get("small")
+	if i, err := small.AsInt(); err != nil || i != 7 {
+		t.Errorf("AsInt() = %v, %v, want 7", i, err)
+	}
+}
+
+func TestJsonNumberBigAccessors(t *testing.T) {
+	num, _ := NewJsonNumber("0.1")
+
+	r, err := num.AsBigRat()
+	if err != nil {
+		t.Fatalf("AsBigRat() error = %v", err)
+	}
+	if r.Cmp(big.NewRat(1, 10)) != 0 {
+		t.Errorf("AsBigRat() = %v, want 1/10", r)
+	}
+
+	f, err := num.AsBigFloat()
+	if err != nil {
+		t.Fatalf("AsBigFloat() error = %v", err)
+	}
+	if f.Text('f', 1) != "0.1" {
+		t.Errorf("AsBigFloat() = %v, want 0.1", f.Text('f', 1))
+	}
+
+	if _, err := num.AsBigInt(); err == nil {
+		t.Error("AsBigInt() should return error for non-integral number")
+	}
+
+	exp, _ := NewJsonNumber("1.5e3")
+	if i, err := exp.AsInt64(); err != nil || i != 1500 {
+		t.Errorf("AsInt64() = %v, %v, want 1500", i, err)
+	}
+}
+
+func TestJsonNumberUnmarshal(t *testing.T) {
+	type Decimal string
+	type Payment struct {
+		Amount   Decimal    `json:"amount"`
+		Total    *big.Float `json:"total"`
+		Balance  big.Int    `json:"balance"`
+		Ratio    *big.Rat   `json:"ratio"`
+		Quantity int8       `json:"quantity"`
+	}
+
+	input := `{"amount": 19.99, "total": 1e-400, "balance": 99999999999999999999999, "ratio": 0.25, "quantity": 3}`
+	parsed, err := ParseWithOptions(input, ParseOptions{UseNumber: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	var p Payment
+	if err := parsed.Unmarshal(&p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if p.Amount != "19.99" {
+		t.Errorf("Amount = %v, want 19.99", p.Amount)
+	}
+	if p.Total == nil || p.Total.Sign() <= 0 {
+		t.Errorf("Total = %v, want tiny positive value", p.Total)
+	}
+	if p.Balance.String() != "99999999999999999999999" {
+		t.Errorf("Balance = %v", p.Balance.String())
+	}
+	if p.Ratio == nil || p.Ratio.Cmp(big.NewRat(1, 4)) != 0 {
+		t.Errorf("Ratio = %v, want 1/4", p.Ratio)
+	}
+	if p.Quantity != 3 {
+		t.Errorf("Quantity = %v, want 3", p.Quantity)
+	}
+
+	tooBig, _ := NewJsonNumber("300")
+	var i8 int8
+	if err := tooBig.Unmarshal(&i8); err == nil {
+		t.Error("Unmarshal() should return overflow error for int8")
+	}
+}
+
+func TestMarshalBigNumbers(t *testing.T) {
+	bi, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	got, err := Marshal(bi)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got.String() != "123456789012345678901234567890" {
+		t.Errorf("Marshal(*big.Int) = %v", got.String())
+	}
+
+	got, err = Marshal(big.NewFloat(2.5))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got.String() != "2.5" {
+		t.Errorf("Marshal(*big.Float) = %v, want 2.5", got.String())
+	}
+}
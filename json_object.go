@@ -3,21 +3,21 @@ package aaronjson
 import (
 	"fmt"
 	"reflect"
-	"sort"
 )
 
+// JsonObject is a JSON object that remembers the order in which its keys
+// were first set, so parsed documents serialize in their original key order.
 type JsonObject struct {
 	jsonNode
-	data       map[string]JsonValue
-	sortedkeys []string
+	data map[string]JsonValue
+	keys []string // insertion order
 }
 
 func NewJsonObject() *JsonObject {
-	keys := make([]string, 0)
 	return &JsonObject{
-		jsonNode:   jsonNode{},
-		data:       make(map[string]JsonValue),
-		sortedkeys: keys,
+		jsonNode: jsonNode{},
+		data:     make(map[string]JsonValue),
+		keys:     make([]string, 0),
 	}
 }
 
@@ -71,15 +71,22 @@ func (jo *JsonObject) Set(key string, value JsonValue) (JsonValue, error) {
 	if value == nil {
 		return nil, fmt.Errorf("cannot set nil value for key '%s'", key)
 	}
+	if _, exists := jo.data[key]; !exists {
+		jo.keys = append(jo.keys, key)
+	}
 	jo.data[key] = value
-	jo.updateKeys()
 	return value, nil
 }
 
 func (jo *JsonObject) Remove(key string) (JsonValue, error) {
 	if value, exists := jo.data[key]; exists {
 		delete(jo.data, key)
-		jo.updateKeys()
+		for i, k := range jo.keys {
+			if k == key {
+				jo.keys = append(jo.keys[:i], jo.keys[i+1:]...)
+				break
+			}
+		}
 		return value, nil
 	}
 	return nil, nil // Key not found, return nil
@@ -89,42 +96,22 @@ func (jo *JsonObject) Length() (int, error) {
 	return len(jo.data), nil
 }
 
+// Keys returns the keys of the object in insertion order.
+// Overwriting an existing key keeps its original position.
 func (jo *JsonObject) Keys() ([]string, error) {
-	return jo.sortedkeys, nil
-}
-
-func (jo *JsonObject) updateKeys() {
-	jo.sortedkeys = make([]string, 0, len(jo.data))
-	for key := range jo.data {
-		jo.sortedkeys = append(jo.sortedkeys, key)
-	}
-	sort.Strings(jo.sortedkeys) // Keep keys sorted
+	keys := make([]string, len(jo.keys))
+	copy(keys, jo.keys)
+	return keys, nil
 }
 
+// String returns the compact JSON encoding of the object.
 func (jo *JsonObject) String() string {
-	if len(jo.data) == 0 {
-		return "{}"
-	}
+	return string(Compact(jo))
+}
 
-	result := "{"
-	for i, key := range jo.sortedkeys {
-		if i > 0 {
-			result += ", "
-		}
-		value := jo.data[key]
-		
-		// Format the value based on its type
-		var valueStr string
-		if _, ok := value.(*JsonString); ok {
-			valueStr = fmt.Sprintf("\"%s\"", escapeString(value.String()))
-		} else {
-			valueStr = value.String()
-		}
-		
-		result += fmt.Sprintf("%q: %s", key, valueStr)
-	}
-	result += "}"
-	return result
+// MarshalJSON returns the compact JSON encoding of the object.
+func (jo *JsonObject) MarshalJSON() ([]byte, error) {
+	return Compact(jo), nil
 }
 
 func (jo *JsonObject) Unmarshal(v interface{}) error {
@@ -236,39 +223,61 @@ func (jo *JsonObject) unmarshalToStruct(rv reflect.Value) error {
 
 // PrettyString returns a pretty-printed JSON object
 func (jo *JsonObject) PrettyString() string {
-	return jo.prettyStringWithIndent(0)
+	return prettyString(jo, DefaultPrettyOptions())
 }
 
-// prettyStringWithIndent returns a pretty-printed JSON object with specified indentation
-func (jo *JsonObject) prettyStringWithIndent(indent int) string {
-	if len(jo.data) == 0 {
-		return "{}"
+// PrettyStringWithOptions returns the JSON object formatted according to opts
+func (jo *JsonObject) PrettyStringWithOptions(opts PrettyOptions) string {
+	return prettyString(jo, opts)
+}
+
+// GetPath returns the value addressed by a path of object keys (strings) and
+// array indices (ints), e.g. GetPath("users", 0, "name"). A failure is reported
+// as a *PathError naming the segment that could not be resolved.
+func (jo *JsonObject) GetPath(path ...interface{}) (JsonValue, error) {
+	return Path(path).Get(jo)
+}
+
+// SetPath stores value at the location a path of object keys and array indices
+// addresses within the object, creating missing intermediate objects and arrays.
+func (jo *JsonObject) SetPath(path Path, value JsonValue) error {
+	return path.Set(jo, value, SetPathOptions{})
+}
+
+// SetPathWithOptions is like SetPath but can refuse to create missing
+// containers or to replace values that are not containers.
+func (jo *JsonObject) SetPathWithOptions(path Path, value JsonValue, opts SetPathOptions) error {
+	return path.Set(jo, value, opts)
+}
+
+// GetPointer returns the value the JSON Pointer (RFC 6901) refers to within the object.
+func (jo *JsonObject) GetPointer(pointer string) (JsonValue, error) {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
 	}
+	return ptr.Get(jo)
+}
 
-	indentStr := ""
-	for i := 0; i < indent; i++ {
-		indentStr += "  "
+// SetPointer stores value at the location the JSON Pointer refers to within the object.
+// The token "-" appends to an array. The empty pointer cannot be set.
+func (jo *JsonObject) SetPointer(pointer string, value JsonValue) error {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return err
 	}
-	nextIndentStr := indentStr + "  "
-
-	result := "{\n"
-	keys, _ := jo.Keys()
-	for i, key := range keys {
-		result += nextIndentStr
-		result += fmt.Sprintf("\"%s\": ", escapeString(key))
-
-		value := jo.data[key]
-		if prettyItem, ok := value.(interface{ prettyStringWithIndent(int) string }); ok {
-			result += prettyItem.prettyStringWithIndent(indent + 1)
-		} else {
-			result += value.PrettyString()
-		}
+	if len(ptr) == 0 {
+		return fmt.Errorf("cannot replace the object itself through an empty pointer")
+	}
+	_, err = ptr.Set(jo, value)
+	return err
+}
 
-		if i < len(keys)-1 {
-			result += ","
-		}
-		result += "\n"
+// DeletePointer removes the value the JSON Pointer refers to within the object and returns it.
+func (jo *JsonObject) DeletePointer(pointer string) (JsonValue, error) {
+	ptr, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
 	}
-	result += indentStr + "}"
-	return result
+	return ptr.Delete(jo)
 }
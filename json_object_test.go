@@ -36,8 +36,8 @@ func TestJsonObjectSet(t *testing.T) {
 	}
 	
 	// Test the object contains the value
-	if obj.String() != `{"name": "John"}` {
-		t.Errorf("Object string representation = %v, want {\"name\": \"John\"}", obj.String())
+	if obj.String() != `{"name":"John"}` {
+		t.Errorf("Object string representation = %v, want {\"name\":\"John\"}", obj.String())
 	}
 }
 
@@ -51,7 +51,7 @@ func TestJsonObjectGet(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() error = %v", err)
 	}
-	if val.String() != "John" {
+	if val.String() != `"John"` {
 		t.Errorf("Get(\"name\") = %v, want John", val.String())
 	}
 	
@@ -78,7 +78,7 @@ func TestJsonObjectRemove(t *testing.T) {
 	if err != nil {
 		t.Errorf("Remove() error = %v", err)
 	}
-	if val.String() != "John" {
+	if val.String() != `"John"` {
 		t.Errorf("Remove(\"name\") = %v, want John", val.String())
 	}
 	
@@ -134,8 +134,8 @@ func TestJsonObjectKeys(t *testing.T) {
 		t.Errorf("Keys() error = %v", err)
 	}
 	
-	// Keys should be sorted
-	expected := []string{"apple", "banana", "zebra"}
+	// Keys should keep insertion order
+	expected := []string{"zebra", "apple", "banana"}
 	if len(keys) != len(expected) {
 		t.Errorf("Keys() length = %v, want %v", len(keys), len(expected))
 	}
@@ -245,7 +245,37 @@ func TestJsonObjectNestedGet(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() error = %v", err)
 	}
-	if val.String() != "nested" {
+	if val.String() != `"nested"` {
 		t.Errorf("Get(\"inner\", \"value\") = %v, want nested", val.String())
 	}
 }
+
+func TestJsonObjectInsertionOrder(t *testing.T) {
+	parsed, err := Parse(`{"zeta": 1, "alpha": {"y": 2, "x": 3}, "mid": [true]}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	obj, _ := parsed.AsObject()
+
+	want := `{"zeta":1,"alpha":{"y":2,"x":3},"mid":[true]}`
+	if obj.String() != want {
+		t.Errorf("String() = %v, want %v", obj.String(), want)
+	}
+
+	// Overwriting keeps the position, removing and re-adding moves the key to the end
+	_, _ = obj.Set("zeta", NewJsonInt(10))
+	_, _ = obj.Remove("alpha")
+	_, _ = obj.Set("alpha", NewJsonNull())
+	keys, _ := obj.Keys()
+	expected := []string{"zeta", "mid", "alpha"}
+	for i, key := range expected {
+		if keys[i] != key {
+			t.Errorf("Keys()[%d] = %v, want %v", i, keys[i], key)
+		}
+	}
+
+	sorted := obj.PrettyStringWithOptions(PrettyOptions{KeyOrder: KeyOrderSorted})
+	if sorted != "{\n\"alpha\":null,\n\"mid\":[\ntrue\n],\n\"zeta\":10\n}" {
+		t.Errorf("sorted PrettyStringWithOptions() = %q", sorted)
+	}
+}
@@ -19,8 +19,15 @@ func NewJsonString(value string) *JsonString {
 	}
 }
 
+// String returns the string as a quoted and escaped JSON string.
+// Use AsString to get the raw text.
 func (js *JsonString) String() string {
-	return js.data
+	return string(appendQuotedString(nil, js.data))
+}
+
+// MarshalJSON returns the string as a quoted and escaped JSON string.
+func (js *JsonString) MarshalJSON() ([]byte, error) {
+	return appendQuotedString(nil, js.data), nil
 }
 
 func (js *JsonString) IsString() bool {
@@ -38,6 +45,20 @@ func (js *JsonString) AsInt() (int, error) {
 	return 0, fmt.Errorf("cannot convert '%s' to int", js.data)
 }
 
+func (js *JsonString) AsInt64() (int64, error) {
+	if i, err := strconv.ParseInt(js.data, 10, 64); err == nil {
+		return i, nil
+	}
+	return 0, fmt.Errorf("cannot convert '%s' to int64", js.data)
+}
+
+func (js *JsonString) AsUint64() (uint64, error) {
+	if u, err := strconv.ParseUint(js.data, 10, 64); err == nil {
+		return u, nil
+	}
+	return 0, fmt.Errorf("cannot convert '%s' to uint64", js.data)
+}
+
 func (js *JsonString) AsFloat() (float64, error) {
 	if f, err := strconv.ParseFloat(js.data, 64); err == nil {
 		return f, nil
@@ -92,5 +113,5 @@ func (js *JsonString) UnmarshalTo(v interface{}) error {
 
 // PrettyString returns a pretty-printed JSON string with proper escaping
 func (js *JsonString) PrettyString() string {
-	return fmt.Sprintf("\"%s\"", escapeString(js.data))
+	return js.String()
 }
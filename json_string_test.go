@@ -12,7 +12,7 @@ func TestNewJsonString(t *testing.T) {
 	if !str.IsString() {
 		t.Error("NewJsonString() should return a string")
 	}
-	if str.String() != "hello" {
+	if str.String() != `"hello"` {
 		t.Errorf("NewJsonString().String() = %v, want hello", str.String())
 	}
 }
@@ -0,0 +1,595 @@
+package aaronjson
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONPath is a compiled JSONPath (RFC 9535) query such as
+// $.store.book[?@.price < 10].title. A JSONPath is safe for concurrent use.
+type JSONPath struct {
+	expr  string
+	query *jpQuery
+}
+
+// JSONPathNode is a node selected by a JSONPath query: a value together with
+// its location in the queried document.
+type JSONPathNode struct {
+	Path  Path
+	Value JsonValue
+}
+
+// NormalizedPath returns the RFC 9535 normalized path of the node, e.g. $['users'][0].
+func (n JSONPathNode) NormalizedPath() string {
+	return n.Path.NormalizedPath()
+}
+
+// JSONPathError reports an invalid JSONPath expression.
+// It matches ErrInvalidJSONPath with errors.Is.
+type JSONPathError struct {
+	Expr   string // the expression being compiled
+	Offset int    // byte offset of the problem in Expr
+	Msg    string // description of the problem
+}
+
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("invalid JSONPath %q: %s at offset %d", e.Expr, e.Msg, e.Offset)
+}
+
+func (e *JSONPathError) Unwrap() error {
+	return ErrInvalidJSONPath
+}
+
+// CompileJSONPath parses a JSONPath expression, checking that it is
+// well-formed and well-typed as defined by RFC 9535.
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &jpParser{expr: expr}
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{expr: expr, query: query}, nil
+}
+
+// QueryJSONPath compiles expr and applies it to root.
+func QueryJSONPath(root JsonValue, expr string) ([]JSONPathNode, error) {
+	jp, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return jp.Query(root), nil
+}
+
+// String returns the source expression of the query.
+func (jp *JSONPath) String() string {
+	return jp.expr
+}
+
+// Query applies the query to root and returns the selected nodes in the
+// order defined by RFC 9535. Object members are visited in insertion order.
+func (jp *JSONPath) Query(root JsonValue) []JSONPathNode {
+	nodes := jp.query.eval(root, root)
+	result := make([]JSONPathNode, len(nodes))
+	for i, n := range nodes {
+		result[i] = JSONPathNode{Path: n.loc.path(), Value: n.value}
+	}
+	return result
+}
+
+// NormalizedPath formats the path as an RFC 9535 normalized path,
+// e.g. $['store']['book'][0].
+func (path Path) NormalizedPath() string {
+	buf := []byte{'$'}
+	for _, segment := range path {
+		switch s := segment.(type) {
+		case string:
+			buf = append(buf, '[', '\'')
+			buf = appendNormalizedName(buf, s)
+			buf = append(buf, '\'', ']')
+		case int:
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(s), 10)
+			buf = append(buf, ']')
+		default:
+			buf = append(buf, fmt.Sprintf("[%v]", segment)...)
+		}
+	}
+	return string(buf)
+}
+
+// appendNormalizedName appends a member name escaped for a normalized path.
+func appendNormalizedName(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		case '\'':
+			dst = append(dst, '\\', '\'')
+		case '\\':
+			dst = append(dst, '\\', '\\')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+	}
+	return dst
+}
+
+// jpNode is a value selected during evaluation along with its location.
+type jpNode struct {
+	value JsonValue
+	loc   *jpLocation
+}
+
+// jpLocation is a node's location as a linked list from the node back to the root,
+// so that selecting a child does not copy the path of its parent.
+type jpLocation struct {
+	parent  *jpLocation
+	segment interface{}
+}
+
+// path converts the location into a Path.
+func (loc *jpLocation) path() Path {
+	n := 0
+	for l := loc; l != nil; l = l.parent {
+		n++
+	}
+	path := make(Path, n)
+	for l := loc; l != nil; l = l.parent {
+		n--
+		path[n] = l.segment
+	}
+	return path
+}
+
+// jpQuery is a root (absolute) or current-node (relative) query.
+type jpQuery struct {
+	relative bool
+	segments []jpSegment
+}
+
+// singular reports whether the query selects at most one node.
+func (q *jpQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		if kind := seg.selectors[0].kind; kind != jpSelectName && kind != jpSelectIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// eval applies the query to root, or to current for a relative query.
+func (q *jpQuery) eval(root, current JsonValue) []jpNode {
+	start := root
+	if q.relative {
+		start = current
+	}
+	nodes := []jpNode{{value: start}}
+	for i := range q.segments {
+		nodes = q.segments[i].apply(root, nodes)
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// value evaluates a singular query used as a comparable, reporting false for Nothing.
+func (q *jpQuery) value(root, current JsonValue) (JsonValue, bool) {
+	nodes := q.eval(root, current)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].value, true
+}
+
+// jpSegment is a child segment, or a descendant segment that applies its
+// selectors to the input nodes and all of their descendants.
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+// apply evaluates the segment against each input node in turn.
+func (seg *jpSegment) apply(root JsonValue, nodes []jpNode) []jpNode {
+	var out []jpNode
+	for _, n := range nodes {
+		if seg.descendant {
+			out = seg.applyDescendants(root, n, out)
+			continue
+		}
+		for i := range seg.selectors {
+			out = seg.selectors[i].apply(root, n, out)
+		}
+	}
+	return out
+}
+
+// applyDescendants applies the selectors to n and then to each of its descendants.
+func (seg *jpSegment) applyDescendants(root JsonValue, n jpNode, out []jpNode) []jpNode {
+	for i := range seg.selectors {
+		out = seg.selectors[i].apply(root, n, out)
+	}
+	forEachChild(n, func(child jpNode) {
+		out = seg.applyDescendants(root, child, out)
+	})
+	return out
+}
+
+// forEachChild calls fn with every member of an object, in insertion order,
+// or every element of an array.
+func forEachChild(n jpNode, fn func(jpNode)) {
+	switch v := n.value.(type) {
+	case *JsonObject:
+		for _, key := range v.keys {
+			fn(jpNode{value: v.data[key], loc: &jpLocation{parent: n.loc, segment: key}})
+		}
+	case *JsonArray:
+		for i, elem := range v.data {
+			fn(jpNode{value: elem, loc: &jpLocation{parent: n.loc, segment: i}})
+		}
+	}
+}
+
+type jpSelectorKind int
+
+const (
+	jpSelectName jpSelectorKind = iota
+	jpSelectWildcard
+	jpSelectIndex
+	jpSelectSlice
+	jpSelectFilter
+)
+
+// jpSelector selects children of a node.
+type jpSelector struct {
+	kind   jpSelectorKind
+	name   string
+	index  int
+	slice  [3]*int // start, end and step, nil when omitted
+	filter jpLogical
+}
+
+// apply appends the children of n selected by the selector to out.
+func (sel *jpSelector) apply(root JsonValue, n jpNode, out []jpNode) []jpNode {
+	switch sel.kind {
+	case jpSelectName:
+		if obj, ok := n.value.(*JsonObject); ok {
+			if child, exists := obj.data[sel.name]; exists {
+				out = append(out, jpNode{value: child, loc: &jpLocation{parent: n.loc, segment: sel.name}})
+			}
+		}
+	case jpSelectWildcard:
+		forEachChild(n, func(child jpNode) {
+			out = append(out, child)
+		})
+	case jpSelectIndex:
+		if arr, ok := n.value.(*JsonArray); ok {
+			i := sel.index
+			if i < 0 {
+				i += len(arr.data)
+			}
+			if i >= 0 && i < len(arr.data) {
+				out = append(out, jpNode{value: arr.data[i], loc: &jpLocation{parent: n.loc, segment: i}})
+			}
+		}
+	case jpSelectSlice:
+		if arr, ok := n.value.(*JsonArray); ok {
+			sliceIndices(sel.slice, len(arr.data), func(i int) {
+				out = append(out, jpNode{value: arr.data[i], loc: &jpLocation{parent: n.loc, segment: i}})
+			})
+		}
+	case jpSelectFilter:
+		forEachChild(n, func(child jpNode) {
+			if sel.filter.test(root, child.value) {
+				out = append(out, child)
+			}
+		})
+	}
+	return out
+}
+
+// sliceIndices calls fn with the indices selected by an array slice
+// start:end:step over an array of the given length (RFC 9535, section 2.3.4.2).
+func sliceIndices(bounds [3]*int, length int, fn func(int)) {
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return
+	}
+
+	normalize := func(i int) int {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int) int {
+		return min(max(i, lo), hi)
+	}
+
+	if step > 0 {
+		start, end := 0, length
+		if bounds[0] != nil {
+			start = normalize(*bounds[0])
+		}
+		if bounds[1] != nil {
+			end = normalize(*bounds[1])
+		}
+		for i := clamp(start, 0, length); i < clamp(end, 0, length); i += step {
+			fn(i)
+		}
+		return
+	}
+
+	start, end := length-1, -length-1
+	if bounds[0] != nil {
+		start = normalize(*bounds[0])
+	}
+	if bounds[1] != nil {
+		end = normalize(*bounds[1])
+	}
+	for i := clamp(start, -1, length-1); clamp(end, -1, length-1) < i; i += step {
+		fn(i)
+	}
+}
+
+// jpLogical is a filter expression producing a logical result.
+type jpLogical interface {
+	test(root, current JsonValue) bool
+}
+
+// jpComparable is an operand of a comparison. value reports false for Nothing.
+type jpComparable interface {
+	value(root, current JsonValue) (JsonValue, bool)
+}
+
+type jpOr []jpLogical
+
+func (e jpOr) test(root, current JsonValue) bool {
+	for _, operand := range e {
+		if operand.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+type jpAnd []jpLogical
+
+func (e jpAnd) test(root, current JsonValue) bool {
+	for _, operand := range e {
+		if !operand.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+type jpNot struct {
+	operand jpLogical
+}
+
+func (e jpNot) test(root, current JsonValue) bool {
+	return !e.operand.test(root, current)
+}
+
+// jpExists tests whether a query selects at least one node.
+type jpExists struct {
+	query *jpQuery
+}
+
+func (e jpExists) test(root, current JsonValue) bool {
+	return len(e.query.eval(root, current)) > 0
+}
+
+// jpLiteral is a literal comparable.
+type jpLiteral struct {
+	v JsonValue
+}
+
+func (l jpLiteral) value(root, current JsonValue) (JsonValue, bool) {
+	return l.v, true
+}
+
+// jpComparison compares two comparables.
+type jpComparison struct {
+	op          string
+	left, right jpComparable
+}
+
+func (e jpComparison) test(root, current JsonValue) bool {
+	a, aok := e.left.value(root, current)
+	b, bok := e.right.value(root, current)
+	switch e.op {
+	case "==":
+		return jpEqual(a, aok, b, bok)
+	case "!=":
+		return !jpEqual(a, aok, b, bok)
+	case "<":
+		return aok && bok && jpLess(a, b)
+	case "<=":
+		return (aok && bok && jpLess(a, b)) || jpEqual(a, aok, b, bok)
+	case ">":
+		return aok && bok && jpLess(b, a)
+	case ">=":
+		return (aok && bok && jpLess(b, a)) || jpEqual(a, aok, b, bok)
+	}
+	return false
+}
+
+// jpEqual implements == for values that may be Nothing.
+func jpEqual(a JsonValue, aok bool, b JsonValue, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	return Equal(a, b)
+}
+
+// jpLess implements < , which only orders two numbers or two strings.
+func jpLess(a, b JsonValue) bool {
+	if as, ok := a.(*JsonString); ok {
+		bs, ok := b.(*JsonString)
+		// Byte order of UTF-8 matches the order of Unicode scalar values
+		return ok && as.data < bs.data
+	}
+	return isNumber(a) && isNumber(b) && compareNumbers(a, b) == -1
+}
+
+// isNumber reports whether v is a JSON number.
+func isNumber(v JsonValue) bool {
+	switch v.(type) {
+	case *JsonInt, *JsonFloat, *JsonNumber:
+		return true
+	}
+	return false
+}
+
+// jpType is the declared type of a function parameter or result (RFC 9535, section 2.4.1).
+type jpType int
+
+const (
+	jpValueType jpType = iota
+	jpLogicalType
+	jpNodesType
+)
+
+// jpFunctionSignatures lists the function extensions defined by RFC 9535.
+var jpFunctionSignatures = map[string]struct {
+	params []jpType
+	result jpType
+}{
+	"length": {params: []jpType{jpValueType}, result: jpValueType},
+	"count":  {params: []jpType{jpNodesType}, result: jpValueType},
+	"match":  {params: []jpType{jpValueType, jpValueType}, result: jpLogicalType},
+	"search": {params: []jpType{jpValueType, jpValueType}, result: jpLogicalType},
+	"value":  {params: []jpType{jpNodesType}, result: jpValueType},
+}
+
+// jpFunction is a call to a function extension. Each argument is a
+// jpLiteral, a *jpQuery or a *jpFunction.
+type jpFunction struct {
+	name   string
+	args   []interface{}
+	result jpType
+	re     *regexp.Regexp // precompiled pattern of match or search, if it is a literal
+}
+
+// value evaluates a function whose result is ValueType.
+func (f *jpFunction) value(root, current JsonValue) (JsonValue, bool) {
+	switch f.name {
+	case "length":
+		v, ok := f.valueArg(0, root, current)
+		if !ok {
+			return nil, false
+		}
+		switch tv := v.(type) {
+		case *JsonString:
+			return NewJsonInt64(int64(utf8.RuneCountInString(tv.data))), true
+		case *JsonArray:
+			return NewJsonInt64(int64(len(tv.data))), true
+		case *JsonObject:
+			return NewJsonInt64(int64(len(tv.data))), true
+		}
+		return nil, false
+	case "count":
+		return NewJsonInt64(int64(len(f.nodesArg(0, root, current)))), true
+	case "value":
+		nodes := f.nodesArg(0, root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].value, true
+	}
+	return nil, false
+}
+
+// test evaluates a function whose result is LogicalType or NodesType.
+func (f *jpFunction) test(root, current JsonValue) bool {
+	if f.result == jpNodesType {
+		return len(f.nodesArg(0, root, current)) > 0
+	}
+
+	subject, ok := f.valueArg(0, root, current)
+	s, isString := subject.(*JsonString)
+	if !ok || !isString {
+		return false
+	}
+	re := f.re
+	if re == nil {
+		pattern, ok := f.valueArg(1, root, current)
+		ps, isString := pattern.(*JsonString)
+		if !ok || !isString {
+			return false
+		}
+		var err error
+		if re, err = compileIRegexp(ps.data, f.name == "match"); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(s.data)
+}
+
+// valueArg evaluates argument i as a ValueType.
+func (f *jpFunction) valueArg(i int, root, current JsonValue) (JsonValue, bool) {
+	return f.args[i].(jpComparable).value(root, current)
+}
+
+// nodesArg evaluates argument i as a NodesType.
+func (f *jpFunction) nodesArg(i int, root, current JsonValue) []jpNode {
+	return f.args[i].(*jpQuery).eval(root, current)
+}
+
+// compileIRegexp compiles an I-Regexp (RFC 9485) pattern. match anchors the
+// pattern to the whole string; otherwise it may match any substring.
+func compileIRegexp(pattern string, match bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	if match {
+		sb.WriteString(`\A(?:`)
+	}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			sb.WriteByte(c)
+			i++
+			sb.WriteByte(pattern[i])
+		case c == '[':
+			inClass = true
+			sb.WriteByte(c)
+		case c == ']':
+			inClass = false
+			sb.WriteByte(c)
+		case c == '.' && !inClass:
+			// I-Regexp's dot excludes both line terminators
+			sb.WriteString(`[^\n\r]`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if match {
+		sb.WriteString(`)\z`)
+	}
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,603 @@
+package aaronjson
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxJSONPathInt is the largest magnitude of an index, slice bound or step
+// allowed by RFC 9535 (the I-JSON exact integer range).
+const maxJSONPathInt = 1<<53 - 1
+
+// jpParser is a recursive descent parser for the RFC 9535 grammar.
+type jpParser struct {
+	expr string
+	pos  int
+}
+
+// errorf returns a JSONPathError at pos.
+func (p *jpParser) errorf(pos int, format string, args ...interface{}) error {
+	return &JSONPathError{Expr: p.expr, Offset: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// unexpected reports the character at the current position, or the end of the expression.
+func (p *jpParser) unexpected(want string) error {
+	if p.pos >= len(p.expr) {
+		return p.errorf(p.pos, "unexpected end of expression, expected %s", want)
+	}
+	return p.errorf(p.pos, "unexpected character %s, expected %s", quoteChar(p.expr[p.pos]), want)
+}
+
+// peek reports whether the next character is c.
+func (p *jpParser) peek(c byte) bool {
+	return p.pos < len(p.expr) && p.expr[p.pos] == c
+}
+
+// skipBlank skips the blank characters RFC 9535 allows between tokens.
+func (p *jpParser) skipBlank() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseQuery parses a whole jsonpath-query.
+func (p *jpParser) parseQuery() (*jpQuery, error) {
+	if !p.peek('$') {
+		return nil, p.unexpected("'$'")
+	}
+	p.pos++
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.expr) {
+		return nil, p.unexpected("'.' or '['")
+	}
+	return &jpQuery{segments: segments}, nil
+}
+
+// parseSegments parses the segments following a root or current node identifier.
+func (p *jpParser) parseSegments() ([]jpSegment, error) {
+	var segments []jpSegment
+	for {
+		// Blanks may precede a segment, but belong to the caller otherwise
+		start := p.pos
+		p.skipBlank()
+		if !p.peek('.') && !p.peek('[') {
+			p.pos = start
+			return segments, nil
+		}
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+}
+
+// parseSegment parses a child or descendant segment starting at '.' or '['.
+func (p *jpParser) parseSegment() (jpSegment, error) {
+	if p.peek('[') {
+		selectors, err := p.parseBracketed()
+		return jpSegment{selectors: selectors}, err
+	}
+
+	p.pos++ // Move past '.'
+	descendant := p.peek('.')
+	if descendant {
+		p.pos++
+		if p.peek('[') {
+			selectors, err := p.parseBracketed()
+			return jpSegment{descendant: true, selectors: selectors}, err
+		}
+	}
+	if p.peek('*') {
+		p.pos++
+		return jpSegment{descendant: descendant, selectors: []jpSelector{{kind: jpSelectWildcard}}}, nil
+	}
+	name, err := p.parseMemberName()
+	if err != nil {
+		return jpSegment{}, err
+	}
+	return jpSegment{descendant: descendant, selectors: []jpSelector{{kind: jpSelectName, name: name}}}, nil
+}
+
+// parseMemberName parses a member-name-shorthand such as the name in $.name.
+func (p *jpParser) parseMemberName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !isNameFirst(r, size) && !(p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.unexpected("member name or '*'")
+	}
+	return p.expr[start:p.pos], nil
+}
+
+// isNameFirst reports whether the decoded rune can start a member-name-shorthand.
+func isNameFirst(r rune, size int) bool {
+	if r == utf8.RuneError && size <= 1 {
+		return false
+	}
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r >= 0x80
+}
+
+// parseBracketed parses a bracketed-selection starting at '['.
+func (p *jpParser) parseBracketed() ([]jpSelector, error) {
+	p.pos++ // Move past '['
+	var selectors []jpSelector
+	for {
+		p.skipBlank()
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+
+		p.skipBlank()
+		switch {
+		case p.peek(','):
+			p.pos++
+		case p.peek(']'):
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.unexpected("',' or ']'")
+		}
+	}
+}
+
+// parseSelector parses one selector of a bracketed selection.
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	if p.pos >= len(p.expr) {
+		return jpSelector{}, p.unexpected("selector")
+	}
+	switch c := p.expr[p.pos]; {
+	case c == '\'' || c == '"':
+		name, err := p.parseStringLiteral()
+		return jpSelector{kind: jpSelectName, name: name}, err
+	case c == '*':
+		p.pos++
+		return jpSelector{kind: jpSelectWildcard}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		filter, err := p.parseLogicalOr()
+		return jpSelector{kind: jpSelectFilter, filter: filter}, err
+	case c == ':' || c == '-' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	default:
+		return jpSelector{}, p.unexpected("selector")
+	}
+}
+
+// parseIndexOrSlice parses an index selector such as -1 or a slice selector such as 1:5:2.
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var bounds [3]*int
+	if !p.peek(':') {
+		start, err := p.parseInt()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		p.skipBlank()
+		if !p.peek(':') {
+			return jpSelector{kind: jpSelectIndex, index: start}, nil
+		}
+		bounds[0] = &start
+	}
+
+	p.pos++ // Move past the first ':'
+	p.skipBlank()
+	if p.peek('-') || (p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9') {
+		end, err := p.parseInt()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		bounds[1] = &end
+		p.skipBlank()
+	}
+	if p.peek(':') {
+		p.pos++
+		p.skipBlank()
+		if p.peek('-') || (p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9') {
+			step, err := p.parseInt()
+			if err != nil {
+				return jpSelector{}, err
+			}
+			bounds[2] = &step
+		}
+	}
+	return jpSelector{kind: jpSelectSlice, slice: bounds}, nil
+}
+
+// parseInt parses an integer without leading zeros within the RFC 9535 range.
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek('-') {
+		p.pos++
+	}
+	digits := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	lexeme := p.expr[start:p.pos]
+	switch {
+	case p.pos == digits:
+		return 0, p.unexpected("digit")
+	case p.expr[digits] == '0' && (p.pos-digits > 1 || digits > start):
+		return 0, p.errorf(start, "invalid integer %q", lexeme)
+	}
+	n, err := strconv.ParseInt(lexeme, 10, 64)
+	if err != nil || n > maxJSONPathInt || n < -maxJSONPathInt {
+		return 0, p.errorf(start, "integer %s out of range", lexeme)
+	}
+	return int(n), nil
+}
+
+// parseStringLiteral parses a single- or double-quoted string literal.
+func (p *jpParser) parseStringLiteral() (string, error) {
+	start := p.pos
+	quote := p.expr[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\':
+			r, err := p.parseStringEscape(quote)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(r)
+		case c < 0x20:
+			return "", p.errorf(p.pos, "invalid control character %#02x in string", c)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf(start, "unterminated string")
+}
+
+// parseStringEscape parses an escape sequence inside a string delimited by quote.
+func (p *jpParser) parseStringEscape(quote byte) (rune, error) {
+	start := p.pos
+	p.pos++ // Move past '\'
+	if p.pos >= len(p.expr) {
+		return 0, p.errorf(start, "unterminated escape sequence")
+	}
+	c := p.expr[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case '/', '\\', quote:
+		return rune(c), nil
+	case 'u':
+		r, ok := parseHex4([]byte(p.expr), p.pos)
+		if !ok {
+			return 0, p.errorf(start, "invalid unicode escape")
+		}
+		p.pos += 4
+		switch {
+		case r >= 0xDC00 && r <= 0xDFFF:
+			return 0, p.errorf(start, "unpaired low surrogate in unicode escape")
+		case r >= 0xD800 && r <= 0xDBFF:
+			if !strings.HasPrefix(p.expr[p.pos:], `\u`) {
+				return 0, p.errorf(start, "unpaired high surrogate in unicode escape")
+			}
+			low, ok := parseHex4([]byte(p.expr), p.pos+2)
+			if !ok || low < 0xDC00 || low > 0xDFFF {
+				return 0, p.errorf(start, "unpaired high surrogate in unicode escape")
+			}
+			p.pos += 6
+			return 0x10000 + (r-0xD800)<<10 + (low - 0xDC00), nil
+		}
+		return r, nil
+	default:
+		return 0, p.errorf(start, "invalid escape sequence '\\%c'", c)
+	}
+}
+
+// parseLogicalOr parses a logical-or-expr of a filter.
+func (p *jpParser) parseLogicalOr() (jpLogical, error) {
+	return p.parseLogicalChain("||", p.parseLogicalAnd, func(operands []jpLogical) jpLogical { return jpOr(operands) })
+}
+
+// parseLogicalAnd parses a logical-and-expr of a filter.
+func (p *jpParser) parseLogicalAnd() (jpLogical, error) {
+	return p.parseLogicalChain("&&", p.parseBasic, func(operands []jpLogical) jpLogical { return jpAnd(operands) })
+}
+
+// parseLogicalChain parses operands separated by op, combining two or more with join.
+func (p *jpParser) parseLogicalChain(op string, operand func() (jpLogical, error), join func([]jpLogical) jpLogical) (jpLogical, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []jpLogical{first}
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !strings.HasPrefix(p.expr[p.pos:], op) {
+			p.pos = start
+			break
+		}
+		p.pos += len(op)
+		p.skipBlank()
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return join(operands), nil
+}
+
+// parseBasic parses a parenthesized expression, a comparison or a test expression,
+// each optionally negated (comparisons only within parentheses).
+func (p *jpParser) parseBasic() (jpLogical, error) {
+	if p.peek('!') {
+		p.pos++
+		p.skipBlank()
+		if p.peek('(') {
+			operand, err := p.parseParen()
+			return jpNot{operand}, err
+		}
+		start := p.pos
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		test, err := p.testExpr(operand, start)
+		return jpNot{test}, err
+	}
+	if p.peek('(') {
+		return p.parseParen()
+	}
+
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	end := p.pos
+	p.skipBlank()
+	op := p.comparisonOp()
+	if op == "" {
+		p.pos = end
+		return p.testExpr(left, start)
+	}
+	if err := p.checkComparable(left, start); err != nil {
+		return nil, err
+	}
+	p.pos += len(op)
+	p.skipBlank()
+	rightStart := p.pos
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkComparable(right, rightStart); err != nil {
+		return nil, err
+	}
+	return jpComparison{op: op, left: left.(jpComparable), right: right.(jpComparable)}, nil
+}
+
+// parseParen parses a parenthesized logical expression starting at '('.
+func (p *jpParser) parseParen() (jpLogical, error) {
+	p.pos++ // Move past '('
+	p.skipBlank()
+	expr, err := p.parseLogicalOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if !p.peek(')') {
+		return nil, p.unexpected("')'")
+	}
+	p.pos++
+	return expr, nil
+}
+
+// comparisonOp returns the comparison operator at the current position, if any.
+func (p *jpParser) comparisonOp() string {
+	rest := p.expr[p.pos:]
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(rest, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// parseOperand parses a literal, a filter query or a function call,
+// returning a jpLiteral, a *jpQuery or a *jpFunction.
+func (p *jpParser) parseOperand() (interface{}, error) {
+	if p.pos >= len(p.expr) {
+		return nil, p.unexpected("filter expression")
+	}
+	switch c := p.expr[p.pos]; {
+	case c == '$' || c == '@':
+		p.pos++
+		segments, err := p.parseSegments()
+		return &jpQuery{relative: c == '@', segments: segments}, err
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		return jpLiteral{NewJsonString(s)}, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumberLiteral()
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for p.pos < len(p.expr) {
+			c := p.expr[p.pos]
+			if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '_' {
+				break
+			}
+			p.pos++
+		}
+		name := p.expr[start:p.pos]
+		if p.peek('(') {
+			return p.parseFunction(name, start)
+		}
+		switch name {
+		case "true":
+			return jpLiteral{NewJsonBool(true)}, nil
+		case "false":
+			return jpLiteral{NewJsonBool(false)}, nil
+		case "null":
+			return jpLiteral{NewJsonNull()}, nil
+		}
+		return nil, p.errorf(start, "unknown literal %q", name)
+	default:
+		return nil, p.unexpected("filter expression")
+	}
+}
+
+// parseNumberLiteral parses a number literal, which follows the JSON number grammar.
+func (p *jpParser) parseNumberLiteral() (interface{}, error) {
+	start := p.pos
+	end, isFloat, err := scanNumber([]byte(p.expr), start)
+	if err != nil {
+		return nil, p.errorf(start, "invalid number")
+	}
+	p.pos = end
+	lexeme := p.expr[start:end]
+	if !isFloat {
+		if n, err := strconv.ParseInt(lexeme, 10, 64); err == nil {
+			return jpLiteral{NewJsonInt64(n)}, nil
+		}
+	}
+	if f, err := strconv.ParseFloat(lexeme, 64); err == nil {
+		return jpLiteral{NewJsonFloat(f)}, nil
+	}
+	// Keep numbers beyond float64 exact
+	return jpLiteral{&JsonNumber{data: lexeme}}, nil
+}
+
+// parseFunction parses the arguments of a call to the function name, checking
+// them against its signature.
+func (p *jpParser) parseFunction(name string, start int) (*jpFunction, error) {
+	signature, ok := jpFunctionSignatures[name]
+	if !ok {
+		return nil, p.errorf(start, "unknown function %s()", name)
+	}
+	p.pos++ // Move past '('
+
+	fn := &jpFunction{name: name, result: signature.result}
+	p.skipBlank()
+	for !p.peek(')') {
+		if len(fn.args) > 0 {
+			if !p.peek(',') {
+				return nil, p.unexpected("',' or ')'")
+			}
+			p.pos++
+			p.skipBlank()
+		}
+		argStart := p.pos
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if len(fn.args) < len(signature.params) {
+			if err := p.checkArgument(name, arg, signature.params[len(fn.args)], argStart); err != nil {
+				return nil, err
+			}
+		}
+		fn.args = append(fn.args, arg)
+		p.skipBlank()
+	}
+	p.pos++ // Move past ')'
+
+	if len(fn.args) != len(signature.params) {
+		return nil, p.errorf(start, "%s() takes %d argument(s), got %d", name, len(signature.params), len(fn.args))
+	}
+	if name == "match" || name == "search" {
+		if pattern, ok := fn.args[1].(jpLiteral); ok {
+			if s, ok := pattern.v.(*JsonString); ok {
+				// A literal pattern is compiled once; an invalid one never matches
+				if re, err := compileIRegexp(s.data, name == "match"); err == nil {
+					fn.re = re
+				} else {
+					fn.re = jpNeverMatch
+				}
+			}
+		}
+	}
+	return fn, nil
+}
+
+// jpNeverMatch stands in for an invalid literal pattern of match or search.
+var jpNeverMatch = regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+
+// checkArgument checks that arg can be passed to a parameter of type param.
+func (p *jpParser) checkArgument(name string, arg interface{}, param jpType, pos int) error {
+	switch param {
+	case jpValueType:
+		if err := p.checkComparable(arg, pos); err != nil {
+			return p.errorf(pos, "%s() argument must be a value: %s", name, err.(*JSONPathError).Msg)
+		}
+	case jpNodesType:
+		if _, ok := arg.(*jpQuery); !ok {
+			return p.errorf(pos, "%s() argument must be a query", name)
+		}
+	}
+	return nil
+}
+
+// checkComparable checks that operand produces a single value: a literal,
+// a singular query or a function returning a value.
+func (p *jpParser) checkComparable(operand interface{}, pos int) error {
+	switch o := operand.(type) {
+	case *jpQuery:
+		if !o.singular() {
+			return p.errorf(pos, "query is not singular")
+		}
+	case *jpFunction:
+		if o.result != jpValueType {
+			return p.errorf(pos, "%s() does not return a value", o.name)
+		}
+	}
+	return nil
+}
+
+// testExpr turns an operand that is not compared into a test expression.
+func (p *jpParser) testExpr(operand interface{}, pos int) (jpLogical, error) {
+	switch o := operand.(type) {
+	case *jpQuery:
+		return jpExists{o}, nil
+	case *jpFunction:
+		if o.result == jpValueType {
+			return nil, p.errorf(pos, "result of %s() must be compared", o.name)
+		}
+		return o, nil
+	default:
+		return nil, p.errorf(pos, "literal must be compared")
+	}
+}
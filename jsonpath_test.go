@@ -0,0 +1,298 @@
+package aaronjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const jsonPathStore = `{"store": {
+	"book": [
+		{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+		{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+		{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+		{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+	],
+	"bicycle": {"color": "red", "price": 399}
+}}`
+
+// formatNodes renders query results as "path=value" pairs for comparison.
+func formatNodes(nodes []JSONPathNode) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.NormalizedPath() + "=" + n.Value.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatValues renders only the values of query results.
+func formatValues(nodes []JSONPathNode) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.Value.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestJSONPathQuery(t *testing.T) {
+	store := mustParse(t, jsonPathStore)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `$.store.book[*].author`, want: `"Nigel Rees" "Evelyn Waugh" "Herman Melville" "J. R. R. Tolkien"`},
+		{expr: `$..author`, want: `"Nigel Rees" "Evelyn Waugh" "Herman Melville" "J. R. R. Tolkien"`},
+		{expr: `$.store..price`, want: `8.95 12.99 8.99 22.99 399`},
+		{expr: `$..book[2].title`, want: `"Moby Dick"`},
+		{expr: `$..book[-1].title`, want: `"The Lord of the Rings"`},
+		{expr: `$..book[0,1].title`, want: `"Sayings of the Century" "Sword of Honour"`},
+		{expr: `$..book[:2].title`, want: `"Sayings of the Century" "Sword of Honour"`},
+		{expr: `$..book[?@.isbn].title`, want: `"Moby Dick" "The Lord of the Rings"`},
+		{expr: `$..book[?@.price<10].title`, want: `"Sayings of the Century" "Moby Dick"`},
+		{expr: `$.store.book[?@.price < 10 && @.category == 'fiction'].title`, want: `"Moby Dick"`},
+		{expr: `$.store.book[?!(@.price < 10 || @.isbn)].title`, want: `"Sword of Honour"`},
+		{expr: `$.store.book[?!@.isbn].title`, want: `"Sayings of the Century" "Sword of Honour"`},
+		{expr: `$.store.book[?@.price == $.store.book[0].price].title`, want: `"Sayings of the Century"`},
+		{expr: `$["store"]['bicycle']["color"]`, want: `"red"`},
+		{expr: `$.store.bicycle[*]`, want: `"red" 399`},
+		{expr: `$.store.bicycle.price.x`, want: ``},
+		{expr: `$.missing`, want: ``},
+		{expr: `$`, want: `{"store":{"book":[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],"bicycle":{"color":"red","price":399}}}`},
+		{expr: `$.store.book[?length(@.author) > 12].author`, want: `"Herman Melville" "J. R. R. Tolkien"`},
+		{expr: `$.store.book[?match(@.author, 'N.*')].author`, want: `"Nigel Rees"`},
+		{expr: `$.store.book[?search(@.title, 'of the')].title`, want: `"Sayings of the Century" "The Lord of the Rings"`},
+		{expr: `$.store[?count(@.*) == 2]`, want: `{"color":"red","price":399}`},
+		{expr: `$.store.book[?value(@..isbn) == '0-553-21311-3'].title`, want: `"Moby Dick"`},
+		{expr: `$.store.book[? @.price > 20 ].title`, want: `"The Lord of the Rings"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nodes, err := QueryJSONPath(store, tt.expr)
+			if err != nil {
+				t.Fatalf("QueryJSONPath() error = %v", err)
+			}
+			if got := formatValues(nodes); got != tt.want {
+				t.Errorf("QueryJSONPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathSelectors(t *testing.T) {
+	arr := mustParse(t, `["a", "b", "c", "d", "e", "f", "g"]`)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `$[1]`, want: `"b"`},
+		{expr: `$[-2]`, want: `"f"`},
+		{expr: `$[7]`, want: ``},
+		{expr: `$[-8]`, want: ``},
+		{expr: `$[1:3]`, want: `"b" "c"`},
+		{expr: `$[5:]`, want: `"f" "g"`},
+		{expr: `$[1:5:2]`, want: `"b" "d"`},
+		{expr: `$[5:1:-2]`, want: `"f" "d"`},
+		{expr: `$[::-1]`, want: `"g" "f" "e" "d" "c" "b" "a"`},
+		{expr: `$[-3:]`, want: `"e" "f" "g"`},
+		{expr: `$[::0]`, want: ``},
+		{expr: `$[-100:100:3]`, want: `"a" "d" "g"`},
+		{expr: `$[0, 0, -1]`, want: `"a" "a" "g"`},
+		{expr: `$[0:2, 5]`, want: `"a" "b" "f"`},
+		{expr: `$.a`, want: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nodes, err := QueryJSONPath(arr, tt.expr)
+			if err != nil {
+				t.Fatalf("QueryJSONPath() error = %v", err)
+			}
+			if got := formatValues(nodes); got != tt.want {
+				t.Errorf("QueryJSONPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathFilters(t *testing.T) {
+	doc := mustParse(t, `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}, null, true, "5"],
+		"o": {"p": 1, "q": 2, "r": 3}, "e": [], "n": 5.0}`)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `$.a[?@ > 3.5]`, want: `5 4 6`},
+		{expr: `$.a[?@ == 5]`, want: `5`},
+		{expr: `$.a[?@ == $.n]`, want: `5`},
+		{expr: `$.a[?@ == "5"]`, want: `"5"`},
+		{expr: `$.a[?@ <= 2]`, want: `1 2`},
+		{expr: `$.a[?@ < 'k']`, want: `"5"`},
+		{expr: `$.a[?@.b >= 'k']`, want: `{"b":"k"} {"b":"kilo"}`},
+		{expr: `$.a[?@.b]`, want: `{"b":"j"} {"b":"k"} {"b":{}} {"b":"kilo"}`},
+		{expr: `$.a[?@.b == $.missing]`, want: `3 5 1 2 4 6 null true "5"`},
+		{expr: `$.a[?@ == null]`, want: `null`},
+		{expr: `$.a[?@ == true]`, want: `true`},
+		{expr: `$.a[?@ != 5 && @ < 3]`, want: `1 2`},
+		{expr: `$.a[?@ < 2 || @ > 5]`, want: `1 6`},
+		{expr: `$.a[?(@ < 2 || @ > 5) && @ != 1]`, want: `6`},
+		{expr: `$.a[?@ == 1e0]`, want: `1`},
+		{expr: `$.a[?@ == -0]`, want: ``},
+		{expr: `$.o[?@ > 1]`, want: `2 3`},
+		{expr: `$[?@ == $.e]`, want: `[]`},
+		{expr: `$.a[?match(@.b, 'k.*')]`, want: `{"b":"k"} {"b":"kilo"}`},
+		{expr: `$.a[?match(@.b, 'k')]`, want: `{"b":"k"}`},
+		{expr: `$.a[?search(@.b, '[jk]')]`, want: `{"b":"j"} {"b":"k"} {"b":"kilo"}`},
+		{expr: `$.a[?match(@.b, '[')]`, want: ``},
+		{expr: `$.a[?length(@) == 1]`, want: `{"b":"j"} {"b":"k"} {"b":{}} {"b":"kilo"} "5"`},
+		{expr: `$.a[?length(@.b) == 4]`, want: `{"b":"kilo"}`},
+		{expr: `$[?count(@[*]) == 0]`, want: `[] 5.0`},
+		{expr: `$.a[?@.b == 'j', ?@ == 1]`, want: `{"b":"j"} 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nodes, err := QueryJSONPath(doc, tt.expr)
+			if err != nil {
+				t.Fatalf("QueryJSONPath() error = %v", err)
+			}
+			if got := formatValues(nodes); got != tt.want {
+				t.Errorf("QueryJSONPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// I-Regexp's dot does not match line terminators
+	lines := mustParse(t, `["a\nb", "a b"]`)
+	nodes, err := QueryJSONPath(lines, `$[?match(@, 'a.b')]`)
+	if err != nil || formatValues(nodes) != `"a b"` {
+		t.Errorf("match() with '.' = %s, %v, want only the string without a newline", formatValues(nodes), err)
+	}
+}
+
+func TestJSONPathNormalizedPaths(t *testing.T) {
+	doc := mustParse(t, `{"a": {"b": [10, {"c": 20}]}, "it's": 1, "tab\t\u0001": 2}`)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `$.a.b[1].c`, want: `$['a']['b'][1]['c']=20`},
+		{expr: `$.a.b[-2]`, want: `$['a']['b'][0]=10`},
+		{expr: `$..c`, want: `$['a']['b'][1]['c']=20`},
+		{expr: `$..*`, want: `$['a']={"b":[10,{"c":20}]} $['it\'s']=1 $['tab\t\u0001']=2 $['a']['b']=[10,{"c":20}] ` +
+			`$['a']['b'][0]=10 $['a']['b'][1]={"c":20} $['a']['b'][1]['c']=20`},
+		{expr: `$["it's"]`, want: `$['it\'s']=1`},
+		{expr: `$`, want: `$={"a":{"b":[10,{"c":20}]},"it's":1,"tab\t\u0001":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nodes, err := QueryJSONPath(doc, tt.expr)
+			if err != nil {
+				t.Fatalf("QueryJSONPath() error = %v", err)
+			}
+			if got := formatNodes(nodes); got != tt.want {
+				t.Errorf("QueryJSONPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Paths of results address the same values with GetPath
+	nodes, _ := QueryJSONPath(doc, `$..*`)
+	for _, n := range nodes {
+		if got, err := n.Path.Get(doc); err != nil || got != n.Value {
+			t.Errorf("Path(%s).Get() = %v, %v, want %v", n.Path, got, err, n.Value)
+		}
+	}
+}
+
+func TestJSONPathNames(t *testing.T) {
+	doc := mustParse(t, `{"é": 1, "_x1": 2, "a\"b": 3, "😀": 4, "\b": 5, "a'b": 6}`)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `$.é`, want: `1`},
+		{expr: `$._x1`, want: `2`},
+		{expr: `$["a\"b"]`, want: `3`},
+		{expr: `$['a"b']`, want: `3`},
+		{expr: `$['😀']`, want: `4`},
+		{expr: `$.😀`, want: `4`},
+		{expr: `$['\b']`, want: `5`},
+		{expr: `$['a\'b']`, want: `6`},
+		{expr: `$['é']`, want: `1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nodes, err := QueryJSONPath(doc, tt.expr)
+			if err != nil {
+				t.Fatalf("QueryJSONPath() error = %v", err)
+			}
+			if got := formatValues(nodes); got != tt.want {
+				t.Errorf("QueryJSONPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ``},
+		{name: "no root", expr: `store.book`},
+		{name: "leading blank", expr: ` $.a`},
+		{name: "trailing blank", expr: `$.a `},
+		{name: "trailing dot", expr: `$.`},
+		{name: "blank after dot", expr: `$. a`},
+		{name: "name starting with digit", expr: `$.1a`},
+		{name: "unclosed bracket", expr: `$[0`},
+		{name: "empty brackets", expr: `$[]`},
+		{name: "trailing comma", expr: `$[0,]`},
+		{name: "leading zero", expr: `$[01]`},
+		{name: "negative zero", expr: `$[-0]`},
+		{name: "index out of range", expr: `$[9007199254740992]`},
+		{name: "float index", expr: `$[1.0]`},
+		{name: "unterminated string", expr: `$['a]`},
+		{name: "invalid escape", expr: `$['\a']`},
+		{name: "wrong quote escape", expr: `$['\"']`},
+		{name: "lone surrogate", expr: `$['\ud800']`},
+		{name: "control character", expr: "$['\x01']"},
+		{name: "empty filter", expr: `$[?]`},
+		{name: "literal test", expr: `$[?1]`},
+		{name: "non-singular comparison", expr: `$[?@.* == 1]`},
+		{name: "descendant comparison", expr: `$[?@..a == 1]`},
+		{name: "negated comparison", expr: `$[?!@.a == 1]`},
+		{name: "unknown function", expr: `$[?foo(@)]`},
+		{name: "value function as test", expr: `$[?length(@)]`},
+		{name: "logical function compared", expr: `$[?match(@, 'a') == true]`},
+		{name: "wrong argument count", expr: `$[?length(@, @)]`},
+		{name: "nodes argument literal", expr: `$[?count(1) == 1]`},
+		{name: "value argument non-singular", expr: `$[?length(@.*) == 1]`},
+		{name: "unknown literal", expr: `$[?@ == nil]`},
+		{name: "object literal", expr: `$[?@ == {}]`},
+		{name: "single equals", expr: `$[?@ = 1]`},
+		{name: "unclosed paren", expr: `$[?(@.a]`},
+		{name: "uppercase literal", expr: `$[?@ == True]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileJSONPath(tt.expr)
+			if !errors.Is(err, ErrInvalidJSONPath) {
+				t.Fatalf("CompileJSONPath(%q) error = %v, want ErrInvalidJSONPath", tt.expr, err)
+			}
+			var pe *JSONPathError
+			if !errors.As(err, &pe) || pe.Offset < 0 || pe.Offset > len(tt.expr) {
+				t.Errorf("CompileJSONPath(%q) error = %v, want a JSONPathError with a valid offset", tt.expr, err)
+			}
+		})
+	}
+}
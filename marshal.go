@@ -2,7 +2,9 @@ package aaronjson
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 )
 
 // Marshal converts a Go value to JsonValue.
@@ -26,15 +28,20 @@ func marshalValue(rv reflect.Value) (JsonValue, error) {
 		rv = rv.Elem()
 	}
 
+	switch rv.Type() {
+	case bigIntType, bigFloatType:
+		return marshalBigNumber(rv)
+	}
+
 	switch rv.Kind() {
 	case reflect.Bool:
 		return NewJsonBool(rv.Bool()), nil
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return NewJsonInt(float64(rv.Int())), nil
+		return NewJsonInt64(rv.Int()), nil
 
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return NewJsonInt(float64(rv.Uint())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewJsonUint64(rv.Uint()), nil
 
 	case reflect.Float32, reflect.Float64:
 		return NewJsonFloat(rv.Float()), nil
@@ -62,6 +69,21 @@ func marshalValue(rv reflect.Value) (JsonValue, error) {
 	}
 }
 
+// marshalBigNumber converts a big.Int or big.Float to JsonNumber without losing precision
+func marshalBigNumber(rv reflect.Value) (JsonValue, error) {
+	var text string
+	switch n := rv.Interface().(type) {
+	case big.Int:
+		text = n.String()
+	case big.Float:
+		if n.IsInf() {
+			return nil, fmt.Errorf("unsupported value: %s", n.String())
+		}
+		text = n.Text('g', -1)
+	}
+	return NewJsonNumber(text)
+}
+
 // marshalSlice converts a slice or array to JsonArray
 func marshalSlice(rv reflect.Value) (JsonValue, error) {
 	arr := NewJsonArray()
@@ -86,7 +108,13 @@ func marshalMap(rv reflect.Value) (JsonValue, error) {
 
 	obj := NewJsonObject()
 
-	for _, key := range rv.MapKeys() {
+	// Map iteration order is random, so add members in sorted key order
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
 		keyStr := key.String()
 		value := rv.MapIndex(key)
 
@@ -19,17 +19,27 @@ func TestMarshal(t *testing.T) {
 		{
 			name:  "string value",
 			input: "hello",
-			want:  "hello",
+			want:  `"hello"`,
 		},
 		{
 			name:  "int value",
 			input: 42,
-			want:  "42.000000",
+			want:  "42",
+		},
+		{
+			name:  "int64 value above 2^53",
+			input: int64(9007199254740993),
+			want:  "9007199254740993",
+		},
+		{
+			name:  "uint64 max value",
+			input: uint64(18446744073709551615),
+			want:  "18446744073709551615",
 		},
 		{
 			name:  "float value",
 			input: 3.14,
-			want:  "3.140000",
+			want:  "3.14",
 		},
 		{
 			name:  "bool true",
@@ -44,17 +54,17 @@ func TestMarshal(t *testing.T) {
 		{
 			name:  "slice of strings",
 			input: []string{"hello", "world"},
-			want:  "[\"hello\", \"world\"]",
+			want:  "[\"hello\",\"world\"]",
 		},
 		{
 			name:  "slice of ints",
 			input: []int{1, 2, 3},
-			want:  "[1.000000, 2.000000, 3.000000]",
+			want:  "[1,2,3]",
 		},
 		{
 			name:  "map with string keys",
 			input: map[string]interface{}{"name": "John", "age": 30},
-			want:  `{"age": 30.000000, "name": "John"}`,
+			want:  `{"age":30,"name":"John"}`,
 		},
 		{
 			name:  "empty slice",
@@ -108,22 +118,22 @@ func TestMarshalStruct(t *testing.T) {
 		{
 			name:  "simple struct",
 			input: Person{Name: "John", Age: 30},
-			want:  `{"age": 30.000000, "name": "John"}`,
+			want:  `{"name":"John","age":30}`,
 		},
 		{
 			name:  "struct with omitempty - non-zero values",
 			input: PersonWithOmit{Name: "John", Age: 30, Email: "john@example.com"},
-			want:  `{"age": 30.000000, "email": "john@example.com", "name": "John"}`,
+			want:  `{"name":"John","age":30,"email":"john@example.com"}`,
 		},
 		{
 			name:  "struct with omitempty - zero values",
 			input: PersonWithOmit{Name: "John", Age: 0, Email: ""},
-			want:  `{"name": "John"}`,
+			want:  `{"name":"John"}`,
 		},
 		{
 			name:  "struct with ignored field",
 			input: PersonWithIgnore{Name: "John", Age: 30, Password: "secret"},
-			want:  `{"age": 30.000000, "name": "John"}`,
+			want:  `{"name":"John","age":30}`,
 		},
 	}
 
@@ -153,12 +163,12 @@ func TestMarshalPointer(t *testing.T) {
 		{
 			name:  "pointer to string",
 			input: &str,
-			want:  "hello",
+			want:  `"hello"`,
 		},
 		{
 			name:  "pointer to int",
 			input: &num,
-			want:  "42.000000",
+			want:  "42",
 		},
 		{
 			name:  "nil pointer",
@@ -190,12 +200,12 @@ func TestMarshalArray(t *testing.T) {
 		{
 			name:  "array of strings",
 			input: [3]string{"a", "b", "c"},
-			want:  "[\"a\", \"b\", \"c\"]",
+			want:  "[\"a\",\"b\",\"c\"]",
 		},
 		{
 			name:  "array of ints",
 			input: [2]int{1, 2},
-			want:  "[1.000000, 2.000000]",
+			want:  "[1,2]",
 		},
 	}
 
@@ -242,7 +252,7 @@ func TestMarshalNestedStructures(t *testing.T) {
 
 	// Check that it contains expected components
 	result := got.String()
-	if !contains(result, `"name": "John"`) {
+	if !contains(result, `"name":"John"`) {
 		t.Error("Result should contain name field")
 	}
 	if !contains(result, `"address"`) {
@@ -293,7 +303,7 @@ func TestMarshalInterface(t *testing.T) {
 		t.Errorf("Marshal() error = %v", err)
 		return
 	}
-	if got.String() != "hello" {
+	if got.String() != `"hello"` {
 		t.Errorf("Marshal() = %v, want hello", got.String())
 	}
 
@@ -0,0 +1,92 @@
+package aaronjson
+
+import (
+	"fmt"
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to target and returns the
+// result. An object patch is merged member by member: a null member removes
+// the key from target, and any other member is merged into the target's
+// member of the same name. Any other patch, including an array, replaces
+// target wholesale. A nil target stands for a missing document.
+//
+// target itself is never modified, and the result shares no arrays or
+// objects with target or patch.
+func MergePatch(target, patch JsonValue) (JsonValue, error) {
+	if patch == nil {
+		return nil, fmt.Errorf("cannot apply a nil json merge patch")
+	}
+	return mergePatch(cloneValue(target), patch), nil
+}
+
+// mergePatch merges patch into target, which it may modify.
+func mergePatch(target, patch JsonValue) JsonValue {
+	p, ok := patch.(*JsonObject)
+	if !ok {
+		return cloneValue(patch)
+	}
+	t, ok := target.(*JsonObject)
+	if !ok {
+		t = NewJsonObject()
+	}
+	for _, key := range p.keys {
+		value := p.data[key]
+		if value.IsNull() {
+			_, _ = t.Remove(key)
+			continue
+		}
+		_, _ = t.Set(key, mergePatch(t.data[key], value))
+	}
+	return t
+}
+
+// CreateMergePatch returns a JSON Merge Patch that turns original into
+// modified: members removed from an object become null, changed members are
+// merged recursively, and changed non-object values are given in full.
+// Unchanged members are left out, so equal documents give the empty object.
+//
+// A merge patch cannot set a member to null, so if modified needs a null
+// object member that original lacks, CreateMergePatch fails with an error
+// matching ErrMergePatchNull. Nulls inside arrays are fine.
+func CreateMergePatch(original, modified JsonValue) (JsonValue, error) {
+	if original == nil || modified == nil {
+		return nil, fmt.Errorf("cannot create a json merge patch from a nil document")
+	}
+	return diffMerge(Pointer{}, original, modified)
+}
+
+// diffMerge returns the merge patch that turns a into b at path.
+func diffMerge(path Pointer, a, b JsonValue) (JsonValue, error) {
+	bv, ok := b.(*JsonObject)
+	if !ok {
+		return cloneValue(b), nil
+	}
+	av, ok := a.(*JsonObject)
+	if !ok {
+		// Merging into a non-object starts from an empty object
+		av = NewJsonObject()
+	}
+
+	patch := NewJsonObject()
+	for _, key := range av.keys {
+		if _, ok := bv.data[key]; !ok {
+			_, _ = patch.Set(key, NewJsonNull())
+		}
+	}
+	for _, key := range bv.keys {
+		value := bv.data[key]
+		old, exists := av.data[key]
+		if exists && Equal(old, value) {
+			continue
+		}
+		if value.IsNull() {
+			return nil, fmt.Errorf("%w: %q", ErrMergePatchNull, childPointer(path, key).String())
+		}
+		member, err := diffMerge(childPointer(path, key), old, value)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = patch.Set(key, member)
+	}
+	return patch, nil
+}
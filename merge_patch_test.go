@@ -0,0 +1,128 @@
+package aaronjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7396, Appendix A, and a few more
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		{target: `{"a":1}`, patch: `{}`, want: `{"a":1}`},
+		{target: `{"a":[null]}`, patch: `{"b":[null,{"c":null}]}`, want: `{"a":[null],"b":[null,{"c":null}]}`},
+	}
+
+	for _, tt := range tests {
+		target := mustParse(t, tt.target)
+		got, err := MergePatch(target, mustParse(t, tt.patch))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s) error: %v", tt.target, tt.patch, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.target, tt.patch, got.String(), tt.want)
+		}
+		if target.String() != tt.target {
+			t.Errorf("MergePatch(%s, %s) modified the target: %s", tt.target, tt.patch, target.String())
+		}
+	}
+}
+
+func TestMergePatchMissingTarget(t *testing.T) {
+	got, err := MergePatch(nil, mustParse(t, `{"a":{"b":null,"c":1}}`))
+	if err != nil || got.String() != `{"a":{"c":1}}` {
+		t.Errorf("MergePatch(nil, ...) = %v, %v", got, err)
+	}
+	if _, err := MergePatch(mustParse(t, `{}`), nil); err == nil {
+		t.Error("MergePatch with a nil patch succeeded")
+	}
+}
+
+func TestMergePatchDoesNotShareValues(t *testing.T) {
+	patch := mustParse(t, `{"a":{"b":[1]}}`)
+	got, err := MergePatch(mustParse(t, `{}`), patch)
+	if err != nil {
+		t.Fatalf("MergePatch error: %v", err)
+	}
+	if _, err := (Pointer{"a", "b", "-"}).Set(got, NewJsonInt64(2)); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if patch.String() != `{"a":{"b":[1]}}` {
+		t.Errorf("result shares values with the patch: %s", patch.String())
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+		want     string
+	}{
+		{original: `{"a":1}`, modified: `{"a":1}`, want: `{}`},
+		{original: `{"a":1,"b":2}`, modified: `{"a":1,"b":3,"c":4}`, want: `{"b":3,"c":4}`},
+		{original: `{"a":1,"b":2}`, modified: `{"b":2}`, want: `{"a":null}`},
+		{original: `{"a":{"x":1,"y":2},"b":[1]}`, modified: `{"a":{"x":1,"z":3},"b":[1,2]}`, want: `{"a":{"y":null,"z":3},"b":[1,2]}`},
+		{original: `{"a":5}`, modified: `{"a":{"b":1}}`, want: `{"a":{"b":1}}`},
+		{original: `{"a":{"b":1}}`, modified: `{"a":{}}`, want: `{"a":{"b":null}}`},
+		{original: `{"a":5}`, modified: `{"a":{}}`, want: `{"a":{}}`},
+		{original: `{"a":null}`, modified: `{"a":null,"b":1}`, want: `{"b":1}`},
+		{original: `{"a":[{"b":1}]}`, modified: `{"a":[{"b":null}]}`, want: `{"a":[{"b":null}]}`},
+		{original: `{"a":1.0}`, modified: `{"a":1}`, want: `{}`},
+		{original: `[1]`, modified: `[1,2]`, want: `[1,2]`},
+		{original: `{"a":1}`, modified: `null`, want: `null`},
+		{original: `[1]`, modified: `{"a":{"b":1}}`, want: `{"a":{"b":1}}`},
+	}
+
+	for _, tt := range tests {
+		original := mustParse(t, tt.original)
+		modified := mustParse(t, tt.modified)
+		patch, err := CreateMergePatch(original, modified)
+		if err != nil {
+			t.Fatalf("CreateMergePatch(%s, %s) error: %v", tt.original, tt.modified, err)
+		}
+		if patch.String() != tt.want {
+			t.Errorf("CreateMergePatch(%s, %s) = %s, want %s", tt.original, tt.modified, patch.String(), tt.want)
+		}
+		got, err := MergePatch(original, patch)
+		if err != nil || !Equal(got, modified) {
+			t.Errorf("applying CreateMergePatch(%s, %s) = %v, %v", tt.original, tt.modified, got, err)
+		}
+	}
+}
+
+func TestCreateMergePatchNull(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+	}{
+		{original: `{"a":1}`, modified: `{"a":null}`},
+		{original: `{}`, modified: `{"a":{"b":null}}`},
+		{original: `{"a":{"b":1}}`, modified: `{"a":{"b":null}}`},
+		{original: `[]`, modified: `{"a":null}`},
+	}
+
+	for _, tt := range tests {
+		_, err := CreateMergePatch(mustParse(t, tt.original), mustParse(t, tt.modified))
+		if !errors.Is(err, ErrMergePatchNull) {
+			t.Errorf("CreateMergePatch(%s, %s) error = %v, want ErrMergePatchNull", tt.original, tt.modified, err)
+		}
+	}
+}
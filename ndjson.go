@@ -0,0 +1,258 @@
+package aaronjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// BadLinePolicy selects how an NDJSONReader handles lines that fail to parse.
+type BadLinePolicy int
+
+const (
+	// BadLineStop ends reading at the first bad line and reports it through Err.
+	BadLineStop BadLinePolicy = iota
+	// BadLineSkip silently skips bad lines.
+	BadLineSkip
+	// BadLineCollect skips bad lines and records them for Errors.
+	BadLineCollect
+)
+
+// NDJSONOptions controls an NDJSONReader.
+type NDJSONOptions struct {
+	// Parse holds the options used to parse each line.
+	// MaxBytes limits the length of a single line: a longer line is a bad
+	// line reported with a *LimitError, and no more than MaxBytes of it is
+	// held in memory.
+	Parse ParseOptions
+	// BadLines selects what happens to lines that fail to parse.
+	BadLines BadLinePolicy
+}
+
+// LineError reports a line of newline-delimited JSON that failed to parse.
+// Positions in Err are relative to the start of the line, whose content is
+// also the Snippet of a *SyntaxError.
+type LineError struct {
+	Line int    // 1-based line number
+	Data []byte // the content of the line, without the line terminator, cut off at MaxBytes
+	Err  error  // the parse error
+}
+
+func (e *LineError) Error() string {
+	// Within a single line only the column of a position is of interest
+	switch err := e.Err.(type) {
+	case *SyntaxError:
+		if err.Line != 0 {
+			return fmt.Sprintf("line %d: %s at column %d (offset %d)", e.Line, err.Msg, err.Column, err.Offset)
+		}
+	case *DuplicateKeyError:
+		if err.Line != 0 {
+			return fmt.Sprintf("line %d: duplicate key %q at column %d (offset %d); first defined at column %d (offset %d)",
+				e.Line, err.Key, err.Column, err.Offset, err.FirstColumn, err.FirstOffset)
+		}
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// newLineError creates a LineError for a line that failed to parse, filling
+// in the position details of err from the line's content.
+func newLineError(line int, data []byte, err error) *LineError {
+	return &LineError{Line: line, Data: data, Err: withErrorContext(err, data)}
+}
+
+// lineReader splits its input into lines, holding no more than maxBytes of a
+// line (plus its terminator) in memory when maxBytes is positive.
+type lineReader struct {
+	r        *bufio.Reader
+	maxBytes int
+	// partial is set while the rest of an overlong line is still unread.
+	partial bool
+}
+
+// next returns the content of the next line without its "\n" or "\r\n"
+// terminator, or io.EOF at the end of the input. A line longer than maxBytes
+// is cut off at maxBytes and reported with a *LimitError; call skip to
+// move past the rest of it.
+func (lr *lineReader) next() ([]byte, *LimitError, error) {
+	var data []byte
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		data = append(data, chunk...)
+		if err == bufio.ErrBufferFull {
+			// A line can still end in "\r\n" after maxBytes of content
+			if lr.maxBytes > 0 && len(data) > lr.maxBytes+1 {
+				lr.partial = true
+				return data[:lr.maxBytes], lr.limitError(), nil
+			}
+			continue
+		}
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return nil, nil, err
+		}
+		break
+	}
+
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	if lr.maxBytes > 0 && len(data) > lr.maxBytes {
+		return data[:lr.maxBytes], lr.limitError(), nil
+	}
+	return data, nil, nil
+}
+
+// skip discards the rest of an overlong line returned by next.
+func (lr *lineReader) skip() error {
+	for lr.partial {
+		_, err := lr.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		lr.partial = false
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitError reports a line longer than maxBytes.
+func (lr *lineReader) limitError() *LimitError {
+	return newLimitError(ErrMaxBytesExceeded, lr.maxBytes, lr.maxBytes)
+}
+
+// NDJSONReader reads newline-delimited JSON (also known as JSON Lines),
+// yielding one JsonValue per line. Blank lines are ignored.
+type NDJSONReader struct {
+	r        *lineReader
+	p        *parser
+	policy   BadLinePolicy
+	line     int
+	value    JsonValue
+	err      error
+	badLines []*LineError
+}
+
+// NewNDJSONReader returns a new reader that reads newline-delimited JSON from r.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return NewNDJSONReaderWithOptions(r, NDJSONOptions{})
+}
+
+// NewNDJSONReaderWithOptions returns a new NDJSON reader using the given options.
+func NewNDJSONReaderWithOptions(r io.Reader, opts NDJSONOptions) *NDJSONReader {
+	return &NDJSONReader{
+		r:      &lineReader{r: bufio.NewReader(r), maxBytes: opts.Parse.MaxBytes},
+		p:      newParser(opts.Parse),
+		policy: opts.BadLines,
+	}
+}
+
+// Next advances to the next value, which is then available through Value.
+// It returns false at the end of the input or when reading stops on an error;
+// Err reports which.
+func (nr *NDJSONReader) Next() bool {
+	if nr.err != nil {
+		return false
+	}
+	for {
+		data, limitErr, err := nr.r.next()
+		if err != nil {
+			nr.err = err
+			return false
+		}
+		nr.line++
+
+		var perr error
+		if limitErr != nil {
+			perr = limitErr
+		} else {
+			if skipWhitespace(data, 0) == len(data) {
+				continue
+			}
+			var value JsonValue
+			if value, perr = nr.p.parseDocument(data); perr == nil {
+				nr.value = value
+				return true
+			}
+		}
+		lineErr := newLineError(nr.line, data, perr)
+
+		switch nr.policy {
+		case BadLineSkip:
+		case BadLineCollect:
+			nr.badLines = append(nr.badLines, lineErr)
+		default:
+			nr.err = lineErr
+			return false
+		}
+		if err := nr.r.skip(); err != nil {
+			nr.err = err
+			return false
+		}
+	}
+}
+
+// Value returns the value read by the most recent call to Next.
+func (nr *NDJSONReader) Value() JsonValue {
+	return nr.value
+}
+
+// Line returns the 1-based number of the line read by the most recent call to Next.
+func (nr *NDJSONReader) Line() int {
+	return nr.line
+}
+
+// Err returns the error that stopped reading, or nil if the input ended cleanly.
+// A bad line under BadLineStop is reported as a *LineError.
+func (nr *NDJSONReader) Err() error {
+	if nr.err == io.EOF {
+		return nil
+	}
+	return nr.err
+}
+
+// Errors returns the bad lines recorded so far under BadLineCollect.
+func (nr *NDJSONReader) Errors() []*LineError {
+	return nr.badLines
+}
+
+// ReadAll reads the remaining values until the end of the input.
+// It returns the values read before any error that stopped reading.
+func (nr *NDJSONReader) ReadAll() ([]JsonValue, error) {
+	values := make([]JsonValue, 0)
+	for nr.Next() {
+		values = append(values, nr.value)
+	}
+	return values, nr.Err()
+}
+
+// NDJSONWriter writes values as newline-delimited JSON, one compact value per line.
+// Output is buffered; call Flush when done.
+type NDJSONWriter struct {
+	w       *bufio.Writer
+	scratch []byte
+}
+
+// NewNDJSONWriter returns a new writer that writes newline-delimited JSON to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// WriteValue writes the compact encoding of v followed by a newline.
+// Compact output escapes newlines in strings, so every value fits on one line.
+func (nw *NDJSONWriter) WriteValue(v JsonValue) error {
+	nw.scratch = append(appendCompact(nw.scratch[:0], v), '\n')
+	_, err := nw.w.Write(nw.scratch)
+	return err
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (nw *NDJSONWriter) Flush() error {
+	return nw.w.Flush()
+}
@@ -0,0 +1,235 @@
+package aaronjson
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// NDJSONProcessOptions controls ProcessNDJSON.
+type NDJSONProcessOptions struct {
+	NDJSONOptions
+
+	// Workers is the number of goroutines parsing and processing lines.
+	// Zero means runtime.GOMAXPROCS(0).
+	Workers int
+	// Ordered delivers results in input order instead of as they complete.
+	Ordered bool
+	// MaxInFlight bounds the number of lines read but not yet delivered.
+	// Reading pauses while the limit is reached, so a slow deliver function
+	// holds back the whole pipeline. Zero means four times Workers.
+	MaxInFlight int
+}
+
+// NDJSONResult is the outcome of processing one line of newline-delimited JSON.
+type NDJSONResult[T any] struct {
+	Line  int   // 1-based line number
+	Value T     // the value returned by the process function
+	Err   error // a *LineError if the line failed to parse, or the process function's error
+}
+
+// ndjsonJob is a line handed to a worker.
+type ndjsonJob struct {
+	seq  int
+	line int
+	data []byte
+	err  error // set for a line rejected before parsing
+}
+
+// ndjsonOutcome is a processed line on its way back from a worker.
+type ndjsonOutcome[T any] struct {
+	seq     int
+	result  NDJSONResult[T]
+	badLine bool
+}
+
+// ProcessNDJSON reads newline-delimited JSON from r and parses and processes
+// the lines on a pool of worker goroutines. process is called concurrently with
+// each parsed value; deliver is called on the calling goroutine with each
+// result, in input order if opts.Ordered is set and as completed otherwise.
+// Blank lines are ignored.
+//
+// Lines that fail to parse are handled according to opts.BadLines: skipped,
+// delivered with a *LineError, or delivered and then processing stops with
+// that *LineError returned (the default). Errors from process are delivered
+// in the result and do not stop processing.
+//
+// Processing stops early if ctx is cancelled, if deliver returns an error, or
+// if reading fails; the corresponding error is returned. ProcessNDJSON waits
+// for all of its goroutines to exit before returning.
+func ProcessNDJSON[T any](ctx context.Context, r io.Reader, opts NDJSONProcessOptions,
+	process func(line int, v JsonValue) (T, error), deliver func(NDJSONResult[T]) error) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	maxInFlight := opts.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = 4 * workers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan ndjsonJob, workers)
+	outcomes := make(chan ndjsonOutcome[T], workers)
+	slots := make(chan struct{}, maxInFlight)
+
+	// The reader fills the job queue, waiting for a free slot before each line
+	var readErr error
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		defer close(jobs)
+		readErr = readNDJSONLines(ctx, r, opts.NDJSONOptions, slots, jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := newParser(opts.Parse)
+			for job := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				out := ndjsonOutcome[T]{seq: job.seq, result: NDJSONResult[T]{Line: job.line}}
+				var value JsonValue
+				err := job.err
+				if err == nil {
+					value, err = p.parseDocument(job.data)
+				}
+				if err != nil {
+					out.result.Err = newLineError(job.line, job.data, err)
+					out.badLine = true
+				} else {
+					out.result.Value, out.result.Err = process(job.line, value)
+				}
+				select {
+				case outcomes <- out:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	err := deliverNDJSON(ctx, opts, outcomes, slots, deliver)
+	cancel()
+	for range outcomes {
+		// Drain until every worker has exited
+	}
+	<-readDone
+	if err == nil {
+		err = readErr
+	}
+	return err
+}
+
+// readNDJSONLines sends every non-blank line of r to jobs, acquiring a slot for each.
+// A line longer than opts.Parse.MaxBytes is sent cut off, with its error.
+func readNDJSONLines(ctx context.Context, r io.Reader, opts NDJSONOptions, slots chan struct{}, jobs chan<- ndjsonJob) error {
+	lr := &lineReader{r: bufio.NewReader(r), maxBytes: opts.Parse.MaxBytes}
+	line, seq := 0, 0
+	for {
+		data, limitErr, err := lr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line++
+
+		job := ndjsonJob{seq: seq, line: line, data: data}
+		if limitErr != nil {
+			job.err = limitErr
+		} else if skipWhitespace(data, 0) == len(data) {
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		seq++
+		if limitErr != nil {
+			// Processing stops at the bad line unless it is skipped or delivered
+			if opts.BadLines == BadLineStop {
+				return nil
+			}
+			if err := lr.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deliverNDJSON passes outcomes to deliver, releasing a slot for each line handled.
+// In ordered mode, outcomes that arrive early wait in a buffer bounded by the slot count.
+func deliverNDJSON[T any](ctx context.Context, opts NDJSONProcessOptions, outcomes <-chan ndjsonOutcome[T],
+	slots chan struct{}, deliver func(NDJSONResult[T]) error) error {
+	pending := make(map[int]ndjsonOutcome[T])
+	next := 0
+	for {
+		var out ndjsonOutcome[T]
+		select {
+		case o, ok := <-outcomes:
+			if !ok {
+				return nil
+			}
+			out = o
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if !opts.Ordered {
+			if err := deliverOutcome(opts.BadLines, out, slots, deliver); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending[out.seq] = out
+		for {
+			o, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := deliverOutcome(opts.BadLines, o, slots, deliver); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deliverOutcome applies the bad-line policy to a single outcome and delivers it.
+func deliverOutcome[T any](policy BadLinePolicy, out ndjsonOutcome[T], slots chan struct{},
+	deliver func(NDJSONResult[T]) error) error {
+	defer func() { <-slots }()
+	if out.badLine && policy == BadLineSkip {
+		return nil
+	}
+	if err := deliver(out.result); err != nil {
+		return err
+	}
+	if out.badLine && policy == BadLineStop {
+		return out.result.Err
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package aaronjson
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ndjsonLines builds n lines of the form {"id": i}.
+func ndjsonLines(n int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "{\"id\": %d}\n", i)
+	}
+	return sb.String()
+}
+
+// idOf extracts the id field, sleeping briefly so that workers finish out of order.
+func idOf(line int, v JsonValue) (int, error) {
+	time.Sleep(time.Duration(line%3) * time.Millisecond)
+	id, err := v.Get("id")
+	if err != nil {
+		return 0, err
+	}
+	return id.AsInt()
+}
+
+func TestProcessNDJSONOrdered(t *testing.T) {
+	var got []int
+	opts := NDJSONProcessOptions{Workers: 4, Ordered: true}
+	err := ProcessNDJSON(context.Background(), strings.NewReader(ndjsonLines(100)), opts, idOf,
+		func(res NDJSONResult[int]) error {
+			if res.Err != nil {
+				return res.Err
+			}
+			if res.Line != res.Value {
+				t.Errorf("result for line %d has value %d", res.Line, res.Value)
+			}
+			got = append(got, res.Value)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ProcessNDJSON() error = %v", err)
+	}
+	if len(got) != 100 || !sort.IntsAreSorted(got) {
+		t.Errorf("results not in input order: %v", got)
+	}
+}
+
+func TestProcessNDJSONUnordered(t *testing.T) {
+	var got []int
+	err := ProcessNDJSON(context.Background(), strings.NewReader(ndjsonLines(100)), NDJSONProcessOptions{Workers: 4}, idOf,
+		func(res NDJSONResult[int]) error {
+			got = append(got, res.Value)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ProcessNDJSON() error = %v", err)
+	}
+	sort.Ints(got)
+	if len(got) != 100 || got[0] != 1 || got[99] != 100 {
+		t.Errorf("missing results: %v", got)
+	}
+}
+
+func TestProcessNDJSONBadLines(t *testing.T) {
+	input := "{\"id\": 1}\n{bad\n\n{\"id\": 4}\n"
+	tests := []struct {
+		name      string
+		policy    BadLinePolicy
+		wantLines string
+		wantErr   bool
+	}{
+		{name: "stop", policy: BadLineStop, wantLines: "[1 2]", wantErr: true},
+		{name: "skip", policy: BadLineSkip, wantLines: "[1 4]"},
+		{name: "collect", policy: BadLineCollect, wantLines: "[1 2 4]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []int
+			opts := NDJSONProcessOptions{NDJSONOptions: NDJSONOptions{BadLines: tt.policy}, Workers: 2, Ordered: true}
+			err := ProcessNDJSON(context.Background(), strings.NewReader(input), opts, idOf,
+				func(res NDJSONResult[int]) error {
+					lines = append(lines, res.Line)
+					var lineErr *LineError
+					if (res.Line == 2) != errors.As(res.Err, &lineErr) {
+						t.Errorf("line %d: Err = %v", res.Line, res.Err)
+					}
+					return nil
+				})
+			if fmt.Sprint(lines) != tt.wantLines {
+				t.Errorf("delivered lines = %v, want %v", lines, tt.wantLines)
+			}
+			var lineErr *LineError
+			if tt.wantErr != errors.As(err, &lineErr) {
+				t.Errorf("ProcessNDJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessNDJSONLongLines(t *testing.T) {
+	input := "{\"id\": 1}\n[\"" + strings.Repeat("x", 100000) + "\"]\n{\"id\": 3}\n"
+	opts := NDJSONProcessOptions{
+		NDJSONOptions: NDJSONOptions{Parse: ParseOptions{MaxBytes: 32}, BadLines: BadLineCollect},
+		Workers:       2,
+		Ordered:       true,
+	}
+	var lines []int
+	err := ProcessNDJSON(context.Background(), strings.NewReader(input), opts, idOf,
+		func(res NDJSONResult[int]) error {
+			lines = append(lines, res.Line)
+			var lineErr *LineError
+			if res.Line == 2 && (!errors.As(res.Err, &lineErr) || !errors.Is(res.Err, ErrMaxBytesExceeded) || len(lineErr.Data) != 32) {
+				t.Errorf("line 2: Err = %v", res.Err)
+			}
+			return nil
+		})
+	if err != nil || fmt.Sprint(lines) != "[1 2 3]" {
+		t.Errorf("ProcessNDJSON() = %v, delivered lines %v, want [1 2 3]", err, lines)
+	}
+
+	opts.BadLines = BadLineStop
+	err = ProcessNDJSON(context.Background(), &endlessReader{}, opts, idOf,
+		func(NDJSONResult[int]) error { return nil })
+	if !errors.Is(err, ErrMaxBytesExceeded) {
+		t.Errorf("ProcessNDJSON() on an endless line = %v, want ErrMaxBytesExceeded", err)
+	}
+}
+
+func TestProcessNDJSONCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	delivered := 0
+	err := ProcessNDJSON(ctx, strings.NewReader(ndjsonLines(1000)), NDJSONProcessOptions{Workers: 4}, idOf,
+		func(res NDJSONResult[int]) error {
+			delivered++
+			if delivered == 10 {
+				cancel()
+			}
+			return nil
+		})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ProcessNDJSON() error = %v, want context.Canceled", err)
+	}
+	if delivered >= 1000 {
+		t.Error("processing should stop after cancellation")
+	}
+}
+
+func TestProcessNDJSONBackpressure(t *testing.T) {
+	var processed int32
+	stop := errors.New("stop")
+	opts := NDJSONProcessOptions{Workers: 2, MaxInFlight: 3}
+	err := ProcessNDJSON(context.Background(), strings.NewReader(ndjsonLines(100)), opts,
+		func(line int, v JsonValue) (int, error) {
+			atomic.AddInt32(&processed, 1)
+			return line, nil
+		},
+		func(res NDJSONResult[int]) error {
+			// While the first result is being delivered, no more than
+			// MaxInFlight lines may have been read
+			time.Sleep(20 * time.Millisecond)
+			if n := atomic.LoadInt32(&processed); n > 3 {
+				t.Errorf("processed %d lines while delivery was blocked, want at most 3", n)
+			}
+			return stop
+		})
+	if err != stop {
+		t.Errorf("ProcessNDJSON() error = %v, want deliver's error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package aaronjson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	input := "{\"id\": 1}\r\n\n  \n[1, 2]\n\"text\"\n42"
+	nr := NewNDJSONReader(strings.NewReader(input))
+
+	var got []string
+	var lines []int
+	for nr.Next() {
+		got = append(got, nr.Value().String())
+		lines = append(lines, nr.Line())
+	}
+	if err := nr.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if want := `{"id":1} [1,2] "text" 42`; strings.Join(got, " ") != want {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+	if want := "[1 4 5 6]"; fmt.Sprint(lines) != want {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
+
+func TestNDJSONReaderBadLines(t *testing.T) {
+	input := "{\"id\": 1}\n{\"id\": \n{\"id\": 3}\nnope\n"
+
+	t.Run("stop", func(t *testing.T) {
+		values, err := NewNDJSONReader(strings.NewReader(input)).ReadAll()
+		if len(values) != 1 {
+			t.Errorf("ReadAll() returned %d values, want 1", len(values))
+		}
+		var lineErr *LineError
+		if !errors.As(err, &lineErr) {
+			t.Fatalf("ReadAll() error = %v, want LineError", err)
+		}
+		if lineErr.Line != 2 || string(lineErr.Data) != `{"id": ` {
+			t.Errorf("LineError = line %d %q, want line 2", lineErr.Line, lineErr.Data)
+		}
+		if !errors.Is(err, ErrInvalidJsonFormat) {
+			t.Errorf("errors.Is(err, ErrInvalidJsonFormat) = false for %v", err)
+		}
+		if !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Errorf("Error() = %q, want line prefix", err.Error())
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		nr := NewNDJSONReaderWithOptions(strings.NewReader(input), NDJSONOptions{BadLines: BadLineSkip})
+		values, err := nr.ReadAll()
+		if err != nil || len(values) != 2 {
+			t.Errorf("ReadAll() = %d values, %v, want 2 values", len(values), err)
+		}
+		if len(nr.Errors()) != 0 {
+			t.Errorf("Errors() = %v, want none", nr.Errors())
+		}
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		nr := NewNDJSONReaderWithOptions(strings.NewReader(input), NDJSONOptions{BadLines: BadLineCollect})
+		values, err := nr.ReadAll()
+		if err != nil || len(values) != 2 {
+			t.Errorf("ReadAll() = %d values, %v, want 2 values", len(values), err)
+		}
+		bad := nr.Errors()
+		if len(bad) != 2 || bad[0].Line != 2 || bad[1].Line != 4 {
+			t.Fatalf("Errors() = %v, want lines 2 and 4", bad)
+		}
+		if string(bad[1].Data) != "nope" {
+			t.Errorf("Errors()[1].Data = %q, want nope", bad[1].Data)
+		}
+	})
+}
+
+func TestNDJSONReaderOptions(t *testing.T) {
+	opts := NDJSONOptions{Parse: ParseOptions{UseNumber: true, MaxBytes: 20}}
+	nr := NewNDJSONReaderWithOptions(strings.NewReader("1.50\n[\"this line is too long\"]\n"), opts)
+	if !nr.Next() {
+		t.Fatalf("Next() error = %v", nr.Err())
+	}
+	if _, ok := nr.Value().(*JsonNumber); !ok {
+		t.Errorf("Value() = %T, want *JsonNumber", nr.Value())
+	}
+	if nr.Next() || !errors.Is(nr.Err(), ErrMaxBytesExceeded) {
+		t.Errorf("Err() = %v, want ErrMaxBytesExceeded", nr.Err())
+	}
+}
+
+// endlessReader is an input with a single line that never ends.
+type endlessReader struct{ read int }
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+func TestNDJSONReaderLongLines(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	input := "[1]\n\"" + long + "\"\n\"abcdefghijklmnop\"\r\n[\"" + long
+	tests := []struct {
+		policy    BadLinePolicy
+		wantValue string
+		wantBad   []int
+	}{
+		{policy: BadLineStop, wantValue: "[1]"},
+		{policy: BadLineSkip, wantValue: `[1] "abcdefghijklmnop"`},
+		{policy: BadLineCollect, wantValue: `[1] "abcdefghijklmnop"`, wantBad: []int{2, 4}},
+	}
+
+	for _, tt := range tests {
+		opts := NDJSONOptions{Parse: ParseOptions{MaxBytes: 18}, BadLines: tt.policy}
+		nr := NewNDJSONReaderWithOptions(strings.NewReader(input), opts)
+		values, err := nr.ReadAll()
+		var got []string
+		for _, v := range values {
+			got = append(got, v.String())
+		}
+		if strings.Join(got, " ") != tt.wantValue {
+			t.Errorf("policy %d: values = %v, want %v", tt.policy, got, tt.wantValue)
+		}
+
+		bad := nr.Errors()
+		if tt.policy == BadLineStop {
+			var lineErr *LineError
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("policy %d: Err() = %v, want a LineError", tt.policy, err)
+			}
+			bad = []*LineError{lineErr}
+		} else if err != nil {
+			t.Errorf("policy %d: Err() = %v", tt.policy, err)
+		}
+		var lines []int
+		for _, lineErr := range bad {
+			lines = append(lines, lineErr.Line)
+			if !errors.Is(lineErr, ErrMaxBytesExceeded) || len(lineErr.Data) != 18 {
+				t.Errorf("policy %d: LineError = %v with %d bytes of data", tt.policy, lineErr, len(lineErr.Data))
+			}
+		}
+		if tt.policy != BadLineStop && fmt.Sprint(lines) != fmt.Sprint(tt.wantBad) {
+			t.Errorf("policy %d: bad lines = %v, want %v", tt.policy, lines, tt.wantBad)
+		}
+	}
+
+	// A line without end is given up on after MaxBytes
+	r := &endlessReader{}
+	nr := NewNDJSONReaderWithOptions(r, NDJSONOptions{Parse: ParseOptions{MaxBytes: 1 << 16}})
+	if nr.Next() || !errors.Is(nr.Err(), ErrMaxBytesExceeded) {
+		t.Errorf("Err() = %v, want ErrMaxBytesExceeded", nr.Err())
+	}
+	if r.read > 1<<17 {
+		t.Errorf("read %d bytes of an overlong line", r.read)
+	}
+}
+
+func TestNDJSONReaderErrorContext(t *testing.T) {
+	nr := NewNDJSONReader(strings.NewReader("{}\n{\"a\": 1, 2}\n"))
+	if nr.Next(); nr.Next() {
+		t.Fatal("Next() succeeded on a bad line")
+	}
+	err := nr.Err()
+	if want := "line 2: expected string key at column 10 (offset 9)"; err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %s", err, want)
+	}
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Snippet != "{\"a\": 1, 2}\n         ^" {
+		t.Errorf("Snippet = %q", syntaxErr.Snippet)
+	}
+
+	nr = NewNDJSONReaderWithOptions(strings.NewReader(`{"a": 1, "a": 2}`), NDJSONOptions{Parse: ParseOptions{DuplicateKeys: DuplicateKeyReject}})
+	nr.Next()
+	if want := `line 1: duplicate key "a" at column 10 (offset 9); first defined at column 2 (offset 1)`; nr.Err() == nil || nr.Err().Error() != want {
+		t.Errorf("Err() = %v, want %s", nr.Err(), want)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	obj := NewJsonObject()
+	_, _ = obj.Set("msg", NewJsonString("multi\nline"))
+	_, _ = obj.Set("n", NewJsonInt(1))
+
+	var buf bytes.Buffer
+	nw := NewNDJSONWriter(&buf)
+	for _, v := range []JsonValue{obj, NewJsonNull(), NewJsonArray()} {
+		if err := nw.WriteValue(v); err != nil {
+			t.Fatalf("WriteValue() error = %v", err)
+		}
+	}
+	if err := nw.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	want := "{\"msg\":\"multi\\nline\",\"n\":1}\nnull\n[]\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	values, err := NewNDJSONReader(&buf).ReadAll()
+	if err != nil || len(values) != 3 || !Equal(values[0], obj) {
+		t.Errorf("round trip = %v, %v", values, err)
+	}
+}
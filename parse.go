@@ -1,20 +1,132 @@
 package aaronjson
 
 import (
-	"fmt"
 	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
+// DefaultMaxDepth is the nesting depth limit applied when ParseOptions.MaxDepth is zero.
+const DefaultMaxDepth = 10000
+
+// DuplicateKeyPolicy selects how the parser handles a key that appears more
+// than once in the same object.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeyLastWins keeps the value of the last occurrence of the key.
+	DuplicateKeyLastWins DuplicateKeyPolicy = iota
+	// DuplicateKeyFirstWins keeps the value of the first occurrence of the key.
+	DuplicateKeyFirstWins
+	// DuplicateKeyReject fails the parse with a *DuplicateKeyError.
+	DuplicateKeyReject
+	// DuplicateKeyCollect stores every value of a repeated key, in input
+	// order, in a JsonArray. Keys that appear once keep their plain value.
+	DuplicateKeyCollect
+)
+
+// ParseOptions controls optional parser behavior.
+// The zero value matches the behavior of Parse and ParseByte.
+// Limits left at zero are not enforced, except for MaxDepth.
+type ParseOptions struct {
+	// UseNumber keeps every number as a JsonNumber holding its original
+	// lexeme instead of converting it to JsonInt or JsonFloat.
+	UseNumber bool
+	// DuplicateKeys selects how repeated object keys are handled.
+	// The default keeps the last value.
+	DuplicateKeys DuplicateKeyPolicy
+	// DisallowEmptyKeys rejects objects with an empty-string key such as {"": 1}.
+	// Empty keys are valid JSON and accepted by default.
+	DisallowEmptyKeys bool
+
+	// MaxDepth limits the nesting of arrays and objects.
+	// Zero means DefaultMaxDepth; a negative value disables the limit.
+	MaxDepth int
+	// MaxBytes limits the size of the input.
+	MaxBytes int
+	// MaxStringLength limits the decoded length in bytes of strings and object keys.
+	MaxStringLength int
+	// MaxObjectMembers limits the number of members in a single object.
+	MaxObjectMembers int
+	// MaxArrayElements limits the number of elements in a single array.
+	MaxArrayElements int
+}
+
+// maxDepth returns the effective nesting limit, or 0 if there is none.
+func (opts *ParseOptions) maxDepth() int {
+	switch {
+	case opts.MaxDepth == 0:
+		return DefaultMaxDepth
+	case opts.MaxDepth < 0:
+		return 0
+	default:
+		return opts.MaxDepth
+	}
+}
+
+// parser holds the options and nesting state for a single parse.
+type parser struct {
+	opts     ParseOptions
+	maxDepth int
+	depth    int
+}
+
+// newParser creates a parser for the given options.
+func newParser(opts ParseOptions) *parser {
+	return &parser{
+		opts:     opts,
+		maxDepth: opts.maxDepth(),
+	}
+}
+
+// checkSize enforces the MaxBytes limit on the whole input.
+func (p *parser) checkSize(data []byte) error {
+	if p.opts.MaxBytes > 0 && len(data) > p.opts.MaxBytes {
+		return newLimitError(ErrMaxBytesExceeded, p.opts.MaxBytes, p.opts.MaxBytes)
+	}
+	return nil
+}
+
+// enter records entering an array or object at pos and enforces the depth limit.
+// Every successful call must be paired with a call to leave.
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		p.depth--
+		return newLimitError(ErrMaxDepthExceeded, p.maxDepth, pos)
+	}
+	return nil
+}
+
+// leave records leaving an array or object.
+func (p *parser) leave() {
+	p.depth--
+}
+
 // ParseByte parses a JSON byte slice and returns the corresponding JsonValue.
+// The data must hold exactly one JSON value; only whitespace may follow it.
 // If parsing fails, it returns nil and an error.
 func ParseByte(jsonData []byte) (JsonValue, error) {
-	return parseJsonByte(jsonData)
+	return parseJsonByte(jsonData, ParseOptions{})
+}
+
+// ParseByteWithOptions parses a JSON byte slice using the given options.
+// If parsing fails, it returns nil and an error.
+func ParseByteWithOptions(jsonData []byte, opts ParseOptions) (JsonValue, error) {
+	return parseJsonByte(jsonData, opts)
+}
+
+// ParseWithOptions parses a JSON string using the given options.
+// If parsing fails, it returns nil and an error.
+func ParseWithOptions(jsonStr string, opts ParseOptions) (JsonValue, error) {
+	return parseJsonByte([]byte(jsonStr), opts)
 }
 
 // Parse parses a JSON string and returns the corresponding JsonValue.
+// The string must hold exactly one JSON value; only whitespace may follow it.
 // If parsing fails, it returns nil and an error.
 func Parse(jsonStr string) (JsonValue, error) {
-	data, err := parseJsonByte([]byte(jsonStr))
+	data, err := parseJsonByte([]byte(jsonStr), ParseOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -23,57 +135,133 @@ func Parse(jsonStr string) (JsonValue, error) {
 
 // parseJsonByte is the internal function that parses JSON byte data.
 // It returns the parsed JsonValue and any error encountered during parsing.
-func parseJsonByte(data []byte) (JsonValue, error) {
+func parseJsonByte(data []byte, opts ParseOptions) (JsonValue, error) {
+	value, err := newParser(opts).parseDocument(data)
+	if err != nil {
+		return nil, withErrorContext(err, data)
+	}
+	return value, nil
+}
+
+// parseDocument parses data holding exactly one JSON value.
+// Errors carry offsets into data but no line or column context.
+func (p *parser) parseDocument(data []byte) (JsonValue, error) {
+	if err := p.checkSize(data); err != nil {
+		return nil, err
+	}
 	pos := 0
 	pos = skipWhitespace(data, pos)
 
 	if pos >= len(data) {
-		return nil, fmt.Errorf("empty JSON data")
+		return nil, newSyntaxError(pos, "empty JSON data").withErr(ErrEmptyData)
+	}
+
+	value, pos, err := p.parseValue(data, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	pos = skipWhitespace(data, pos)
+	if pos < len(data) {
+		return nil, newSyntaxError(pos, "unexpected data after top-level value")
 	}
+	return value, nil
+}
 
-	value, _, err := parseValue(data, pos)
-	return value, err
+// ParseAll parses a stream of concatenated JSON values, such as `{"a":1} {"a":2}`,
+// and returns them in order. Values may be separated by whitespace; input
+// holding only whitespace yields no values.
+// If parsing fails, it returns nil and an error.
+func ParseAll(jsonData []byte) ([]JsonValue, error) {
+	return ParseAllWithOptions(jsonData, ParseOptions{})
+}
+
+// ParseAllWithOptions parses a stream of concatenated JSON values using the given options.
+// If parsing fails, it returns nil and an error.
+func ParseAllWithOptions(jsonData []byte, opts ParseOptions) ([]JsonValue, error) {
+	p := newParser(opts)
+	if err := p.checkSize(jsonData); err != nil {
+		return nil, err
+	}
+	values := make([]JsonValue, 0)
+	pos := skipWhitespace(jsonData, 0)
+	for pos < len(jsonData) {
+		value, newPos, err := p.parseValue(jsonData, pos)
+		if err != nil {
+			return nil, withErrorContext(err, jsonData)
+		}
+		values = append(values, value)
+		pos = skipWhitespace(jsonData, newPos)
+	}
+	return values, nil
+}
+
+// withErrorContext fills in the position details of a SyntaxError or
+// DuplicateKeyError from the parsed data.
+// Other errors are returned unchanged.
+func withErrorContext(err error, data []byte) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		return e.withContext(data)
+	case *DuplicateKeyError:
+		return e.withContext(data)
+	}
+	return err
 }
 
 // parseValue parses any JSON value starting at the given position.
 // It determines the type of JSON value and delegates to the appropriate parser.
 // Returns the parsed JsonValue, the new position, and any error.
-func parseValue(data []byte, pos int) (JsonValue, int, error) {
+func (p *parser) parseValue(data []byte, pos int) (JsonValue, int, error) {
 	pos = skipWhitespace(data, pos)
 
 	if pos >= len(data) {
-		return nil, pos, fmt.Errorf("unexpected end of data")
+		return nil, pos, newSyntaxError(pos, "unexpected end of data")
 	}
 
 	switch data[pos] {
 	case '{':
-		return parseObject(data, pos)
+		return p.parseObject(data, pos)
 	case '[':
-		return parseArray(data, pos)
+		return p.parseArray(data, pos)
 	case '"':
-		return parseString(data, pos)
+		return p.parseString(data, pos)
 	case 't', 'f':
 		return parseBool(data, pos)
 	case 'n':
 		return parseNull(data, pos)
 	default:
 		if (data[pos] >= '0' && data[pos] <= '9') || data[pos] == '-' || data[pos] == '+' {
-			return parseNumber(data, pos)
+			return p.parseNumber(data, pos)
 		}
-		return nil, pos, fmt.Errorf("invalid JSON data at position %d", pos)
+		return nil, pos, newSyntaxError(pos, "invalid character %s looking for beginning of value", quoteChar(data[pos]))
 	}
 }
 
 // parseObject parses a JSON object starting at the given position.
 // It expects the current character to be '{' and parses key-value pairs
 // until it finds the matching '}'. Returns the parsed object, new position, and any error.
-func parseObject(data []byte, pos int) (JsonValue, int, error) {
+func (p *parser) parseObject(data []byte, pos int) (JsonValue, int, error) {
 	if pos >= len(data) || data[pos] != '{' {
-		return nil, pos, fmt.Errorf("expected '{' at position %d", pos)
+		return nil, pos, newSyntaxError(pos, "expected '{'")
 	}
+	if err := p.enter(pos); err != nil {
+		return nil, pos, err
+	}
+	defer p.leave()
 	pos++ // Move past '{'
 
 	obj := NewJsonObject()
+	members := 0
+	// Offsets of the keys seen so far, tracked only when a policy needs them
+	var keyOffsets map[string]int
+	if p.opts.DuplicateKeys != DuplicateKeyLastWins {
+		keyOffsets = make(map[string]int)
+	}
+	var collected map[string]*JsonArray
+	if p.opts.DuplicateKeys == DuplicateKeyCollect {
+		collected = make(map[string]*JsonArray)
+	}
 	pos = skipWhitespace(data, pos)
 
 	// Check for empty object
@@ -86,15 +274,21 @@ func parseObject(data []byte, pos int) (JsonValue, int, error) {
 		pos = skipWhitespace(data, pos)
 
 		if pos >= len(data) {
-			return nil, pos, fmt.Errorf("unexpected end of data while parsing object")
+			return nil, pos, newSyntaxError(pos, "unexpected end of data while parsing object")
+		}
+
+		members++
+		if p.opts.MaxObjectMembers > 0 && members > p.opts.MaxObjectMembers {
+			return nil, pos, newLimitError(ErrMaxObjectMembersExceeded, p.opts.MaxObjectMembers, pos)
 		}
 
 		// Parse key
 		if data[pos] != '"' {
-			return nil, pos, fmt.Errorf("expected string key at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "expected string key")
 		}
 
-		keyData, newPos, err := parseString(data, pos)
+		keyPos := pos
+		keyData, newPos, err := p.parseString(data, pos)
 		if err != nil {
 			return nil, pos, err
 		}
@@ -103,33 +297,35 @@ func parseObject(data []byte, pos int) (JsonValue, int, error) {
 		// Get the raw string value for the key (not the JSON-formatted string)
 		key, err := keyData.AsString()
 		if err != nil {
-			return nil, pos, fmt.Errorf("failed to get string value for key: %v", err)
+			return nil, pos, newSyntaxError(pos, "failed to get string value for key").withErr(err)
 		}
-		if key == "" {
-			return nil, pos, fmt.Errorf("empty key at position %d", pos)
+		if key == "" && p.opts.DisallowEmptyKeys {
+			return nil, pos, newSyntaxError(pos, "empty key")
 		}
 
 		// Expect colon
 		pos = skipWhitespace(data, pos)
 		if pos >= len(data) || data[pos] != ':' {
-			return nil, pos, fmt.Errorf("expected ':' after key at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "expected ':' after key")
 		}
 		pos++ // Move past ':'
 
 		// Parse value
 		pos = skipWhitespace(data, pos)
-		value, newPos, err := parseValue(data, pos)
+		value, newPos, err := p.parseValue(data, pos)
 		if err != nil {
 			return nil, pos, err
 		}
 		pos = newPos
 
-		_, _ = obj.Set(key, value)
+		if err := p.setMember(obj, key, value, keyPos, keyOffsets, collected); err != nil {
+			return nil, pos, err
+		}
 
 		// Check for end or comma
 		pos = skipWhitespace(data, pos)
 		if pos >= len(data) {
-			return nil, pos, fmt.Errorf("unexpected end of data while parsing object")
+			return nil, pos, newSyntaxError(pos, "unexpected end of data while parsing object")
 		}
 
 		if data[pos] == '}' {
@@ -138,23 +334,63 @@ func parseObject(data []byte, pos int) (JsonValue, int, error) {
 		} else if data[pos] == ',' {
 			pos++
 		} else {
-			return nil, pos, fmt.Errorf("expected ',' or '}' at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "expected ',' or '}'")
 		}
 	}
 
 	return obj, pos, nil
 }
 
+// setMember stores a parsed member in obj according to the duplicate-key policy.
+// keyOffsets records where each key was first seen; collected tracks the
+// arrays created for repeated keys under DuplicateKeyCollect.
+func (p *parser) setMember(obj *JsonObject, key string, value JsonValue, keyPos int,
+	keyOffsets map[string]int, collected map[string]*JsonArray) error {
+	firstPos, seen := keyOffsets[key]
+	if !seen {
+		if keyOffsets != nil {
+			keyOffsets[key] = keyPos
+		}
+		_, _ = obj.Set(key, value)
+		return nil
+	}
+
+	switch p.opts.DuplicateKeys {
+	case DuplicateKeyFirstWins:
+		return nil
+	case DuplicateKeyReject:
+		return &DuplicateKeyError{Key: key, FirstOffset: firstPos, Offset: keyPos}
+	case DuplicateKeyCollect:
+		arr, ok := collected[key]
+		if !ok {
+			arr = NewJsonArray()
+			_, _ = arr.Append(obj.data[key])
+			collected[key] = arr
+			_, _ = obj.Set(key, arr)
+		}
+		_, _ = arr.Append(value)
+		return nil
+	default:
+		_, _ = obj.Set(key, value)
+		return nil
+	}
+}
+
 // parseArray parses a JSON array starting at the given position.
 // It expects the current character to be '[' and parses array elements
 // until it finds the matching ']'. Returns the parsed array, new position, and any error.
-func parseArray(data []byte, pos int) (JsonValue, int, error) {
+func (p *parser) parseArray(data []byte, pos int) (JsonValue, int, error) {
 	if pos >= len(data) || data[pos] != '[' {
-		return nil, pos, fmt.Errorf("expected '[' at position %d", pos)
+		return nil, pos, newSyntaxError(pos, "expected '['")
 	}
+	if err := p.enter(pos); err != nil {
+		return nil, pos, err
+	}
+	defer p.leave()
 	pos++ // Move past '['
 
 	arr := NewJsonArray()
+	elements := 0
 	pos = skipWhitespace(data, pos)
 
 	// Check for empty array
@@ -167,11 +403,16 @@ func parseArray(data []byte, pos int) (JsonValue, int, error) {
 		pos = skipWhitespace(data, pos)
 
 		if pos >= len(data) {
-			return nil, pos, fmt.Errorf("unexpected end of data while parsing array")
+			return nil, pos, newSyntaxError(pos, "unexpected end of data while parsing array")
+		}
+
+		elements++
+		if p.opts.MaxArrayElements > 0 && elements > p.opts.MaxArrayElements {
+			return nil, pos, newLimitError(ErrMaxArrayElementsExceeded, p.opts.MaxArrayElements, pos)
 		}
 
 		// Parse element
-		value, newPos, err := parseValue(data, pos)
+		value, newPos, err := p.parseValue(data, pos)
 		if err != nil {
 			return nil, pos, err
 		}
@@ -182,7 +423,7 @@ func parseArray(data []byte, pos int) (JsonValue, int, error) {
 		// Check for end or comma
 		pos = skipWhitespace(data, pos)
 		if pos >= len(data) {
-			return nil, pos, fmt.Errorf("unexpected end of data while parsing array")
+			return nil, pos, newSyntaxError(pos, "unexpected end of data while parsing array")
 		}
 
 		if data[pos] == ']' {
@@ -191,7 +432,7 @@ func parseArray(data []byte, pos int) (JsonValue, int, error) {
 		} else if data[pos] == ',' {
 			pos++
 		} else {
-			return nil, pos, fmt.Errorf("expected ',' or ']' at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "expected ',' or ']'")
 		}
 	}
 
@@ -199,42 +440,155 @@ func parseArray(data []byte, pos int) (JsonValue, int, error) {
 }
 
 // parseString parses a JSON string starting at the given position.
-// It expects the current character to be '"' and parses until the closing '"'.
+// It expects the current character to be '"' and parses until the closing '"',
+// decoding every escape sequence defined by RFC 8259, including \uXXXX escapes
+// and UTF-16 surrogate pairs. Unescaped control characters and unknown escapes
+// are rejected. Invalid UTF-8 bytes and lone surrogates decode to U+FFFD.
 // Returns the parsed string, new position, and any error.
-func parseString(data []byte, pos int) (JsonValue, int, error) {
+func (p *parser) parseString(data []byte, pos int) (JsonValue, int, error) {
 	if pos >= len(data) || data[pos] != '"' {
-		return nil, pos, fmt.Errorf("expected '\"' at position %d", pos)
+		return nil, pos, newSyntaxError(pos, "expected '\"'")
 	}
 	pos++ // Move past opening '"'
 
 	start := pos
+	// buf stays nil until the string needs decoding, so plain strings are
+	// copied straight out of the input.
+	var buf []byte
 	for pos < len(data) {
-		switch data[pos] {
-		case '"':
+		c := data[pos]
+		switch {
+		case c == '"':
 			// Found closing quote
-			str := string(data[start:pos])
+			length := pos - start
+			if buf != nil {
+				length = len(buf)
+			}
+			if p.opts.MaxStringLength > 0 && length > p.opts.MaxStringLength {
+				return nil, start - 1, newLimitError(ErrMaxStringLengthExceeded, p.opts.MaxStringLength, start-1)
+			}
+			var str string
+			if buf == nil {
+				str = string(data[start:pos])
+			} else {
+				str = string(buf)
+			}
 			pos++ // Move past closing '"'
 			return NewJsonString(str), pos, nil
-		case '\\':
-			// Handle escape sequences (simplified)
-			pos += 2
-		default:
+		case c == '\\':
+			if buf == nil {
+				buf = append(make([]byte, 0, pos-start+16), data[start:pos]...)
+			}
+			r, newPos, err := parseEscape(data, pos)
+			if err != nil {
+				return nil, newPos, err
+			}
+			buf = utf8.AppendRune(buf, r)
+			pos = newPos
+		case c < 0x20:
+			return nil, pos, newSyntaxError(pos, "invalid control character %#02x in string", c)
+		case c < utf8.RuneSelf:
+			if buf != nil {
+				buf = append(buf, c)
+			}
 			pos++
+		default:
+			r, size := utf8.DecodeRune(data[pos:])
+			if r == utf8.RuneError && size == 1 {
+				if buf == nil {
+					buf = append(make([]byte, 0, pos-start+16), data[start:pos]...)
+				}
+				buf = utf8.AppendRune(buf, utf8.RuneError)
+			} else if buf != nil {
+				buf = append(buf, data[pos:pos+size]...)
+			}
+			pos += size
 		}
 	}
 
-	return nil, pos, fmt.Errorf("unterminated string starting at position %d", start-1)
+	return nil, pos, newSyntaxError(start-1, "unterminated string")
 }
 
-// parseNumber parses a JSON number starting at the given position.
+// parseEscape decodes the escape sequence starting at the backslash at pos.
+// A high surrogate \uXXXX escape is combined with an immediately following
+// low surrogate escape; an unpaired surrogate decodes to U+FFFD.
+// Returns the decoded rune, the position after the sequence, and any error.
+func parseEscape(data []byte, pos int) (rune, int, error) {
+	if pos+1 >= len(data) {
+		return 0, pos, newSyntaxError(pos, "unterminated escape sequence")
+	}
+
+	switch data[pos+1] {
+	case '"':
+		return '"', pos + 2, nil
+	case '\\':
+		return '\\', pos + 2, nil
+	case '/':
+		return '/', pos + 2, nil
+	case 'b':
+		return '\b', pos + 2, nil
+	case 'f':
+		return '\f', pos + 2, nil
+	case 'n':
+		return '\n', pos + 2, nil
+	case 'r':
+		return '\r', pos + 2, nil
+	case 't':
+		return '\t', pos + 2, nil
+	case 'u':
+		r, ok := parseHex4(data, pos+2)
+		if !ok {
+			return 0, pos, newSyntaxError(pos, "invalid unicode escape")
+		}
+		newPos := pos + 6
+		if !utf16.IsSurrogate(r) {
+			return r, newPos, nil
+		}
+		// Try to pair a high surrogate with a following low surrogate
+		if newPos+1 < len(data) && data[newPos] == '\\' && data[newPos+1] == 'u' {
+			if r2, ok := parseHex4(data, newPos+2); ok {
+				if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+					return combined, newPos + 6, nil
+				}
+			}
+		}
+		return utf8.RuneError, newPos, nil
+	default:
+		return 0, pos, newSyntaxError(pos, "invalid escape character %s", quoteChar(data[pos+1]))
+	}
+}
+
+// parseHex4 decodes the four hexadecimal digits of a \uXXXX escape starting at pos.
+func parseHex4(data []byte, pos int) (rune, bool) {
+	if pos+4 > len(data) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range data[pos : pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// scanNumber checks the RFC 8259 number grammar starting at the given position.
 // It handles integers, floats, negative numbers, and scientific notation.
-// Returns the parsed number as JsonValue, new position, and any error.
-func parseNumber(data []byte, pos int) (JsonValue, int, error) {
+// Returns the position after the number, whether it has a fraction or exponent, and any error.
+func scanNumber(data []byte, pos int) (int, bool, error) {
 	start := pos
 
 	// Check for empty data
 	if pos >= len(data) {
-		return nil, pos, fmt.Errorf("unexpected end of data while parsing number")
+		return pos, false, newSyntaxError(pos, "unexpected end of data while parsing number")
 	}
 
 	// Handle optional minus sign
@@ -242,27 +596,26 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 	case '-':
 		pos++
 		if pos >= len(data) {
-			return nil, pos, fmt.Errorf("invalid number: minus sign without digits at position %d", start)
+			return pos, false, newSyntaxError(start, "invalid number: minus sign without digits")
 		}
 	case '+':
 		// JSON doesn't allow leading plus signs
-		return nil, pos, fmt.Errorf("invalid number: leading plus sign at position %d", start)
+		return pos, false, newSyntaxError(start, "invalid number: leading plus sign")
 	}
 
 	// Check if we have at least one digit
 	if pos >= len(data) || (data[pos] < '0' || data[pos] > '9') {
-		return nil, pos, fmt.Errorf("invalid number: no digits at position %d", start)
+		return pos, false, newSyntaxError(start, "invalid number: no digits")
 	}
 
 	isFloat := false
-	hasExponent := false
 
 	// Parse integer part
 	if data[pos] == '0' {
 		// Leading zero - next character must not be a digit (unless it's a decimal point or exponent)
 		pos++
 		if pos < len(data) && data[pos] >= '0' && data[pos] <= '9' {
-			return nil, pos, fmt.Errorf("invalid number: leading zero followed by digit at position %d", start)
+			return pos, false, newSyntaxError(start, "invalid number: leading zero followed by digit")
 		}
 	} else {
 		// Parse digits (1-9 followed by 0-9*)
@@ -278,7 +631,7 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 
 		// Must have at least one digit after decimal point
 		if pos >= len(data) || data[pos] < '0' || data[pos] > '9' {
-			return nil, pos, fmt.Errorf("invalid number: decimal point without fractional digits at position %d", start)
+			return pos, false, newSyntaxError(start, "invalid number: decimal point without fractional digits")
 		}
 
 		// Parse fractional digits
@@ -290,7 +643,6 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 	// Check for exponent
 	if pos < len(data) && (data[pos] == 'e' || data[pos] == 'E') {
 		isFloat = true
-		hasExponent = true
 		pos++
 
 		// Optional sign after exponent
@@ -300,7 +652,7 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 
 		// Must have at least one digit after exponent
 		if pos >= len(data) || data[pos] < '0' || data[pos] > '9' {
-			return nil, pos, fmt.Errorf("invalid number: exponent without digits at position %d", start)
+			return pos, false, newSyntaxError(start, "invalid number: exponent without digits")
 		}
 
 		// Parse exponent digits
@@ -309,6 +661,20 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 		}
 	}
 
+	return pos, isFloat, nil
+}
+
+// parseNumber parses a JSON number starting at the given position.
+// Integers become JsonInt and other numbers JsonFloat, unless the parser is
+// in UseNumber mode, in which case the original lexeme is kept in a JsonNumber.
+// Returns the parsed number as JsonValue, new position, and any error.
+func (p *parser) parseNumber(data []byte, pos int) (JsonValue, int, error) {
+	start := pos
+	pos, isFloat, err := scanNumber(data, pos)
+	if err != nil {
+		return nil, pos, err
+	}
+
 	// Extract the number string
 	numStr := string(data[start:pos])
 
@@ -316,16 +682,20 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 	if pos < len(data) {
 		c := data[pos]
 		if !isValidNumberTerminator(c) {
-			return nil, pos, fmt.Errorf("invalid character '%c' after number at position %d", c, pos)
+			return nil, pos, newSyntaxError(pos, "invalid character %s after number", quoteChar(c))
 		}
 	}
 
+	if p.opts.UseNumber {
+		return &JsonNumber{data: numStr}, pos, nil
+	}
+
 	// Parse the number based on type
-	if isFloat || hasExponent {
+	if isFloat {
 		// Parse as float
 		val, err := strconv.ParseFloat(numStr, 64)
 		if err != nil {
-			return nil, pos, fmt.Errorf("invalid float number '%s' at position %d: %v", numStr, start, err)
+			return nil, pos, newSyntaxError(start, "invalid float number '%s'", numStr).withErr(err)
 		}
 
 		// Check for overflow/underflow
@@ -339,19 +709,33 @@ func parseNumber(data []byte, pos int) (JsonValue, int, error) {
 				}
 			}
 			if hasNonZero {
-				return nil, pos, fmt.Errorf("number underflow: '%s' at position %d", numStr, start)
+				return nil, pos, newSyntaxError(start, "number underflow: '%s'", numStr)
 			}
 		}
 
 		return NewJsonFloat(val), pos, nil
 	} else {
-		// Parse as integer
-		val, err := strconv.ParseFloat(numStr, 64)
-		if err != nil {
-			return nil, pos, fmt.Errorf("invalid integer number '%s' at position %d: %v", numStr, start, err)
+		// Parse as integer, keeping the exact value whenever it fits in 64 bits
+		return parseInteger(numStr, start, pos)
+	}
+}
+
+// parseInteger converts an integer lexeme into a JsonInt without going through float64.
+// Values beyond the uint64 range fall back to a JsonFloat approximation.
+func parseInteger(numStr string, start, pos int) (JsonValue, int, error) {
+	if val, err := strconv.ParseInt(numStr, 10, 64); err == nil {
+		return NewJsonInt64(val), pos, nil
+	}
+	if numStr[0] != '-' {
+		if val, err := strconv.ParseUint(numStr, 10, 64); err == nil {
+			return NewJsonUint64(val), pos, nil
 		}
-		return NewJsonInt(val), pos, nil
 	}
+	val, err := strconv.ParseFloat(numStr, 64)
+	if err != nil {
+		return nil, pos, newSyntaxError(start, "invalid integer number '%s'", numStr).withErr(err)
+	}
+	return NewJsonFloat(val), pos, nil
 }
 
 func parseBool(data []byte, pos int) (JsonValue, int, error) {
@@ -359,18 +743,18 @@ func parseBool(data []byte, pos int) (JsonValue, int, error) {
 		newPos := pos + 4
 		// Check that we're at a valid word boundary
 		if newPos < len(data) && !isValidBoolTerminator(data[newPos]) {
-			return nil, pos, fmt.Errorf("invalid boolean value at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "invalid boolean value")
 		}
 		return NewJsonBool(true), newPos, nil
 	} else if pos+5 <= len(data) && string(data[pos:pos+5]) == "false" {
 		newPos := pos + 5
 		// Check that we're at a valid word boundary
 		if newPos < len(data) && !isValidBoolTerminator(data[newPos]) {
-			return nil, pos, fmt.Errorf("invalid boolean value at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "invalid boolean value")
 		}
 		return NewJsonBool(false), newPos, nil
 	}
-	return nil, pos, fmt.Errorf("invalid boolean value at position %d", pos)
+	return nil, pos, newSyntaxError(pos, "invalid boolean value")
 }
 
 func parseNull(data []byte, pos int) (JsonValue, int, error) {
@@ -378,9 +762,9 @@ func parseNull(data []byte, pos int) (JsonValue, int, error) {
 		newPos := pos + 4
 		// Check that we're at a valid word boundary
 		if newPos < len(data) && !isValidNullTerminator(data[newPos]) {
-			return nil, pos, fmt.Errorf("invalid null value at position %d", pos)
+			return nil, pos, newSyntaxError(pos, "invalid null value")
 		}
 		return NewJsonNull(), newPos, nil
 	}
-	return nil, pos, fmt.Errorf("invalid null value at position %d", pos)
+	return nil, pos, newSyntaxError(pos, "invalid null value")
 }
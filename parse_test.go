@@ -1,6 +1,8 @@
 package aaronjson
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -14,19 +16,19 @@ func TestParse(t *testing.T) {
 		{
 			name:    "parse simple string",
 			jsonStr: `"hello"`,
-			want:    "hello",
+			want:    `"hello"`,
 			wantErr: false,
 		},
 		{
 			name:    "parse integer",
 			jsonStr: `42`,
-			want:    "42.000000",
+			want:    "42",
 			wantErr: false,
 		},
 		{
 			name:    "parse float",
 			jsonStr: `3.14`,
-			want:    "3.140000",
+			want:    "3.14",
 			wantErr: false,
 		},
 		{
@@ -62,31 +64,31 @@ func TestParse(t *testing.T) {
 		{
 			name:    "parse simple object",
 			jsonStr: `{"name": "John", "age": 30}`,
-			want:    `{"age": 30.000000, "name": "John"}`,
+			want:    `{"name":"John","age":30}`,
 			wantErr: false,
 		},
 		{
 			name:    "parse simple array",
 			jsonStr: `[1, 2, 3]`,
-			want:    "[1.000000, 2.000000, 3.000000]",
+			want:    "[1,2,3]",
 			wantErr: false,
 		},
 		{
 			name:    "parse nested object",
 			jsonStr: `{"person": {"name": "John", "age": 30}, "city": "New York"}`,
-			want:    `{"city": "New York", "person": {"age": 30.000000, "name": "John"}}`,
+			want:    `{"person":{"name":"John","age":30},"city":"New York"}`,
 			wantErr: false,
 		},
 		{
 			name:    "parse array with mixed types",
 			jsonStr: `[1, "hello", true, null]`,
-			want:    `[1.000000, "hello", true, null]`,
+			want:    `[1,"hello",true,null]`,
 			wantErr: false,
 		},
 		{
 			name:    "parse with whitespace",
 			jsonStr: ` { "key" : "value" } `,
-			want:    `{"key": "value"}`,
+			want:    `{"key":"value"}`,
 			wantErr: false,
 		},
 		{
@@ -135,7 +137,7 @@ func TestParseByte(t *testing.T) {
 		{
 			name:     "parse byte slice",
 			jsonData: []byte(`{"key": "value"}`),
-			want:     `{"key": "value"}`,
+			want:     `{"key":"value"}`,
 			wantErr:  false,
 		},
 		{
@@ -200,11 +202,6 @@ func TestParseObject(t *testing.T) {
 			jsonStr: `{"key1": "value1" "key2": "value2"}`,
 			wantErr: true,
 		},
-		{
-			name:    "empty key",
-			jsonStr: `{"": "value"}`,
-			wantErr: true,
-		},
 	}
 
 	for _, tt := range tests {
@@ -287,9 +284,64 @@ func TestParseString(t *testing.T) {
 		{
 			name:    "string with escape",
 			jsonStr: `"hello\nworld"`,
-			want:    `hello\nworld`,
+			want:    "hello\nworld",
+			wantErr: false,
+		},
+		{
+			name:    "all simple escapes",
+			jsonStr: `"\"\\\/\b\f\n\r\t"`,
+			want:    "\"\\/\b\f\n\r\t",
+			wantErr: false,
+		},
+		{
+			name:    "unicode escape",
+			jsonStr: `"caf\u00e9"`,
+			want:    "café",
 			wantErr: false,
 		},
+		{
+			name:    "surrogate pair",
+			jsonStr: `"\ud83d\ude00"`,
+			want:    "😀",
+			wantErr: false,
+		},
+		{
+			name:    "lone surrogate",
+			jsonStr: `"\ud83dx"`,
+			want:    "\ufffdx",
+			wantErr: false,
+		},
+		{
+			name:    "raw utf-8",
+			jsonStr: `"日本語"`,
+			want:    "日本語",
+			wantErr: false,
+		},
+		{
+			name:    "invalid escape",
+			jsonStr: `"hello\qworld"`,
+			wantErr: true,
+		},
+		{
+			name:    "short unicode escape",
+			jsonStr: `"\u12"`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid unicode escape",
+			jsonStr: `"\u12zz"`,
+			wantErr: true,
+		},
+		{
+			name:    "unescaped control character",
+			jsonStr: "\"hello\nworld\"",
+			wantErr: true,
+		},
+		{
+			name:    "trailing backslash",
+			jsonStr: `"hello\`,
+			wantErr: true,
+		},
 		{
 			name:    "unterminated string",
 			jsonStr: `"hello`,
@@ -304,8 +356,36 @@ func TestParseString(t *testing.T) {
 				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr && got.String() != tt.want {
-				t.Errorf("Parse() = %v, want %v", got.String(), tt.want)
+			if tt.wantErr {
+				return
+			}
+			str, _ := got.AsString()
+			if str != tt.want {
+				t.Errorf("Parse() = %q, want %q", str, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain",
+		"line1\nline2\r\n",
+		"tab\tquote\"backslash\\",
+		"control\x00\x01\x1f",
+		"café 日本語 😀",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			got, err := Parse(NewJsonString(input).PrettyString())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			str, _ := got.AsString()
+			if str != input {
+				t.Errorf("round trip = %q, want %q", str, input)
 			}
 		})
 	}
@@ -457,3 +537,303 @@ func TestParseNull(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTrailingData(t *testing.T) {
+	tests := []struct {
+		name    string
+		jsonStr string
+		wantErr bool
+	}{
+		{
+			name:    "trailing whitespace",
+			jsonStr: "{} \n\t",
+			wantErr: false,
+		},
+		{
+			name:    "trailing garbage after object",
+			jsonStr: `{} xyz`,
+			wantErr: true,
+		},
+		{
+			name:    "two numbers",
+			jsonStr: `1 2`,
+			wantErr: true,
+		},
+		{
+			name:    "two objects",
+			jsonStr: `{"a": 1}{"b": 2}`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing closing bracket",
+			jsonStr: `[1]]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.jsonStr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, err = ParseByte([]byte(tt.jsonStr))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseByte() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "concatenated objects",
+			input: `{"a":1}{"a":2}`,
+			want:  []string{`{"a":1}`, `{"a":2}`},
+		},
+		{
+			name:  "whitespace separated scalars",
+			input: "1 \"two\"\ntrue null",
+			want:  []string{"1", `"two"`, "true", "null"},
+		},
+		{
+			name:  "only whitespace",
+			input: "  \n ",
+			want:  []string{},
+		},
+		{
+			name:    "invalid value",
+			input:   `{"a":1} {`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAll([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseAll() returned %d values, want %d", len(got), len(tt.want))
+			}
+			for i, value := range got {
+				if value.String() != tt.want[i] {
+					t.Errorf("ParseAll()[%d] = %v, want %v", i, value.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		wantErr error
+	}{
+		{
+			name:    "default depth limit",
+			input:   strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1),
+			wantErr: ErrMaxDepthExceeded,
+		},
+		{
+			name:  "depth within limit",
+			input: `[[{"a": [1]}]]`,
+			opts:  ParseOptions{MaxDepth: 4},
+		},
+		{
+			name:    "depth over limit",
+			input:   `[[{"a": [1]}]]`,
+			opts:    ParseOptions{MaxDepth: 3},
+			wantErr: ErrMaxDepthExceeded,
+		},
+		{
+			name:  "depth limit disabled",
+			input: strings.Repeat("[", DefaultMaxDepth+1) + strings.Repeat("]", DefaultMaxDepth+1),
+			opts:  ParseOptions{MaxDepth: -1},
+		},
+		{
+			name:    "input too large",
+			input:   `{"a": "bcdef"}`,
+			opts:    ParseOptions{MaxBytes: 10},
+			wantErr: ErrMaxBytesExceeded,
+		},
+		{
+			name:    "string too long",
+			input:   `["abcdef"]`,
+			opts:    ParseOptions{MaxStringLength: 5},
+			wantErr: ErrMaxStringLengthExceeded,
+		},
+		{
+			name:    "key too long",
+			input:   `{"abcdef": 1}`,
+			opts:    ParseOptions{MaxStringLength: 5},
+			wantErr: ErrMaxStringLengthExceeded,
+		},
+		{
+			name:  "decoded string length",
+			input: `["ABC"]`,
+			opts:  ParseOptions{MaxStringLength: 3},
+		},
+		{
+			name:    "too many object members",
+			input:   `{"a": 1, "b": 2, "c": 3}`,
+			opts:    ParseOptions{MaxObjectMembers: 2},
+			wantErr: ErrMaxObjectMembersExceeded,
+		},
+		{
+			name:    "too many array elements",
+			input:   `[1, 2, 3]`,
+			opts:    ParseOptions{MaxArrayElements: 2},
+			wantErr: ErrMaxArrayElementsExceeded,
+		},
+		{
+			name:  "counts within limits",
+			input: `{"a": [1, 2], "b": [3, 4]}`,
+			opts:  ParseOptions{MaxObjectMembers: 2, MaxArrayElements: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithOptions(tt.input, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ParseWithOptions() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWithOptions() error = %v, want %v", err, tt.wantErr)
+			}
+			var le *LimitError
+			if !errors.As(err, &le) {
+				t.Errorf("errors.As(err, *LimitError) = false for %v", err)
+			}
+		})
+	}
+}
+
+func TestParseDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": 2, "a": 3, "a": [4]}`
+	tests := []struct {
+		name   string
+		policy DuplicateKeyPolicy
+		want   string
+	}{
+		{
+			name:   "last wins",
+			policy: DuplicateKeyLastWins,
+			want:   `{"a":[4],"b":2}`,
+		},
+		{
+			name:   "first wins",
+			policy: DuplicateKeyFirstWins,
+			want:   `{"a":1,"b":2}`,
+		},
+		{
+			name:   "collect",
+			policy: DuplicateKeyCollect,
+			want:   `{"a":[1,3,[4]],"b":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWithOptions(input, ParseOptions{DuplicateKeys: tt.policy})
+			if err != nil {
+				t.Fatalf("ParseWithOptions() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseWithOptions() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("collect keeps single keys plain", func(t *testing.T) {
+		got, err := ParseWithOptions(`{"a": [1], "b": {"a": 2, "a": 3}}`, ParseOptions{DuplicateKeys: DuplicateKeyCollect})
+		if err != nil {
+			t.Fatalf("ParseWithOptions() error = %v", err)
+		}
+		if want := `{"a":[1],"b":{"a":[2,3]}}`; got.String() != want {
+			t.Errorf("ParseWithOptions() = %v, want %v", got.String(), want)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := ParseWithOptions("{\"a\": 1,\n \"a\": 2}", ParseOptions{DuplicateKeys: DuplicateKeyReject})
+		if !errors.Is(err, ErrDuplicateKey) {
+			t.Fatalf("ParseWithOptions() error = %v, want ErrDuplicateKey", err)
+		}
+		var de *DuplicateKeyError
+		if !errors.As(err, &de) {
+			t.Fatalf("errors.As(err, *DuplicateKeyError) = false for %v", err)
+		}
+		if de.Key != "a" || de.FirstOffset != 1 || de.Offset != 10 {
+			t.Errorf("DuplicateKeyError = %+v, want key a at offsets 1 and 10", de)
+		}
+		if de.FirstLine != 1 || de.FirstColumn != 2 || de.Line != 2 || de.Column != 2 {
+			t.Errorf("DuplicateKeyError positions = %d:%d and %d:%d, want 1:2 and 2:2",
+				de.FirstLine, de.FirstColumn, de.Line, de.Column)
+		}
+	})
+
+	t.Run("reject allows same key in different objects", func(t *testing.T) {
+		_, err := ParseWithOptions(`[{"a": 1}, {"a": 2}]`, ParseOptions{DuplicateKeys: DuplicateKeyReject})
+		if err != nil {
+			t.Errorf("ParseWithOptions() error = %v", err)
+		}
+	})
+}
+
+func TestParseEmptyKeys(t *testing.T) {
+	input := `{"": 1, "nested": {"": {"": "deep"}}}`
+
+	parsed, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := parsed.String(); got != `{"":1,"nested":{"":{"":"deep"}}}` {
+		t.Errorf("String() = %v", got)
+	}
+
+	value, err := parsed.Get("")
+	if err != nil {
+		t.Fatalf("Get(\"\") error = %v", err)
+	}
+	if i, _ := value.AsInt(); i != 1 {
+		t.Errorf("Get(\"\") = %v, want 1", value)
+	}
+
+	deep, err := parsed.Get("nested", "", "")
+	if err != nil {
+		t.Fatalf("Get(nested, \"\", \"\") error = %v", err)
+	}
+	if s, _ := deep.AsString(); s != "deep" {
+		t.Errorf("Get(nested, \"\", \"\") = %v, want deep", deep)
+	}
+
+	var m map[string]interface{}
+	if err := parsed.Unmarshal(&m); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if m[""] != 1 {
+		t.Errorf("m[\"\"] = %v, want 1", m[""])
+	}
+
+	_, err = ParseWithOptions(input, ParseOptions{DisallowEmptyKeys: true})
+	if !errors.Is(err, ErrInvalidJsonFormat) {
+		t.Errorf("ParseWithOptions(DisallowEmptyKeys) error = %v, want ErrInvalidJsonFormat", err)
+	}
+}
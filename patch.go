@@ -0,0 +1,415 @@
+package aaronjson
+
+import (
+	"fmt"
+)
+
+// PatchOperation is one operation of a JSON Patch (RFC 6902) document.
+type PatchOperation struct {
+	Op    string    // "add", "remove", "replace", "move", "copy" or "test"
+	Path  Pointer   // the location the operation applies to
+	From  Pointer   // the source location of "move" and "copy"
+	Value JsonValue // the value of "add", "replace" and "test"
+}
+
+// Patch is a JSON Patch (RFC 6902): a sequence of operations applied in order.
+type Patch []PatchOperation
+
+// PatchError reports the operation of a patch that is malformed or could not
+// be applied. Err is ErrInvalidPatch for a malformed operation,
+// ErrPatchTestFailed for a failed "test", or the pointer error that stopped
+// the operation, such as ErrKeyNotFound or ErrIndexOutOfBounds.
+type PatchError struct {
+	Index int    // position of the operation in the patch
+	Op    string // the operation's "op" member
+	Err   error  // why the operation failed
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json patch operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePatch parses a JSON Patch document such as
+// [{"op": "replace", "path": "/a", "value": 1}].
+func ParsePatch(data []byte) (Patch, error) {
+	v, err := ParseByte(data)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePatch(v)
+}
+
+// DecodePatch converts a parsed JSON Patch document into a Patch, checking
+// that every operation has the members its "op" requires. Members that the
+// RFC does not define are ignored.
+func DecodePatch(v JsonValue) (Patch, error) {
+	arr, ok := v.(*JsonArray)
+	if !ok {
+		return nil, fmt.Errorf("%w: document must be an array of operations", ErrInvalidPatch)
+	}
+	patch := make(Patch, len(arr.data))
+	for i, item := range arr.data {
+		op, err := decodePatchOperation(item)
+		if err != nil {
+			opName := ""
+			if obj, ok := item.(*JsonObject); ok {
+				if name, ok := obj.data["op"].(*JsonString); ok {
+					opName = name.data
+				}
+			}
+			return nil, &PatchError{Index: i, Op: opName, Err: err}
+		}
+		patch[i] = op
+	}
+	return patch, nil
+}
+
+// decodePatchOperation converts one member of a JSON Patch document.
+func decodePatchOperation(v JsonValue) (PatchOperation, error) {
+	var op PatchOperation
+	obj, ok := v.(*JsonObject)
+	if !ok {
+		return op, fmt.Errorf("%w: operation must be an object", ErrInvalidPatch)
+	}
+	name, ok := obj.data["op"].(*JsonString)
+	if !ok {
+		return op, fmt.Errorf("%w: missing or non-string \"op\"", ErrInvalidPatch)
+	}
+	op.Op = name.data
+
+	pointer := func(member string) (Pointer, error) {
+		s, ok := obj.data[member].(*JsonString)
+		if !ok {
+			return nil, fmt.Errorf("%w: missing or non-string %q", ErrInvalidPatch, member)
+		}
+		ptr, err := ParsePointer(s.data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidPatch, member, err)
+		}
+		return ptr, nil
+	}
+	var err error
+	if op.Path, err = pointer("path"); err != nil {
+		return op, err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value, ok = obj.data["value"]; !ok {
+			return op, fmt.Errorf("%w: missing \"value\"", ErrInvalidPatch)
+		}
+	case "move", "copy":
+		if op.From, err = pointer("from"); err != nil {
+			return op, err
+		}
+	case "remove":
+	default:
+		return op, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+	return op, nil
+}
+
+// Apply applies the operations of the patch in order to a copy of doc and
+// returns the result. The patch is atomic: if any operation fails, Apply
+// returns a *PatchError and no result. doc itself is never modified, and the
+// result shares no arrays or objects with doc or the patch.
+func (patch Patch) Apply(doc JsonValue) (JsonValue, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("cannot apply a json patch to a nil document")
+	}
+	result := cloneValue(doc)
+	for i, op := range patch {
+		var err error
+		if result, err = op.apply(result); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Err: err}
+		}
+	}
+	return result, nil
+}
+
+// apply performs the operation on root, which it may modify, and returns the
+// resulting document.
+func (op PatchOperation) apply(root JsonValue) (JsonValue, error) {
+	switch op.Op {
+	case "add":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: missing \"value\"", ErrInvalidPatch)
+		}
+		return patchAdd(root, op.Path, cloneValue(op.Value))
+	case "remove":
+		if _, err := op.Path.Delete(root); err != nil {
+			return nil, err
+		}
+		return root, nil
+	case "replace":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: missing \"value\"", ErrInvalidPatch)
+		}
+		// The target must exist, so "-" and missing members are rejected
+		if _, err := op.Path.Get(root); err != nil {
+			return nil, err
+		}
+		return op.Path.Set(root, cloneValue(op.Value))
+	case "move":
+		value, err := op.From.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		if pointerHasPrefix(op.Path, op.From) {
+			if len(op.Path) == len(op.From) {
+				return root, nil
+			}
+			return nil, fmt.Errorf("%w: cannot move %q into its own child %q", ErrInvalidPatch, op.From.String(), op.Path.String())
+		}
+		if _, err := op.From.Delete(root); err != nil {
+			return nil, err
+		}
+		return patchAdd(root, op.Path, value)
+	case "copy":
+		value, err := op.From.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(root, op.Path, cloneValue(value))
+	case "test":
+		value, err := op.Path.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		if op.Value == nil || !Equal(value, op.Value) {
+			return nil, fmt.Errorf("%w: value at %q is %s", ErrPatchTestFailed, op.Path.String(), value.String())
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// patchAdd implements "add": object members are added or replaced, and
+// values are inserted into arrays before the given index, which may equal
+// the array's length; "-" appends.
+func patchAdd(root JsonValue, path Pointer, value JsonValue) (JsonValue, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := path[:len(path)-1].Get(root)
+	if err != nil {
+		return nil, err
+	}
+	container, ok := parent.(*JsonArray)
+	if !ok {
+		return path.Set(root, value)
+	}
+	token := path[len(path)-1]
+	index := len(container.data)
+	if token != "-" {
+		if index, err = pointerIndex(token, len(container.data)+1, path); err != nil {
+			return nil, err
+		}
+	}
+	container.data = append(container.data, nil)
+	copy(container.data[index+1:], container.data[index:])
+	container.data[index] = value
+	return root, nil
+}
+
+// pointerHasPrefix reports whether prefix is ptr or one of its ancestors.
+func pointerHasPrefix(ptr, prefix Pointer) bool {
+	if len(prefix) > len(ptr) {
+		return false
+	}
+	for i, token := range prefix {
+		if ptr[i] != token {
+			return false
+		}
+	}
+	return true
+}
+
+// ToJsonValue returns the patch as a JSON Patch document.
+func (patch Patch) ToJsonValue() *JsonArray {
+	arr := NewJsonArray()
+	for _, op := range patch {
+		obj := NewJsonObject()
+		_, _ = obj.Set("op", NewJsonString(op.Op))
+		if op.Op == "move" || op.Op == "copy" {
+			_, _ = obj.Set("from", NewJsonString(op.From.String()))
+		}
+		_, _ = obj.Set("path", NewJsonString(op.Path.String()))
+		if op.Value != nil {
+			_, _ = obj.Set("value", op.Value)
+		}
+		_, _ = arr.Append(obj)
+	}
+	return arr
+}
+
+// String returns the patch as a compact JSON Patch document.
+func (patch Patch) String() string {
+	return patch.ToJsonValue().String()
+}
+
+// MarshalJSON returns the patch as a compact JSON Patch document.
+func (patch Patch) MarshalJSON() ([]byte, error) {
+	return Compact(patch.ToJsonValue()), nil
+}
+
+// CreatePatch returns a patch that turns original into modified. Unchanged
+// members and array elements are left alone: objects are compared member
+// by member, arrays element by element after aligning their longest common
+// subsequence, and only the values that differ are added, removed or
+// replaced. Values in the patch are copies, so later changes to modified do
+// not affect it.
+func CreatePatch(original, modified JsonValue) (Patch, error) {
+	if original == nil || modified == nil {
+		return nil, fmt.Errorf("cannot create a json patch from a nil document")
+	}
+	var patch Patch
+	diffValues(&patch, Pointer{}, original, modified)
+	return patch, nil
+}
+
+// diffValues appends to patch the operations that turn a into b at path.
+func diffValues(patch *Patch, path Pointer, a, b JsonValue) {
+	switch av := a.(type) {
+	case *JsonObject:
+		if bv, ok := b.(*JsonObject); ok {
+			diffObjects(patch, path, av, bv)
+			return
+		}
+	case *JsonArray:
+		if bv, ok := b.(*JsonArray); ok {
+			diffArrays(patch, path, av, bv)
+			return
+		}
+	}
+	if !Equal(a, b) {
+		*patch = append(*patch, PatchOperation{Op: "replace", Path: path, Value: cloneValue(b)})
+	}
+}
+
+// diffObjects removes the members missing from b, updates the members both
+// objects hold and adds the members only b holds, in b's key order.
+func diffObjects(patch *Patch, path Pointer, a, b *JsonObject) {
+	for _, key := range a.keys {
+		if _, ok := b.data[key]; !ok {
+			*patch = append(*patch, PatchOperation{Op: "remove", Path: childPointer(path, key)})
+		}
+	}
+	for _, key := range b.keys {
+		if av, ok := a.data[key]; ok {
+			diffValues(patch, childPointer(path, key), av, b.data[key])
+		}
+	}
+	for _, key := range b.keys {
+		if _, ok := a.data[key]; !ok {
+			*patch = append(*patch, PatchOperation{Op: "add", Path: childPointer(path, key), Value: cloneValue(b.data[key])})
+		}
+	}
+}
+
+// maxDiffCells bounds the size of the table used to align two arrays; larger
+// arrays are aligned only by their common prefix and suffix.
+const maxDiffCells = 1 << 20
+
+// diffArrays aligns the elements of a and b on their longest common
+// subsequence. Between aligned elements, elements removed and inserted at the
+// same place are paired up and diffed, and the rest are removed or added.
+func diffArrays(patch *Patch, path Pointer, a, b *JsonArray) {
+	x, y := a.data, b.data
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && Equal(x[prefix], y[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && Equal(x[len(x)-1-suffix], y[len(y)-1-suffix]) {
+		suffix++
+	}
+	x, y = x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	var lcs [][]int
+	if (len(x)+1)*(len(y)+1) <= maxDiffCells {
+		lcs = make([][]int, len(x)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(y)+1)
+		}
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				if Equal(x[i], y[j]) {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+	}
+
+	// index is the position in the array as patched so far
+	index := prefix
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		if i < len(x) && j < len(y) && lcs != nil && Equal(x[i], y[j]) {
+			i, j, index = i+1, j+1, index+1
+			continue
+		}
+		// Collect the run of removals and insertions up to the next aligned pair
+		di, dj := i, j
+		for di < len(x) || dj < len(y) {
+			if di < len(x) && dj < len(y) && lcs != nil && Equal(x[di], y[dj]) {
+				break
+			}
+			if dj == len(y) || (di < len(x) && (lcs == nil || lcs[di+1][dj] >= lcs[di][dj+1])) {
+				di++
+			} else {
+				dj++
+			}
+		}
+		for i < di && j < dj {
+			diffValues(patch, childPointer(path, fmt.Sprint(index)), x[i], y[j])
+			i, j, index = i+1, j+1, index+1
+		}
+		for ; i < di; i++ {
+			*patch = append(*patch, PatchOperation{Op: "remove", Path: childPointer(path, fmt.Sprint(index))})
+		}
+		for ; j < dj; j++ {
+			*patch = append(*patch, PatchOperation{Op: "add", Path: childPointer(path, fmt.Sprint(index)), Value: cloneValue(y[j])})
+			index++
+		}
+	}
+}
+
+// childPointer returns a new pointer to the member token of the value at ptr.
+func childPointer(ptr Pointer, token string) Pointer {
+	child := make(Pointer, len(ptr)+1)
+	copy(child, ptr)
+	child[len(ptr)] = token
+	return child
+}
+
+// cloneValue returns a deep copy of the arrays and objects in v. Other
+// values are immutable and shared.
+func cloneValue(v JsonValue) JsonValue {
+	switch jv := v.(type) {
+	case *JsonObject:
+		obj := &JsonObject{
+			data: make(map[string]JsonValue, len(jv.data)),
+			keys: append(make([]string, 0, len(jv.keys)), jv.keys...),
+		}
+		for key, value := range jv.data {
+			obj.data[key] = cloneValue(value)
+		}
+		return obj
+	case *JsonArray:
+		arr := &JsonArray{data: make([]JsonValue, len(jv.data))}
+		for i, value := range jv.data {
+			arr.data[i] = cloneValue(value)
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,228 @@
+package aaronjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPatchApply(t *testing.T) {
+	// Mostly the examples of RFC 6902, Appendix A
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{name: "add member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"foo":"bar","baz":"qux"}`},
+		{name: "add element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, want: `{"foo":["bar","qux","baz"]}`},
+		{name: "add at end", doc: `[1,2]`, patch: `[{"op":"add","path":"/2","value":3},{"op":"add","path":"/-","value":4}]`, want: `[1,2,3,4]`},
+		{name: "add replaces member", doc: `{"a":1,"b":2}`, patch: `[{"op":"add","path":"/a","value":[]}]`, want: `{"a":[],"b":2}`},
+		{name: "add nested", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, want: `{"foo":"bar","child":{"grandchild":{}}}`},
+		{name: "add root", doc: `{"a":1}`, patch: `[{"op":"add","path":"","value":[1]}]`, want: `[1]`},
+		{name: "add array value", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, want: `{"foo":["bar",["abc","def"]]}`},
+		{name: "remove member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, want: `{"foo":"bar"}`},
+		{name: "remove element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, want: `{"foo":["bar","baz"]}`},
+		{name: "replace", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, want: `{"baz":"boo","foo":"bar"}`},
+		{name: "replace root", doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":null}]`, want: `null`},
+		{name: "move member", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, want: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, want: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "move to itself", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a"}]`, want: `{"a":{"b":1}}`},
+		{name: "move to parent", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a/b","path":"/a"}]`, want: `{"a":1}`},
+		{name: "copy", doc: `{"a":{"b":[1]}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`, want: `{"a":{"b":[1]},"c":{"b":[1,2]}}`},
+		{name: "test", doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, want: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "test object ignores order", doc: `{"a":{"x":1,"y":2}}`, patch: `[{"op":"test","path":"/a","value":{"y":2,"x":1}}]`, want: `{"a":{"x":1,"y":2}}`},
+		{name: "escaped keys", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, want: `{"~1":10}`},
+		{name: "extra members ignored", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, want: `{"foo":"bar","baz":"qux"}`},
+		{name: "empty patch", doc: `{"a":1}`, patch: `[]`, want: `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := ParsePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParsePatch error: %v", err)
+			}
+			doc := mustParse(t, tt.doc)
+			got, err := patch.Apply(doc)
+			if err != nil {
+				t.Fatalf("Apply error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Apply = %s, want %s", got.String(), tt.want)
+			}
+			if doc.String() != mustParse(t, tt.doc).String() {
+				t.Errorf("Apply modified the document: %s", doc.String())
+			}
+		})
+	}
+}
+
+func TestPatchApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		index int
+		want  error
+	}{
+		{name: "test fails", doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, index: 0, want: ErrPatchTestFailed},
+		{name: "test type", doc: `{"a":"1"}`, patch: `[{"op":"test","path":"/a","value":1}]`, index: 0, want: ErrPatchTestFailed},
+		{name: "add to missing parent", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, index: 0, want: ErrKeyNotFound},
+		{name: "add past end", doc: `[1]`, patch: `[{"op":"add","path":"/2","value":3}]`, index: 0, want: ErrIndexOutOfBounds},
+		{name: "remove missing", doc: `{"a":1}`, patch: `[{"op":"remove","path":"/b"}]`, index: 0, want: ErrKeyNotFound},
+		{name: "replace missing", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":1},{"op":"replace","path":"/b","value":2}]`, index: 1, want: ErrKeyNotFound},
+		{name: "replace end of array", doc: `[1]`, patch: `[{"op":"replace","path":"/-","value":2}]`, index: 0, want: ErrIndexOutOfBounds},
+		{name: "move missing", doc: `{"a":1}`, patch: `[{"op":"move","from":"/b","path":"/c"}]`, index: 0, want: ErrKeyNotFound},
+		{name: "move into child", doc: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, index: 0, want: ErrInvalidPatch},
+		{name: "copy missing", doc: `[]`, patch: `[{"op":"copy","from":"/0","path":"/1"}]`, index: 0, want: ErrIndexOutOfBounds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := ParsePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParsePatch error: %v", err)
+			}
+			doc := mustParse(t, tt.doc)
+			got, err := patch.Apply(doc)
+			if got != nil {
+				t.Errorf("Apply returned %s on error", got.String())
+			}
+			var pe *PatchError
+			if !errors.As(err, &pe) || pe.Index != tt.index || !errors.Is(err, tt.want) {
+				t.Errorf("Apply error = %v, want a PatchError at operation %d matching %v", err, tt.index, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchApplyIsAtomic(t *testing.T) {
+	doc := mustParse(t, `{"a":[1,2],"b":{"c":1}}`)
+	patch, err := ParsePatch([]byte(`[
+		{"op":"remove","path":"/a/0"},
+		{"op":"add","path":"/b/d","value":2},
+		{"op":"replace","path":"","value":{}},
+		{"op":"test","path":"/x","value":1}
+	]`))
+	if err != nil {
+		t.Fatalf("ParsePatch error: %v", err)
+	}
+	if _, err := patch.Apply(doc); err == nil {
+		t.Fatal("expected the final test to fail")
+	}
+	if doc.String() != `{"a":[1,2],"b":{"c":1}}` {
+		t.Errorf("failed patch modified the document: %s", doc.String())
+	}
+
+	// The result must not share containers with the patch values
+	patch, _ = ParsePatch([]byte(`[{"op":"add","path":"/x","value":{"y":[]}}]`))
+	first, _ := patch.Apply(doc)
+	second, _ := patch.Apply(doc)
+	if _, err := (Pointer{"x", "y", "-"}).Set(first, NewJsonInt64(1)); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if second.String() != `{"a":[1,2],"b":{"c":1},"x":{"y":[]}}` || patch.String() != `[{"op":"add","path":"/x","value":{"y":[]}}]` {
+		t.Errorf("results share values: %s, patch %s", second.String(), patch.String())
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	tests := []string{
+		`{"op":"add"}`,
+		`[1]`,
+		`[{"path":"/a"}]`,
+		`[{"op":"jump","path":"/a"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"remove"}]`,
+		`[{"op":"remove","path":"a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"copy","from":1,"path":"/a"}]`,
+	}
+
+	for _, input := range tests {
+		if _, err := ParsePatch([]byte(input)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("ParsePatch(%s) error = %v, want ErrInvalidPatch", input, err)
+		}
+	}
+	if _, err := ParsePatch([]byte(`[`)); !errors.Is(err, ErrInvalidJsonFormat) {
+		t.Errorf("ParsePatch([) error = %v, want ErrInvalidJsonFormat", err)
+	}
+}
+
+func TestPatchString(t *testing.T) {
+	input := `[{"op":"add","path":"/a~1b","value":[1]},{"op":"remove","path":"/c/0"},{"op":"move","from":"/d","path":"/e"},{"op":"test","path":"","value":null}]`
+	patch, err := ParsePatch([]byte(input))
+	if err != nil {
+		t.Fatalf("ParsePatch error: %v", err)
+	}
+	if patch.String() != input {
+		t.Errorf("String = %s, want %s", patch.String(), input)
+	}
+	data, err := patch.MarshalJSON()
+	if err != nil || string(data) != input {
+		t.Errorf("MarshalJSON = %s, %v", data, err)
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+		want     string
+	}{
+		{original: `{"a":1}`, modified: `{"a":1}`, want: `[]`},
+		{original: `{"a":1,"b":2}`, modified: `{"a":1,"b":3,"c":4}`, want: `[{"op":"replace","path":"/b","value":3},{"op":"add","path":"/c","value":4}]`},
+		{original: `{"a":{"x":1,"y":2}}`, modified: `{"a":{"x":1}}`, want: `[{"op":"remove","path":"/a/y"}]`},
+		{original: `{"a":1}`, modified: `[1]`, want: `[{"op":"replace","path":"","value":[1]}]`},
+		{original: `{"a/b":1}`, modified: `{"a/b":2}`, want: `[{"op":"replace","path":"/a~1b","value":2}]`},
+		{original: `[1,2,3]`, modified: `[1,3]`, want: `[{"op":"remove","path":"/1"}]`},
+		{original: `[1,3]`, modified: `[1,2,3]`, want: `[{"op":"add","path":"/1","value":2}]`},
+		{original: `[1,2,3]`, modified: `[1,2,3,4]`, want: `[{"op":"add","path":"/3","value":4}]`},
+		{original: `[1,2,3]`, modified: `[0,2,4]`, want: `[{"op":"replace","path":"/0","value":0},{"op":"replace","path":"/2","value":4}]`},
+		{original: `[{"id":1,"n":"a"},{"id":2,"n":"b"}]`, modified: `[{"id":1,"n":"a"},{"id":2,"n":"c"}]`, want: `[{"op":"replace","path":"/1/n","value":"c"}]`},
+		{original: `["a","b","c","d"]`, modified: `["b","x","d","e"]`, want: `[{"op":"remove","path":"/0"},{"op":"replace","path":"/1","value":"x"},{"op":"add","path":"/3","value":"e"}]`},
+		{original: `[1.0]`, modified: `[1]`, want: `[]`},
+	}
+
+	for _, tt := range tests {
+		original := mustParse(t, tt.original)
+		modified := mustParse(t, tt.modified)
+		patch, err := CreatePatch(original, modified)
+		if err != nil {
+			t.Fatalf("CreatePatch(%s, %s) error: %v", tt.original, tt.modified, err)
+		}
+		if patch.String() != tt.want {
+			t.Errorf("CreatePatch(%s, %s) = %s, want %s", tt.original, tt.modified, patch.String(), tt.want)
+		}
+		got, err := patch.Apply(original)
+		if err != nil || !Equal(got, modified) {
+			t.Errorf("applying CreatePatch(%s, %s) = %v, %v", tt.original, tt.modified, got, err)
+		}
+	}
+}
+
+func TestCreatePatchRoundTrip(t *testing.T) {
+	pairs := [][2]string{
+		{`{"users":[{"name":"a","tags":["x","y"]},{"name":"b"}],"n":1}`, `{"users":[{"name":"b","tags":[]},{"name":"c","tags":["y","z"]}],"m":[null]}`},
+		{`[[1,2],[3,4],[5]]`, `[[3,4],[1],[5,6],[7]]`},
+		{`[1,2,3,4,5,6,7,8,9]`, `[9,8,7,6,5,4,3,2,1]`},
+		{`{"a":[{"b":[1,{"c":2}]}]}`, `{"a":[{"b":[{"c":3},1]}],"d":{}}`},
+		{`"x"`, `{"x":"x"}`},
+	}
+	for _, pair := range pairs {
+		original := mustParse(t, pair[0])
+		modified := mustParse(t, pair[1])
+		patch, err := CreatePatch(original, modified)
+		if err != nil {
+			t.Fatalf("CreatePatch error: %v", err)
+		}
+		got, err := patch.Apply(original)
+		if err != nil {
+			t.Errorf("applying %s to %s: %v", patch.String(), pair[0], err)
+			continue
+		}
+		if !Equal(got, modified) {
+			t.Errorf("applying %s to %s = %s, want %s", patch.String(), pair[0], got.String(), pair[1])
+		}
+	}
+}
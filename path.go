@@ -0,0 +1,300 @@
+package aaronjson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Path addresses a value inside a document as a sequence of segments:
+// a string selects an object member and an int selects an array element.
+type Path []interface{}
+
+// PathError reports the path segment at which navigation failed.
+// Err is ErrKeyNotFound, ErrIndexOutOfBounds or ErrPathMismatch.
+type PathError struct {
+	Path    Path  // the full path being navigated
+	Segment int   // index of the failing segment in Path
+	Err     error // why the segment could not be resolved
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("path %s: segment %d (%s): %v", e.Path, e.Segment, formatSegment(e.Path[e.Segment]), e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// String formats the path with keys as ".key" (or ["key"] when needed) and
+// indices as "[i]", e.g. users[0].name.
+func (path Path) String() string {
+	buf := make([]byte, 0, 16)
+	for i, segment := range path {
+		switch s := segment.(type) {
+		case string:
+			if isIdentifier(s) {
+				if i > 0 {
+					buf = append(buf, '.')
+				}
+				buf = append(buf, s...)
+			} else {
+				buf = append(buf, '[')
+				buf = appendQuotedString(buf, s)
+				buf = append(buf, ']')
+			}
+		case int:
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(s), 10)
+			buf = append(buf, ']')
+		default:
+			buf = append(buf, fmt.Sprintf("[%v]", segment)...)
+		}
+	}
+	if len(buf) == 0 {
+		return "$"
+	}
+	return string(buf)
+}
+
+// formatSegment formats a single segment for an error message.
+func formatSegment(segment interface{}) string {
+	if s, ok := segment.(string); ok {
+		return string(appendQuotedString(nil, s))
+	}
+	return fmt.Sprint(segment)
+}
+
+// isIdentifier reports whether s can be written after a '.' in a formatted path.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value the path addresses within root.
+func (path Path) Get(root JsonValue) (JsonValue, error) {
+	current := root
+	for i := range path {
+		child, err := path.child(current, i)
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// child resolves segment i of the path against v.
+func (path Path) child(v JsonValue, i int) (JsonValue, error) {
+	switch segment := path[i].(type) {
+	case string:
+		obj, ok := v.(*JsonObject)
+		if !ok {
+			return nil, path.mismatch(i, v, "object")
+		}
+		child, exists := obj.data[segment]
+		if !exists {
+			return nil, &PathError{Path: path, Segment: i, Err: ErrKeyNotFound}
+		}
+		return child, nil
+	case int:
+		arr, ok := v.(*JsonArray)
+		if !ok {
+			return nil, path.mismatch(i, v, "array")
+		}
+		if segment < 0 || segment >= len(arr.data) {
+			return nil, &PathError{Path: path, Segment: i, Err: ErrIndexOutOfBounds}
+		}
+		return arr.data[segment], nil
+	default:
+		return nil, &PathError{Path: path, Segment: i,
+			Err: fmt.Errorf("%w: segment must be a string or int, got %T", ErrPathMismatch, segment)}
+	}
+}
+
+// mismatch reports that segment i expected a container of kind want but found v.
+func (path Path) mismatch(i int, v JsonValue, want string) error {
+	return &PathError{Path: path, Segment: i,
+		Err: fmt.Errorf("%w: expected %s, found %s", ErrPathMismatch, want, describeValue(v))}
+}
+
+// describeValue names the type of v for error messages.
+func describeValue(v JsonValue) string {
+	switch {
+	case v == nil:
+		return "nothing"
+	case v.IsObject():
+		return "object"
+	case v.IsArray():
+		return "array"
+	case v.IsString():
+		return "string"
+	case v.IsBool():
+		return "bool"
+	case v.IsNull():
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// GetPath returns the value addressed by a path of object keys and array
+// indices within root, e.g. GetPath(doc, "users", 0, "name").
+func GetPath(root JsonValue, path ...interface{}) (JsonValue, error) {
+	return Path(path).Get(root)
+}
+
+// SetPathOptions controls how SetPath treats missing and non-container values
+// along the path. The zero value creates and replaces as needed.
+type SetPathOptions struct {
+	// DisallowCreate fails with ErrKeyNotFound or ErrIndexOutOfBounds when an
+	// intermediate container is missing, instead of creating an object (for a
+	// string segment) or an array (for an int segment). It also stops arrays
+	// from being padded with nulls, whatever MaxPadding says.
+	DisallowCreate bool
+	// DisallowReplace fails with ErrPathMismatch when an intermediate value is
+	// not a container, instead of replacing it with a new one.
+	DisallowReplace bool
+	// MaxPadding is the largest number of nulls an array may be padded with to
+	// reach an index beyond its end. Zero, the default, only allows an index
+	// up to the length of the array, so that a path taken from untrusted input
+	// cannot make SetPath allocate an arbitrarily large array.
+	MaxPadding int
+}
+
+// Set stores value at the location the path addresses within root, creating
+// missing intermediate containers. An index equal to the length of an array
+// appends to it; a larger index fails with ErrIndexOutOfBounds unless
+// opts.MaxPadding allows padding the array with nulls to reach it. Containers
+// of the wrong kind are never replaced.
+func (path Path) Set(root JsonValue, value JsonValue, opts SetPathOptions) error {
+	if value == nil {
+		return fmt.Errorf("cannot set nil value at path %s", path)
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("cannot set a value at the empty path")
+	}
+
+	padding := opts.MaxPadding
+	if opts.DisallowCreate || padding < 0 {
+		padding = 0
+	}
+
+	current := root
+	if err := path.checkContainer(current, 0); err != nil {
+		return err
+	}
+	last := len(path) - 1
+	for i := 0; i < last; i++ {
+		child, err := path.child(current, i)
+		var pe *PathError
+		switch {
+		case err == nil && path.checkContainer(child, i+1) == nil:
+			// The container already exists
+			current = child
+			continue
+		case err == nil:
+			// A value of the wrong kind is in the way
+			if child.IsObject() || child.IsArray() || opts.DisallowReplace {
+				return path.checkContainer(child, i+1)
+			}
+		case errors.As(err, &pe) && pe.Err != ErrPathMismatch:
+			// The key or index is missing
+			if opts.DisallowCreate {
+				return err
+			}
+		default:
+			return err
+		}
+
+		// Create a container matching the next segment in place of the child,
+		// once the rest of the path is known to fit in new containers
+		if err := path.checkNew(i+1, padding); err != nil {
+			return err
+		}
+		var container JsonValue = NewJsonObject()
+		if _, ok := path[i+1].(int); ok {
+			container = NewJsonArray()
+		}
+		if err := path.store(current, i, container, padding); err != nil {
+			return err
+		}
+		current = container
+	}
+	return path.store(current, last, value, padding)
+}
+
+// checkNew reports whether the segments of the path from i on can be stored
+// in newly created, empty containers.
+func (path Path) checkNew(i int, padding int) error {
+	for ; i < len(path); i++ {
+		switch segment := path[i].(type) {
+		case string:
+		case int:
+			if segment < 0 || segment > padding {
+				return &PathError{Path: path, Segment: i, Err: ErrIndexOutOfBounds}
+			}
+		default:
+			return &PathError{Path: path, Segment: i,
+				Err: fmt.Errorf("%w: segment must be a string or int, got %T", ErrPathMismatch, segment)}
+		}
+	}
+	return nil
+}
+
+// checkContainer reports whether v can be navigated by segment i of the path.
+func (path Path) checkContainer(v JsonValue, i int) error {
+	switch path[i].(type) {
+	case string:
+		if _, ok := v.(*JsonObject); !ok {
+			return path.mismatch(i, v, "object")
+		}
+	case int:
+		if _, ok := v.(*JsonArray); !ok {
+			return path.mismatch(i, v, "array")
+		}
+	default:
+		return &PathError{Path: path, Segment: i,
+			Err: fmt.Errorf("%w: segment must be a string or int, got %T", ErrPathMismatch, path[i])}
+	}
+	return nil
+}
+
+// store sets the member of container named by segment i of the path to value.
+// An array is padded with up to padding nulls to reach the index.
+func (path Path) store(container JsonValue, i int, value JsonValue, padding int) error {
+	switch segment := path[i].(type) {
+	case string:
+		obj := container.(*JsonObject)
+		_, _ = obj.Set(segment, value)
+	case int:
+		arr := container.(*JsonArray)
+		if segment < 0 || segment-len(arr.data) > padding {
+			return &PathError{Path: path, Segment: i, Err: ErrIndexOutOfBounds}
+		}
+		for len(arr.data) < segment {
+			arr.data = append(arr.data, NewJsonNull())
+		}
+		if segment == len(arr.data) {
+			arr.data = append(arr.data, value)
+		} else {
+			arr.data[segment] = value
+		}
+	}
+	return nil
+}
+
+// SetPath stores value at the location a path of object keys and array
+// indices addresses within root, creating missing intermediate containers.
+// See Path.Set for details.
+func SetPath(root JsonValue, path Path, value JsonValue) error {
+	return path.Set(root, value, SetPathOptions{})
+}
@@ -0,0 +1,260 @@
+package aaronjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+	doc, err := Parse(`{"users": [{"name": "Ann", "tags": ["a", "b"]}, {"name": "Bob"}], "a.b": {"": 1}, "n": 5}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	obj, _ := doc.AsObject()
+
+	tests := []struct {
+		name        string
+		path        []interface{}
+		want        string
+		wantErr     error
+		wantSegment int
+	}{
+		{name: "empty path", path: nil, want: doc.String()},
+		{name: "key then index", path: []interface{}{"users", 1, "name"}, want: `"Bob"`},
+		{name: "nested arrays", path: []interface{}{"users", 0, "tags", 1}, want: `"b"`},
+		{name: "unusual keys", path: []interface{}{"a.b", ""}, want: "1"},
+		{name: "missing key", path: []interface{}{"users", 1, "age"}, wantErr: ErrKeyNotFound, wantSegment: 2},
+		{name: "index out of range", path: []interface{}{"users", 2}, wantErr: ErrIndexOutOfBounds, wantSegment: 1},
+		{name: "negative index", path: []interface{}{"users", -1}, wantErr: ErrIndexOutOfBounds, wantSegment: 1},
+		{name: "index into object", path: []interface{}{"users", 0, 0}, wantErr: ErrPathMismatch, wantSegment: 2},
+		{name: "key into array", path: []interface{}{"users", "0"}, wantErr: ErrPathMismatch, wantSegment: 1},
+		{name: "key into number", path: []interface{}{"n", "x"}, wantErr: ErrPathMismatch, wantSegment: 1},
+		{name: "unsupported segment", path: []interface{}{"users", 1.5}, wantErr: ErrPathMismatch, wantSegment: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := obj.GetPath(tt.path...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetPath() error = %v, want %v", err, tt.wantErr)
+				}
+				var pe *PathError
+				if !errors.As(err, &pe) || pe.Segment != tt.wantSegment {
+					t.Errorf("GetPath() error = %v, want failure at segment %d", err, tt.wantSegment)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPath() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("GetPath() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+
+	arr, _ := Parse(`[[1, {"x": true}]]`)
+	if got, err := GetPath(arr, 0, 1, "x"); err != nil || got.String() != "true" {
+		t.Errorf("GetPath() on array root = %v, %v, want true", got, err)
+	}
+}
+
+func TestPathString(t *testing.T) {
+	tests := []struct {
+		path Path
+		want string
+	}{
+		{path: Path{}, want: "$"},
+		{path: Path{"users", 0, "name"}, want: "users[0].name"},
+		{path: Path{0, "a_1"}, want: "[0].a_1"},
+		{path: Path{"a.b", "", "1x"}, want: `["a.b"][""]["1x"]`},
+	}
+	for _, tt := range tests {
+		if got := tt.path.String(); got != tt.want {
+			t.Errorf("Path(%#v).String() = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	err := &PathError{Path: Path{"users", 3, "name"}, Segment: 1, Err: ErrIndexOutOfBounds}
+	if want := "path users[3].name: segment 1 (3): index out of bounds"; err.Error() != want {
+		t.Errorf("PathError.Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	obj := NewJsonObject()
+	steps := []struct {
+		path  Path
+		value JsonValue
+	}{
+		{path: Path{"server", "http", "port"}, value: NewJsonInt(8080)},
+		{path: Path{"server", "http", "host"}, value: NewJsonString("localhost")},
+		{path: Path{"server", "routes", 0, "path"}, value: NewJsonString("/")},
+		{path: Path{"server", "routes", 1}, value: NewJsonString("last")},
+		{path: Path{"matrix", 0, 0}, value: NewJsonInt(1)},
+		{path: Path{"server", "http", "port"}, value: NewJsonInt(9090)},
+	}
+	for _, step := range steps {
+		if err := obj.SetPath(step.path, step.value); err != nil {
+			t.Fatalf("SetPath(%v) error = %v", step.path, err)
+		}
+	}
+
+	want := `{"server":{"http":{"port":9090,"host":"localhost"},"routes":[{"path":"/"},"last"]},"matrix":[[1]]}`
+	if obj.String() != want {
+		t.Errorf("after SetPath() = %v, want %v", obj.String(), want)
+	}
+
+	var pe *PathError
+	if err := SetPath(nil, Path{"a"}, NewJsonInt(1)); !errors.As(err, &pe) || !errors.Is(err, ErrPathMismatch) {
+		t.Errorf("SetPath() on nil root error = %v, want a PathError", err)
+	}
+
+	arr := NewJsonArray()
+	if err := arr.SetPath(Path{0, "name"}, NewJsonString("x")); err != nil {
+		t.Fatalf("SetPath() on array error = %v", err)
+	}
+	if arr.String() != `[{"name":"x"}]` {
+		t.Errorf("array after SetPath() = %v", arr.String())
+	}
+}
+
+func TestSetPathOptions(t *testing.T) {
+	base := `{"a": {"list": [1]}, "n": 5, "nil": null}`
+	tests := []struct {
+		name        string
+		path        Path
+		opts        SetPathOptions
+		want        string
+		wantErr     error
+		wantSegment int
+	}{
+		{
+			name: "replace scalar by default",
+			path: Path{"n", "x"},
+			want: `{"a":{"list":[1]},"n":{"x":true},"nil":null}`,
+		},
+		{
+			name: "replace null by default",
+			path: Path{"nil", 0},
+			want: `{"a":{"list":[1]},"n":5,"nil":[true]}`,
+		},
+		{
+			name:        "refuse to replace scalar",
+			path:        Path{"n", "x"},
+			opts:        SetPathOptions{DisallowReplace: true},
+			wantErr:     ErrPathMismatch,
+			wantSegment: 1,
+		},
+		{
+			name:        "never replace container of the wrong kind",
+			path:        Path{"a", 0},
+			wantErr:     ErrPathMismatch,
+			wantSegment: 1,
+		},
+		{
+			name:        "refuse to create",
+			path:        Path{"b", "c"},
+			opts:        SetPathOptions{DisallowCreate: true},
+			wantErr:     ErrKeyNotFound,
+			wantSegment: 0,
+		},
+		{
+			name: "existing containers without create",
+			path: Path{"a", "list", 1},
+			opts: SetPathOptions{DisallowCreate: true},
+			want: `{"a":{"list":[1,true]},"n":5,"nil":null}`,
+		},
+		{
+			name:        "refuse to pad array",
+			path:        Path{"a", "list", 3},
+			opts:        SetPathOptions{DisallowCreate: true},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name:        "refuse to pad array by default",
+			path:        Path{"a", "list", 2},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name: "pad array",
+			path: Path{"a", "list", 3},
+			opts: SetPathOptions{MaxPadding: 2},
+			want: `{"a":{"list":[1,null,null,true]},"n":5,"nil":null}`,
+		},
+		{
+			name:        "pad array beyond limit",
+			path:        Path{"a", "list", 1 << 40},
+			opts:        SetPathOptions{MaxPadding: 2},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name: "pad new arrays",
+			path: Path{"b", 1, 1},
+			opts: SetPathOptions{MaxPadding: 1},
+			want: `{"a":{"list":[1]},"n":5,"nil":null,"b":[null,[null,true]]}`,
+		},
+		{
+			name:        "new array out of bounds",
+			path:        Path{"b", "c", 1},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name:        "refuse to pad array without create",
+			path:        Path{"a", "list", 2},
+			opts:        SetPathOptions{DisallowCreate: true, MaxPadding: 5},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name:        "negative index",
+			path:        Path{"a", "list", -1},
+			wantErr:     ErrIndexOutOfBounds,
+			wantSegment: 2,
+		},
+		{
+			name:        "root of the wrong kind",
+			path:        Path{0},
+			wantErr:     ErrPathMismatch,
+			wantSegment: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := Parse(base)
+			obj, _ := doc.AsObject()
+			err := obj.SetPathWithOptions(tt.path, NewJsonBool(true), tt.opts)
+			if tt.wantErr != nil {
+				var pe *PathError
+				if !errors.Is(err, tt.wantErr) || !errors.As(err, &pe) || pe.Segment != tt.wantSegment {
+					t.Fatalf("SetPathWithOptions() error = %v, want %v at segment %d", err, tt.wantErr, tt.wantSegment)
+				}
+				if !Equal(obj, mustParse(t, base)) {
+					t.Errorf("document changed on error: %v", obj.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetPathWithOptions() error = %v", err)
+			}
+			if obj.String() != tt.want {
+				t.Errorf("after SetPathWithOptions() = %v, want %v", obj.String(), tt.want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, s string) JsonValue {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", s, err)
+	}
+	return v
+}
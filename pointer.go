@@ -0,0 +1,175 @@
+package aaronjson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a parsed JSON Pointer (RFC 6901) such as /users/3/name,
+// held as its unescaped reference tokens. The empty Pointer refers to the
+// whole document.
+type Pointer []string
+
+// ParsePointer parses the string form of a JSON Pointer, unescaping
+// "~1" to "/" and "~0" to "~" in each reference token.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("%w %q: must be empty or start with '/'", ErrInvalidPointer, s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("%w %q: '~' must be followed by '0' or '1'", ErrInvalidPointer, s)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return Pointer(tokens), nil
+}
+
+// String returns the string form of the pointer, escaping "~" as "~0" and "/" as "~1".
+func (ptr Pointer) String() string {
+	var sb strings.Builder
+	for _, token := range ptr {
+		sb.WriteByte('/')
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// Get returns the value the pointer refers to within root, which must not
+// be nil.
+func (ptr Pointer) Get(root JsonValue) (JsonValue, error) {
+	if root == nil {
+		return nil, fmt.Errorf("cannot resolve json pointer %q in a nil document", ptr.String())
+	}
+	current := root
+	for i, token := range ptr {
+		child, err := pointerChild(current, token, ptr[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// Set stores value at the location the pointer refers to within root and
+// returns the resulting document. Object members are added or replaced;
+// array elements must exist, except that the token "-" appends to the array.
+// Setting the empty pointer replaces the whole document with value.
+func (ptr Pointer) Set(root JsonValue, value JsonValue) (JsonValue, error) {
+	if root == nil {
+		return nil, fmt.Errorf("cannot set json pointer %q in a nil document", ptr.String())
+	}
+	if value == nil {
+		return nil, fmt.Errorf("cannot set nil value at json pointer %q", ptr.String())
+	}
+	if len(ptr) == 0 {
+		return value, nil
+	}
+
+	parent, err := ptr[:len(ptr)-1].Get(root)
+	if err != nil {
+		return nil, err
+	}
+	token := ptr[len(ptr)-1]
+	switch container := parent.(type) {
+	case *JsonObject:
+		_, _ = container.Set(token, value)
+	case *JsonArray:
+		if token == "-" {
+			_, _ = container.Append(value)
+			break
+		}
+		index, err := pointerIndex(token, len(container.data), ptr)
+		if err != nil {
+			return nil, err
+		}
+		container.data[index] = value
+	default:
+		return nil, fmt.Errorf("json pointer %q: cannot set a member of %s", ptr.String(), describeValue(parent))
+	}
+	return root, nil
+}
+
+// Delete removes the value the pointer refers to within root and returns it.
+// The empty pointer cannot be deleted.
+func (ptr Pointer) Delete(root JsonValue) (JsonValue, error) {
+	if root == nil {
+		return nil, fmt.Errorf("cannot delete json pointer %q from a nil document", ptr.String())
+	}
+	if len(ptr) == 0 {
+		return nil, fmt.Errorf("cannot delete the whole document")
+	}
+
+	parent, err := ptr[:len(ptr)-1].Get(root)
+	if err != nil {
+		return nil, err
+	}
+	token := ptr[len(ptr)-1]
+	switch container := parent.(type) {
+	case *JsonObject:
+		value, exists := container.data[token]
+		if !exists {
+			return nil, fmt.Errorf("json pointer %q: %w", ptr.String(), ErrKeyNotFound)
+		}
+		_, _ = container.Remove(token)
+		return value, nil
+	case *JsonArray:
+		index, err := pointerIndex(token, len(container.data), ptr)
+		if err != nil {
+			return nil, err
+		}
+		return container.RemoveByIndex(index)
+	default:
+		return nil, fmt.Errorf("json pointer %q: cannot delete a member of %s", ptr.String(), describeValue(parent))
+	}
+}
+
+// pointerChild returns the member of v named by token; at is the pointer up to
+// and including token, used in error messages.
+func pointerChild(v JsonValue, token string, at Pointer) (JsonValue, error) {
+	switch container := v.(type) {
+	case *JsonObject:
+		child, exists := container.data[token]
+		if !exists {
+			return nil, fmt.Errorf("json pointer %q: %w", at.String(), ErrKeyNotFound)
+		}
+		return child, nil
+	case *JsonArray:
+		index, err := pointerIndex(token, len(container.data), at)
+		if err != nil {
+			return nil, err
+		}
+		return container.data[index], nil
+	default:
+		return nil, fmt.Errorf("json pointer %q: cannot descend into %s", at.String(), describeValue(v))
+	}
+}
+
+// pointerIndex converts an array reference token into an index below length.
+// RFC 6901 allows only digits without leading zeros.
+func pointerIndex(token string, length int, at Pointer) (int, error) {
+	if token == "-" {
+		// "-" names the nonexistent element after the last one
+		return 0, fmt.Errorf("json pointer %q: %w", at.String(), ErrIndexOutOfBounds)
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("json pointer %q: invalid array index %q", at.String(), token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= length {
+		return 0, fmt.Errorf("json pointer %q: %w", at.String(), ErrIndexOutOfBounds)
+	}
+	return index, nil
+}
@@ -0,0 +1,228 @@
+package aaronjson
+
+import (
+	"errors"
+	"testing"
+)
+
+// rfc6901Document is the example document from RFC 6901, section 5.
+const rfc6901Document = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    Pointer
+		wantErr bool
+	}{
+		{pointer: "", want: Pointer{}},
+		{pointer: "/", want: Pointer{""}},
+		{pointer: "/foo/0", want: Pointer{"foo", "0"}},
+		{pointer: "/a~1b", want: Pointer{"a/b"}},
+		{pointer: "/m~0n", want: Pointer{"m~n"}},
+		{pointer: "/~01", want: Pointer{"~1"}},
+		{pointer: "foo", wantErr: true},
+		{pointer: "/a~2b", wantErr: true},
+		{pointer: "/a~", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got, err := ParsePointer(tt.pointer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePointer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPointer) {
+					t.Errorf("ParsePointer() error = %v, want ErrInvalidPointer", err)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParsePointer() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParsePointer() = %q, want %q", got, tt.want)
+				}
+			}
+			if got.String() != tt.pointer {
+				t.Errorf("String() = %q, want %q", got.String(), tt.pointer)
+			}
+		})
+	}
+}
+
+func TestGetPointer(t *testing.T) {
+	doc, err := Parse(rfc6901Document)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	obj, _ := doc.AsObject()
+
+	tests := []struct {
+		pointer string
+		want    string
+		wantErr error
+	}{
+		{pointer: "", want: doc.String()},
+		{pointer: "/foo", want: `["bar","baz"]`},
+		{pointer: "/foo/0", want: `"bar"`},
+		{pointer: "/", want: "0"},
+		{pointer: "/a~1b", want: "1"},
+		{pointer: "/c%d", want: "2"},
+		{pointer: "/e^f", want: "3"},
+		{pointer: "/g|h", want: "4"},
+		{pointer: "/i\\j", want: "5"},
+		{pointer: "/k\"l", want: "6"},
+		{pointer: "/ ", want: "7"},
+		{pointer: "/m~0n", want: "8"},
+		{pointer: "/missing", wantErr: ErrKeyNotFound},
+		{pointer: "/foo/2", wantErr: ErrIndexOutOfBounds},
+		{pointer: "/foo/-", wantErr: ErrIndexOutOfBounds},
+		{pointer: "/foo/01", wantErr: errors.New("invalid array index")},
+		{pointer: "/foo/0/x", wantErr: errors.New("cannot descend")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got, err := obj.GetPointer(tt.pointer)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("GetPointer() = %v, want error", got)
+				}
+				if errors.Is(tt.wantErr, ErrKeyNotFound) || errors.Is(tt.wantErr, ErrIndexOutOfBounds) {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("GetPointer() error = %v, want %v", err, tt.wantErr)
+					}
+				} else if !contains(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("GetPointer() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPointer() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("GetPointer() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	doc, _ := Parse(`{"users": [{"name": "Ann"}, {"name": "Bob"}]}`)
+	obj, _ := doc.AsObject()
+
+	steps := []struct {
+		pointer string
+		value   JsonValue
+		wantErr bool
+	}{
+		{pointer: "/users/1/name", value: NewJsonString("Bea")},
+		{pointer: "/users/0/age", value: NewJsonInt(30)},
+		{pointer: "/users/-", value: NewJsonString("Cy")},
+		{pointer: "/a~1b", value: NewJsonBool(true)},
+		{pointer: "/users/9", value: NewJsonNull(), wantErr: true},
+		{pointer: "/missing/x", value: NewJsonNull(), wantErr: true},
+		{pointer: "", value: NewJsonNull(), wantErr: true},
+	}
+	for _, step := range steps {
+		if err := obj.SetPointer(step.pointer, step.value); (err != nil) != step.wantErr {
+			t.Errorf("SetPointer(%q) error = %v, wantErr %v", step.pointer, err, step.wantErr)
+		}
+	}
+
+	want := `{"users":[{"name":"Ann","age":30},{"name":"Bea"},"Cy"],"a/b":true}`
+	if obj.String() != want {
+		t.Errorf("after SetPointer() = %v, want %v", obj.String(), want)
+	}
+
+	root, err := Pointer{}.Set(obj, NewJsonInt(1))
+	if err != nil || root.String() != "1" {
+		t.Errorf("Pointer{}.Set() = %v, %v, want replaced root", root, err)
+	}
+}
+
+func TestDeletePointer(t *testing.T) {
+	doc, _ := Parse(`{"a": {"b": 1, "c": 2}, "list": [1, 2, 3]}`)
+	arr, _ := Parse(`[[1, 2], {"x": 3}]`)
+	obj, _ := doc.AsObject()
+	array, _ := arr.AsArray()
+
+	removed, err := obj.DeletePointer("/a/b")
+	if err != nil || removed.String() != "1" {
+		t.Errorf("DeletePointer(/a/b) = %v, %v", removed, err)
+	}
+	removed, err = obj.DeletePointer("/list/1")
+	if err != nil || removed.String() != "2" {
+		t.Errorf("DeletePointer(/list/1) = %v, %v", removed, err)
+	}
+	if want := `{"a":{"c":2},"list":[1,3]}`; obj.String() != want {
+		t.Errorf("after DeletePointer() = %v, want %v", obj.String(), want)
+	}
+	if _, err := obj.DeletePointer("/a/b"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("DeletePointer(missing) error = %v, want ErrKeyNotFound", err)
+	}
+	if _, err := obj.DeletePointer(""); err == nil {
+		t.Error("DeletePointer(\"\") should return error")
+	}
+
+	if _, err := array.DeletePointer("/1/x"); err != nil {
+		t.Errorf("DeletePointer(/1/x) error = %v", err)
+	}
+	if err := array.SetPointer("/0/-", NewJsonInt(3)); err != nil {
+		t.Errorf("SetPointer(/0/-) error = %v", err)
+	}
+	if v, _ := array.GetPointer("/0/2"); v == nil || v.String() != "3" {
+		t.Errorf("GetPointer(/0/2) = %v, want 3", v)
+	}
+	if want := `[[1,2,3],{}]`; array.String() != want {
+		t.Errorf("array = %v, want %v", array.String(), want)
+	}
+}
+
+func TestPointerNilRoot(t *testing.T) {
+	ptr := Pointer{"a"}
+	if _, err := ptr.Get(nil); err == nil {
+		t.Error("Get(nil) should return error")
+	}
+	if _, err := ptr.Set(nil, NewJsonInt(1)); err == nil {
+		t.Error("Set(nil) should return error")
+	}
+	if _, err := ptr.Delete(nil); err == nil {
+		t.Error("Delete(nil) should return error")
+	}
+	if _, err := (Pointer{}).Get(nil); err == nil {
+		t.Error("Pointer{}.Get(nil) should return error")
+	}
+}
+
+func TestPointerErrorNamesType(t *testing.T) {
+	doc, _ := Parse(`{"a": "a long string value", "b": 1}`)
+	tests := []struct {
+		op   string
+		err  func() error
+		want string
+	}{
+		{op: "Get", err: func() error { _, err := (Pointer{"a", "x"}).Get(doc); return err }, want: "cannot descend into string"},
+		{op: "Set", err: func() error { _, err := (Pointer{"b", "x"}).Set(doc, NewJsonNull()); return err }, want: "cannot set a member of number"},
+		{op: "Delete", err: func() error { _, err := (Pointer{"a", "x"}).Delete(doc); return err }, want: "cannot delete a member of string"},
+	}
+	for _, tt := range tests {
+		err := tt.err()
+		if err == nil || !contains(err.Error(), tt.want) || contains(err.Error(), "long string") {
+			t.Errorf("%s error = %v, want %q", tt.op, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package aaronjson
+
+import (
+	"sort"
+	"strings"
+)
+
+// KeyOrder selects the order in which object members are written.
+type KeyOrder int
+
+const (
+	// KeyOrderDefault writes members in insertion order.
+	KeyOrderDefault KeyOrder = iota
+	// KeyOrderSorted writes members sorted by key.
+	KeyOrderSorted
+)
+
+// PrettyOptions controls the layout of pretty-printed output.
+type PrettyOptions struct {
+	// Prefix is written at the start of every line after the first.
+	Prefix string
+	// Indent is written once per nesting level, e.g. "  " or "\t".
+	Indent string
+	// KeyOrder selects the order of object members.
+	KeyOrder KeyOrder
+	// MaxInlineWidth keeps an array or object on a single line when that line,
+	// including its indentation, fits in this many bytes. Zero disables inlining.
+	MaxInlineWidth int
+	// SpaceAfterColon writes a space between an object key and its value.
+	SpaceAfterColon bool
+	// TrailingNewline ends the output with a newline.
+	TrailingNewline bool
+}
+
+// DefaultPrettyOptions returns the options used by PrettyString:
+// two-space indentation, one element per line and a space after colons.
+func DefaultPrettyOptions() PrettyOptions {
+	return PrettyOptions{
+		Indent:          "  ",
+		SpaceAfterColon: true,
+	}
+}
+
+// prettyString returns v formatted according to opts.
+func prettyString(v JsonValue, opts PrettyOptions) string {
+	var sb strings.Builder
+	enc := NewEncoder(&sb)
+	enc.SetPrettyOptions(opts)
+	_ = enc.encodeValue(v)
+	if opts.TrailingNewline {
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// orderedKeys returns the keys of obj in the order selected by order.
+func orderedKeys(obj *JsonObject, order KeyOrder) []string {
+	if order != KeyOrderSorted {
+		return obj.keys
+	}
+	keys := make([]string, len(obj.keys))
+	copy(keys, obj.keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// appendInline appends the single-line form of v to dst, with a space after
+// commas and, if requested, after colons. It gives up and reports false as soon
+// as more than limit bytes would be appended.
+func appendInline(dst []byte, v JsonValue, opts *PrettyOptions, limit int) ([]byte, bool) {
+	start := len(dst)
+	var ok bool
+	switch jv := v.(type) {
+	case *JsonObject:
+		dst = append(dst, '{')
+		for i, key := range orderedKeys(jv, opts.KeyOrder) {
+			if i > 0 {
+				dst = append(dst, ',', ' ')
+			}
+			dst = appendQuotedString(dst, key)
+			dst = append(dst, ':')
+			if opts.SpaceAfterColon {
+				dst = append(dst, ' ')
+			}
+			if len(dst)-start > limit {
+				return dst, false
+			}
+			dst, ok = appendInline(dst, jv.data[key], opts, limit-(len(dst)-start))
+			if !ok {
+				return dst, false
+			}
+		}
+		dst = append(dst, '}')
+	case *JsonArray:
+		dst = append(dst, '[')
+		for i, item := range jv.data {
+			if i > 0 {
+				dst = append(dst, ',', ' ')
+			}
+			dst, ok = appendInline(dst, item, opts, limit-(len(dst)-start))
+			if !ok {
+				return dst, false
+			}
+		}
+		dst = append(dst, ']')
+	default:
+		dst = appendCompact(dst, v)
+	}
+	return dst, len(dst)-start <= limit
+}
@@ -0,0 +1,87 @@
+package aaronjson
+
+import (
+	"testing"
+)
+
+func TestPrettyStringWithOptions(t *testing.T) {
+	parsed, err := Parse(`{"name": "John", "tags": ["a", "b"], "pos": {"x": 1, "y": 2}}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	obj, _ := parsed.AsObject()
+
+	tests := []struct {
+		name string
+		opts PrettyOptions
+		want string
+	}{
+		{
+			name: "default",
+			opts: DefaultPrettyOptions(),
+			want: "{\n  \"name\": \"John\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"pos\": {\n    \"x\": 1,\n    \"y\": 2\n  }\n}",
+		},
+		{
+			name: "tabs without space after colon",
+			opts: PrettyOptions{Indent: "\t", KeyOrder: KeyOrderSorted},
+			want: "{\n\t\"name\":\"John\",\n\t\"pos\":{\n\t\t\"x\":1,\n\t\t\"y\":2\n\t},\n\t\"tags\":[\n\t\t\"a\",\n\t\t\"b\"\n\t]\n}",
+		},
+		{
+			name: "prefix and trailing newline",
+			opts: PrettyOptions{Prefix: "// ", Indent: " ", SpaceAfterColon: true, MaxInlineWidth: 30, TrailingNewline: true},
+			want: "{\n//  \"name\": \"John\",\n//  \"tags\": [\"a\", \"b\"],\n//  \"pos\": {\"x\": 1, \"y\": 2}\n// }\n",
+		},
+		{
+			name: "everything inline",
+			opts: PrettyOptions{Indent: "  ", SpaceAfterColon: true, MaxInlineWidth: 80},
+			want: `{"name": "John", "tags": ["a", "b"], "pos": {"x": 1, "y": 2}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := obj.PrettyStringWithOptions(tt.opts)
+			if got != tt.want {
+				t.Errorf("PrettyStringWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyInlineWidthCountsIndentation(t *testing.T) {
+	parsed, err := Parse(`[[1, 2, 3], [[4, 5], 6]]`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	arr, _ := parsed.AsArray()
+
+	// "  [1, 2, 3]" is 11 bytes wide and fits; "  [[4, 5], 6]" is 13 and does not.
+	opts := PrettyOptions{Indent: "  ", MaxInlineWidth: 12}
+	want := "[\n  [1, 2, 3],\n  [\n    [4, 5],\n    6\n  ]\n]"
+	if got := arr.PrettyStringWithOptions(opts); got != want {
+		t.Errorf("PrettyStringWithOptions() = %q, want %q", got, want)
+	}
+}
+
+func TestPrettyOutputParses(t *testing.T) {
+	input := `{"a": [1, {"b": [true, null, "x\"y"]}, []], "c": {}}`
+	parsed, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	obj, _ := parsed.AsObject()
+
+	for _, opts := range []PrettyOptions{
+		DefaultPrettyOptions(),
+		{Indent: "\t", MaxInlineWidth: 10},
+		{Prefix: "  ", MaxInlineWidth: 1000, TrailingNewline: true},
+	} {
+		reparsed, err := Parse(obj.PrettyStringWithOptions(opts))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if !Equal(parsed, reparsed) {
+			t.Errorf("pretty output with %+v does not round trip", opts)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package aaronjson
+
+import (
+	"errors"
+	"io"
+)
+
+// PushParser parses JSON that arrives in arbitrary chunks, such as reads from
+// a network connection, without blocking on an io.Reader. Feed it input with
+// Write; each top-level value is passed to the handler as soon as its last
+// byte has been written. The input may hold any number of top-level values
+// separated by whitespace.
+//
+// The parser keeps only the value currently being received in memory. Its
+// boundaries are tracked across writes by a small resumable scanner, and the
+// complete value is then handed to the regular parser. Errors report byte
+// offsets into the whole input.
+type PushParser struct {
+	p      *parser
+	handle func(JsonValue) error
+
+	buf    []byte
+	offset int // stream offset of buf[0]
+	scan   int // next byte of buf to scan
+
+	// Resumable scanner state for the value being received
+	start    int // start of the value in buf, or -1 between values
+	depth    int // open arrays and objects
+	inString bool
+	escaped  bool
+	scalar   bool // a top-level number or literal, which ends at the next whitespace or delimiter
+
+	err    error // sticky error
+	closed bool
+}
+
+// NewPushParser returns a push parser that passes every complete top-level
+// value to handle. An error returned by handle stops the parser and is
+// returned from the Write or Close call that produced the value.
+func NewPushParser(handle func(JsonValue) error) *PushParser {
+	return NewPushParserWithOptions(handle, ParseOptions{})
+}
+
+// NewPushParserWithOptions returns a push parser using the given options.
+// MaxBytes limits the size of each top-level value rather than the whole input.
+func NewPushParserWithOptions(handle func(JsonValue) error, opts ParseOptions) *PushParser {
+	return &PushParser{
+		p:      newParser(opts),
+		handle: handle,
+		start:  -1,
+	}
+}
+
+// Write feeds the next chunk of input to the parser, calling the handler for
+// every value the chunk completes. Once Write returns an error, every later
+// call returns the same error.
+func (pp *PushParser) Write(chunk []byte) (int, error) {
+	if pp.err != nil {
+		return 0, pp.err
+	}
+	if pp.closed {
+		return 0, errors.New("write to closed push parser")
+	}
+	pp.buf = append(pp.buf, chunk...)
+	if err := pp.advance(); err != nil {
+		pp.err = err
+		return len(chunk), err
+	}
+	pp.discard()
+	return len(chunk), nil
+}
+
+// Close marks the end of the input. It completes a trailing top-level number
+// or literal and reports a value that was cut off, in which case the error
+// matches io.ErrUnexpectedEOF with errors.Is.
+func (pp *PushParser) Close() error {
+	if pp.err != nil || pp.closed {
+		return pp.err
+	}
+	pp.closed = true
+	if pp.start < 0 {
+		return nil
+	}
+	if pp.scalar {
+		pp.err = pp.complete(len(pp.buf))
+		return pp.err
+	}
+
+	// Let the parser describe what is missing
+	err := pp.complete(len(pp.buf))
+	if se, ok := err.(*SyntaxError); ok && se.Err == nil {
+		se.Err = io.ErrUnexpectedEOF
+	}
+	pp.err = err
+	return err
+}
+
+// advance scans the buffered input, completing every value whose end it finds.
+func (pp *PushParser) advance() error {
+	for pp.scan < len(pp.buf) {
+		c := pp.buf[pp.scan]
+		switch {
+		case pp.start < 0:
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				pp.scan++
+				continue
+			}
+			pp.start = pp.scan
+			pp.scan++
+			switch {
+			case c == '{' || c == '[':
+				pp.depth = 1
+			case c == '"':
+				pp.inString = true
+			case isTokenChar(c):
+				pp.scalar = true
+			default:
+				// Cannot start a value; let the parser report it
+				if err := pp.complete(pp.scan); err != nil {
+					return err
+				}
+			}
+		case pp.inString:
+			pp.scan++
+			switch {
+			case pp.escaped:
+				pp.escaped = false
+			case c == '\\':
+				pp.escaped = true
+			case c == '"':
+				pp.inString = false
+				if pp.depth == 0 {
+					if err := pp.complete(pp.scan); err != nil {
+						return err
+					}
+				}
+			}
+		case pp.scalar:
+			if isTokenChar(c) {
+				pp.scan++
+				break
+			}
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				// Check the byte after the scalar the way ParseAll does
+				if _, _, err := pp.p.parseValue(pp.buf[pp.start:pp.scan+1], 0); err != nil {
+					return shiftErrorOffset(err, pp.offset+pp.start)
+				}
+			}
+			if err := pp.complete(pp.scan); err != nil {
+				return err
+			}
+		default:
+			pp.scan++
+			switch c {
+			case '"':
+				pp.inString = true
+			case '{', '[':
+				pp.depth++
+			case '}', ']':
+				pp.depth--
+				if pp.depth == 0 {
+					if err := pp.complete(pp.scan); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if limit := pp.p.opts.MaxBytes; limit > 0 && pp.start >= 0 && pp.scan-pp.start > limit {
+			return newLimitError(ErrMaxBytesExceeded, limit, pp.offset+pp.start+limit)
+		}
+	}
+	return nil
+}
+
+// complete parses the value in buf[start:end] and passes it to the handler.
+func (pp *PushParser) complete(end int) error {
+	start := pp.start
+	pp.start = -1
+	pp.depth = 0
+	pp.inString, pp.escaped, pp.scalar = false, false, false
+
+	value, err := pp.p.parseDocument(pp.buf[start:end])
+	if err != nil {
+		return shiftErrorOffset(err, pp.offset+start)
+	}
+	return pp.handle(value)
+}
+
+// discard drops the scanned input that no longer belongs to a pending value.
+func (pp *PushParser) discard() {
+	keep := pp.scan
+	if pp.start >= 0 {
+		keep = pp.start
+	}
+	if keep == 0 {
+		return
+	}
+	n := copy(pp.buf, pp.buf[keep:])
+	pp.buf = pp.buf[:n]
+	pp.offset += keep
+	pp.scan -= keep
+	if pp.start >= 0 {
+		pp.start -= keep
+	}
+}
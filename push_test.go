@@ -0,0 +1,174 @@
+package aaronjson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPushParser(t *testing.T) {
+	input := `{"a": "}]\"{", "b": [1, {"c": null}]} [] "x\\" 42 true -1.5e3`
+	want := []string{`{"a":"}]\"{","b":[1,{"c":null}]}`, `[]`, `"x\\"`, `42`, `true`, `-1500.0`}
+
+	for _, size := range []int{1, 2, 7, len(input)} {
+		var got []string
+		pp := NewPushParser(func(v JsonValue) error {
+			got = append(got, v.String())
+			return nil
+		})
+		for i := 0; i < len(input); i += size {
+			end := i + size
+			if end > len(input) {
+				end = len(input)
+			}
+			if _, err := pp.Write([]byte(input[i:end])); err != nil {
+				t.Fatalf("chunk size %d: Write() error = %v", size, err)
+			}
+		}
+		// Everything but the trailing number is complete before Close
+		if len(got) != len(want)-1 {
+			t.Errorf("chunk size %d: got %d values before Close, want %d", size, len(got), len(want)-1)
+		}
+		if err := pp.Close(); err != nil {
+			t.Fatalf("chunk size %d: Close() error = %v", size, err)
+		}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("chunk size %d: values = %v, want %v", size, got, want)
+		}
+	}
+}
+
+func TestPushParserImmediateDelivery(t *testing.T) {
+	count := 0
+	pp := NewPushParser(func(v JsonValue) error {
+		count++
+		return nil
+	})
+	_, _ = pp.Write([]byte(`{"id": 1`))
+	if count != 0 {
+		t.Fatalf("value delivered before it was complete")
+	}
+	_, _ = pp.Write([]byte(`}`))
+	if count != 1 {
+		t.Errorf("value not delivered as soon as it was complete")
+	}
+}
+
+func TestPushParserErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		chunks     []string
+		wantOffset int
+		wantEOF    bool
+	}{
+		{
+			name:       "malformed value",
+			chunks:     []string{`[1] [1 `, `2]`},
+			wantOffset: 7,
+		},
+		{
+			name:       "stray delimiter",
+			chunks:     []string{`{} }`},
+			wantOffset: 3,
+		},
+		{
+			name:       "truncated object",
+			chunks:     []string{`{"a": `, `[1`},
+			wantOffset: 8,
+			wantEOF:    true,
+		},
+		{
+			name:       "truncated string",
+			chunks:     []string{`"abc`},
+			wantOffset: 0,
+			wantEOF:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp := NewPushParser(func(v JsonValue) error { return nil })
+			var err error
+			for _, chunk := range tt.chunks {
+				if _, err = pp.Write([]byte(chunk)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = pp.Close()
+			}
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("error = %v, want SyntaxError", err)
+			}
+			if se.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d (%v)", se.Offset, tt.wantOffset, err)
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) != tt.wantEOF {
+				t.Errorf("errors.Is(err, io.ErrUnexpectedEOF) = %v, want %v", !tt.wantEOF, tt.wantEOF)
+			}
+			if _, werr := pp.Write([]byte("1")); werr == nil {
+				t.Error("Write() after an error should fail")
+			}
+		})
+	}
+}
+
+func TestPushParserMatchesParseAll(t *testing.T) {
+	inputs := []string{
+		`1"a"`, `true"x"`, `1[2]`, `1{}`, `null{`, `1/`, "1\x00", `-2.5e3x`,
+		`1,2`, `1}`, `false]`, `1 "a"`, `"a"1`, `[1]2`, "true\n[1]\t3\r\n",
+	}
+
+	for _, input := range inputs {
+		values, wantErr := ParseAll([]byte(input))
+		var want []string
+		for _, v := range values {
+			want = append(want, v.String())
+		}
+
+		for _, size := range []int{1, len(input)} {
+			var got []string
+			pp := NewPushParser(func(v JsonValue) error {
+				got = append(got, v.String())
+				return nil
+			})
+			var err error
+			for i := 0; i < len(input) && err == nil; i += size {
+				_, err = pp.Write([]byte(input[i:min(i+size, len(input))]))
+			}
+			if err == nil {
+				err = pp.Close()
+			}
+
+			if wantErr == nil {
+				if err != nil || strings.Join(got, " ") != strings.Join(want, " ") {
+					t.Errorf("%q, chunk size %d: values = %v, %v, want %v", input, size, got, err, want)
+				}
+				continue
+			}
+			// Values before the error have already been delivered; the error must be the same
+			var gotSyntax, wantSyntax *SyntaxError
+			if !errors.As(err, &gotSyntax) || !errors.As(wantErr, &wantSyntax) ||
+				gotSyntax.Msg != wantSyntax.Msg || gotSyntax.Offset != wantSyntax.Offset {
+				t.Errorf("%q, chunk size %d: error = %v, want %v", input, size, err, wantErr)
+			}
+		}
+	}
+}
+
+func TestPushParserHandlerError(t *testing.T) {
+	stop := errors.New("stop")
+	pp := NewPushParser(func(v JsonValue) error { return stop })
+	if _, err := pp.Write([]byte(`[1] [2]`)); err != stop {
+		t.Errorf("Write() error = %v, want handler error", err)
+	}
+}
+
+func TestPushParserMaxBytes(t *testing.T) {
+	pp := NewPushParserWithOptions(func(v JsonValue) error { return nil }, ParseOptions{MaxBytes: 8})
+	if _, err := pp.Write([]byte(`[1, 2] [1, 2, 3, 4`)); !errors.Is(err, ErrMaxBytesExceeded) {
+		t.Errorf("Write() error = %v, want ErrMaxBytesExceeded", err)
+	}
+}
@@ -0,0 +1,106 @@
+package aaronjson
+
+import (
+	"math"
+	"strconv"
+)
+
+// Compact returns the minimal RFC 8259 encoding of v, without any
+// insignificant whitespace. Object members are written in insertion order.
+// Parsing the result yields a value equal to v.
+// Floats use the shortest representation that round-trips and always carry a
+// fraction or exponent, so they parse back as JsonFloat. NaN and infinities
+// have no JSON representation and are encoded as null.
+func Compact(v JsonValue) []byte {
+	return appendCompact(nil, v)
+}
+
+// appendCompact appends the compact encoding of v to dst and returns the extended buffer.
+func appendCompact(dst []byte, v JsonValue) []byte {
+	switch jv := v.(type) {
+	case nil:
+		return append(dst, "null"...)
+	case *JsonObject:
+		dst = append(dst, '{')
+		for i, key := range jv.keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendQuotedString(dst, key)
+			dst = append(dst, ':')
+			dst = appendCompact(dst, jv.data[key])
+		}
+		return append(dst, '}')
+	case *JsonArray:
+		dst = append(dst, '[')
+		for i, item := range jv.data {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendCompact(dst, item)
+		}
+		return append(dst, ']')
+	case *JsonString:
+		return appendQuotedString(dst, jv.data)
+	case *JsonInt:
+		if jv.float {
+			return appendFloat(dst, jv.fdata)
+		}
+		if jv.unsigned {
+			return strconv.AppendUint(dst, jv.udata, 10)
+		}
+		return strconv.AppendInt(dst, jv.data, 10)
+	case *JsonFloat:
+		return appendFloat(dst, jv.data)
+	case *JsonNumber:
+		return append(dst, jv.data...)
+	case *JsonBool:
+		return strconv.AppendBool(dst, jv.data)
+	case *JsonNull:
+		return append(dst, "null"...)
+	default:
+		return append(dst, v.String()...)
+	}
+}
+
+// appendQuotedString appends s to dst as a quoted and escaped JSON string.
+func appendQuotedString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendEscapedString(dst, s)
+	return append(dst, '"')
+}
+
+// appendFloat appends the shortest round-trip encoding of f to dst.
+// It mirrors the ECMAScript number formatting: plain decimal notation for
+// magnitudes in [1e-6, 1e21) and exponent notation otherwise.
+func appendFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Shorten e-07 to e-7
+		n := len(dst)
+		if n-start >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+		return dst
+	}
+
+	// Keep a fraction so that the value parses back as a float
+	for _, c := range dst[start:] {
+		if c == '.' {
+			return dst
+		}
+	}
+	return append(dst, ".0"...)
+}
@@ -0,0 +1,177 @@
+package aaronjson
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name  string
+		value JsonValue
+		want  string
+	}{
+		{
+			name:  "string with escapes",
+			value: NewJsonString("line\n\"quoted\"\x01"),
+			want:  `"line\n\"quoted\"\u0001"`,
+		},
+		{
+			name:  "integer",
+			value: NewJsonInt(30),
+			want:  "30",
+		},
+		{
+			name:  "integral float",
+			value: NewJsonFloat(30),
+			want:  "30.0",
+		},
+		{
+			name:  "shortest float",
+			value: NewJsonFloat(0.1),
+			want:  "0.1",
+		},
+		{
+			name:  "large float",
+			value: NewJsonFloat(1e21),
+			want:  "1e+21",
+		},
+		{
+			name:  "small float",
+			value: NewJsonFloat(1e-7),
+			want:  "1e-7",
+		},
+		{
+			name:  "NaN",
+			value: NewJsonFloat(math.NaN()),
+			want:  "null",
+		},
+		{
+			name:  "bool",
+			value: NewJsonBool(true),
+			want:  "true",
+		},
+		{
+			name:  "null",
+			value: NewJsonNull(),
+			want:  "null",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Compact(tt.value))
+			if got != tt.want {
+				t.Errorf("Compact() = %v, want %v", got, tt.want)
+			}
+			if tt.value.String() != tt.want {
+				t.Errorf("String() = %v, want %v", tt.value.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	inputs := []string{
+		`{"a": [1, 2.5, -0.0, 1e300, "x\ty"], "b": {"c": null, "d": true}, "e": ""}`,
+		`[18446744073709551615, -9223372036854775808, 0.1, 123456.789e-20]`,
+		`"café 😀"`,
+		`[[], {}, [{}], {"k": []}]`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			original, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			compact := Compact(original)
+			reparsed, err := ParseByte(compact)
+			if err != nil {
+				t.Fatalf("Parse(%s) error = %v", compact, err)
+			}
+			if !Equal(original, reparsed) {
+				t.Errorf("round trip mismatch: %s", compact)
+			}
+			if string(Compact(reparsed)) != string(compact) {
+				t.Errorf("Compact() not stable: %s != %s", Compact(reparsed), compact)
+			}
+			if !json.Valid(compact) {
+				t.Errorf("Compact() produced invalid JSON: %s", compact)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONInterop(t *testing.T) {
+	parsed, err := Parse(`{"name": "John", "tags": ["a", "b"]}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	data, err := json.Marshal(map[string]JsonValue{"user": parsed})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"user":{"name":"John","tags":["a","b"]}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, _ := Parse(`{"x": 1, "y": [true, null, "s"]}`)
+	b, _ := Parse(`{"y": [true, null, "s"], "x": 1.0}`)
+	c, _ := Parse(`{"x": 1, "y": [true, null]}`)
+	n, _ := ParseWithOptions(`1.00`, ParseOptions{UseNumber: true})
+
+	if !Equal(a, b) {
+		t.Error("Equal() should ignore key order and numeric representation")
+	}
+	if Equal(a, c) {
+		t.Error("Equal() should detect differing arrays")
+	}
+	if !Equal(NewJsonInt(1), n) {
+		t.Error("Equal() should compare JsonNumber by value")
+	}
+	if Equal(NewJsonString("1"), NewJsonInt(1)) {
+		t.Error("Equal() should not equate strings and numbers")
+	}
+	if Equal(NewJsonFloat(math.NaN()), NewJsonFloat(math.NaN())) {
+		t.Error("Equal() should not equate NaN values")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{
+		`null`, `false`, `true`, `-1e3`, `0`, `1.5`, `2`, `""`, `"A"`, `"a"`, `"ab"`, `"é"`,
+		`[]`, `[1]`, `[1,2]`, `[2]`, `{}`, `{"a":2}`, `{"a":1,"b":0}`, `{"a":2,"b":0}`, `{"b":0}`,
+	}
+	values := make([]JsonValue, len(ordered))
+	for i, s := range ordered {
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", s, err)
+		}
+		values[i] = v
+	}
+
+	for i := range values {
+		for j := range values {
+			want := compareOrdered(int64(i), int64(j))
+			if got := Compare(values[i], values[j]); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	n, _ := ParseWithOptions(`2.00`, ParseOptions{UseNumber: true})
+	if Compare(NewJsonInt(2), n) != 0 || Compare(NewJsonFloat(1.5), n) != -1 {
+		t.Error("Compare() should order numbers by value across representations")
+	}
+	nan := NewJsonFloat(math.NaN())
+	if Compare(nan, nan) != 0 || Compare(nan, NewJsonFloat(math.Inf(-1))) != -1 || Compare(NewJsonInt(0), nan) != 1 {
+		t.Error("Compare() should order NaN below every other number")
+	}
+}
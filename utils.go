@@ -1,35 +1,59 @@
 package aaronjson
 
-import "fmt"
+import "unicode/utf8"
 
 // escapeString escapes special characters in JSON strings
 func escapeString(s string) string {
-	result := ""
-	for _, r := range s {
-		switch r {
-		case '"':
-			result += "\\\""
-		case '\\':
-			result += "\\\\"
-		case '\b':
-			result += "\\b"
-		case '\f':
-			result += "\\f"
-		case '\n':
-			result += "\\n"
-		case '\r':
-			result += "\\r"
-		case '\t':
-			result += "\\t"
-		default:
-			if r < 32 {
-				result += fmt.Sprintf("\\u%04x", r)
-			} else {
-				result += string(r)
+	return string(appendEscapedString(nil, s))
+}
+
+// appendEscapedString appends s to dst with the characters that JSON strings
+// cannot hold literally escaped. Invalid UTF-8 bytes are replaced by U+FFFD.
+func appendEscapedString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"':
+				dst = append(dst, '\\', '"')
+			case '\\':
+				dst = append(dst, '\\', '\\')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
 			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += size
+			start = i
+			continue
 		}
+		i += size
 	}
-	return result
+	return append(dst, s[start:]...)
 }
 
 // skipWhitespace advances the position past any whitespace characters.
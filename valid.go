@@ -0,0 +1,227 @@
+package aaronjson
+
+import "strconv"
+
+// Valid checks that data holds exactly one well-formed JSON value without
+// building a JsonValue tree. It runs the same grammar checks as ParseByte and
+// returns the same error ParseByte would, or nil if the data is valid.
+// Valid does not allocate unless the data is invalid.
+func Valid(data []byte) error {
+	p := parser{maxDepth: DefaultMaxDepth}
+	pos := skipWhitespace(data, 0)
+	if pos >= len(data) {
+		return newSyntaxError(pos, "empty JSON data").withErr(ErrEmptyData).withContext(data)
+	}
+
+	pos, err := p.validValue(data, pos)
+	if err != nil {
+		return withErrorContext(err, data)
+	}
+
+	pos = skipWhitespace(data, pos)
+	if pos < len(data) {
+		return newSyntaxError(pos, "unexpected data after top-level value").withContext(data)
+	}
+	return nil
+}
+
+// validValue checks the JSON value starting at pos, mirroring parseValue.
+// Returns the position after the value and any error.
+func (p *parser) validValue(data []byte, pos int) (int, error) {
+	pos = skipWhitespace(data, pos)
+
+	if pos >= len(data) {
+		return pos, newSyntaxError(pos, "unexpected end of data")
+	}
+
+	switch c := data[pos]; c {
+	case '{':
+		return p.validObject(data, pos)
+	case '[':
+		return p.validArray(data, pos)
+	case '"':
+		return validString(data, pos)
+	case 't':
+		return validLiteral(data, pos, "true", "invalid boolean value")
+	case 'f':
+		return validLiteral(data, pos, "false", "invalid boolean value")
+	case 'n':
+		return validLiteral(data, pos, "null", "invalid null value")
+	default:
+		if (c >= '0' && c <= '9') || c == '-' || c == '+' {
+			return validNumber(data, pos)
+		}
+		return pos, newSyntaxError(pos, "invalid character %s looking for beginning of value", quoteChar(c))
+	}
+}
+
+// validObject checks the JSON object starting at pos, mirroring parseObject.
+func (p *parser) validObject(data []byte, pos int) (int, error) {
+	if err := p.enter(pos); err != nil {
+		return pos, err
+	}
+	defer p.leave()
+	pos++ // Move past '{'
+
+	pos = skipWhitespace(data, pos)
+	if pos < len(data) && data[pos] == '}' {
+		return pos + 1, nil
+	}
+
+	for {
+		pos = skipWhitespace(data, pos)
+		if pos >= len(data) {
+			return pos, newSyntaxError(pos, "unexpected end of data while parsing object")
+		}
+
+		if data[pos] != '"' {
+			return pos, newSyntaxError(pos, "expected string key")
+		}
+		newPos, err := validString(data, pos)
+		if err != nil {
+			return pos, err
+		}
+		pos = skipWhitespace(data, newPos)
+		if pos >= len(data) || data[pos] != ':' {
+			return pos, newSyntaxError(pos, "expected ':' after key")
+		}
+		pos++ // Move past ':'
+
+		pos = skipWhitespace(data, pos)
+		newPos, err = p.validValue(data, pos)
+		if err != nil {
+			return pos, err
+		}
+
+		pos = skipWhitespace(data, newPos)
+		if pos >= len(data) {
+			return pos, newSyntaxError(pos, "unexpected end of data while parsing object")
+		}
+		switch data[pos] {
+		case '}':
+			return pos + 1, nil
+		case ',':
+			pos++
+		default:
+			return pos, newSyntaxError(pos, "expected ',' or '}'")
+		}
+	}
+}
+
+// validArray checks the JSON array starting at pos, mirroring parseArray.
+func (p *parser) validArray(data []byte, pos int) (int, error) {
+	if err := p.enter(pos); err != nil {
+		return pos, err
+	}
+	defer p.leave()
+	pos++ // Move past '['
+
+	pos = skipWhitespace(data, pos)
+	if pos < len(data) && data[pos] == ']' {
+		return pos + 1, nil
+	}
+
+	for {
+		pos = skipWhitespace(data, pos)
+		if pos >= len(data) {
+			return pos, newSyntaxError(pos, "unexpected end of data while parsing array")
+		}
+
+		newPos, err := p.validValue(data, pos)
+		if err != nil {
+			return pos, err
+		}
+
+		pos = skipWhitespace(data, newPos)
+		if pos >= len(data) {
+			return pos, newSyntaxError(pos, "unexpected end of data while parsing array")
+		}
+		switch data[pos] {
+		case ']':
+			return pos + 1, nil
+		case ',':
+			pos++
+		default:
+			return pos, newSyntaxError(pos, "expected ',' or ']'")
+		}
+	}
+}
+
+// validString checks the JSON string starting at pos, mirroring parseString.
+// Invalid UTF-8 is accepted, since the parser replaces it with U+FFFD.
+func validString(data []byte, pos int) (int, error) {
+	start := pos + 1
+	for pos = start; pos < len(data); {
+		switch c := data[pos]; {
+		case c == '"':
+			return pos + 1, nil
+		case c == '\\':
+			_, newPos, err := parseEscape(data, pos)
+			if err != nil {
+				return newPos, err
+			}
+			pos = newPos
+		case c < 0x20:
+			return pos, newSyntaxError(pos, "invalid control character %#02x in string", c)
+		default:
+			pos++
+		}
+	}
+	return pos, newSyntaxError(start-1, "unterminated string")
+}
+
+// validLiteral checks that the literal lit starts at pos and is followed by a delimiter.
+func validLiteral(data []byte, pos int, lit, msg string) (int, error) {
+	end := pos + len(lit)
+	if end > len(data) || string(data[pos:end]) != lit {
+		return pos, newSyntaxError(pos, "%s", msg)
+	}
+	if end < len(data) && !isValidNullTerminator(data[end]) {
+		return pos, newSyntaxError(pos, "%s", msg)
+	}
+	return end, nil
+}
+
+// validNumber checks the JSON number starting at pos, mirroring parseNumber,
+// including its rejection of values that overflow or underflow a float64.
+func validNumber(data []byte, pos int) (int, error) {
+	start := pos
+	pos, isFloat, err := scanNumber(data, pos)
+	if err != nil {
+		return pos, err
+	}
+	if pos < len(data) && !isValidNumberTerminator(data[pos]) {
+		return pos, newSyntaxError(pos, "invalid character %s after number", quoteChar(data[pos]))
+	}
+
+	lexeme := data[start:pos]
+	if !isFloat {
+		// Integers only fail once they overflow uint64 and then float64
+		if len(lexeme) <= 19 {
+			return pos, nil
+		}
+		if _, err := strconv.ParseUint(string(lexeme), 10, 64); err == nil {
+			return pos, nil
+		}
+		if _, err := strconv.ParseInt(string(lexeme), 10, 64); err == nil {
+			return pos, nil
+		}
+		if _, err := strconv.ParseFloat(string(lexeme), 64); err != nil {
+			return pos, newSyntaxError(start, "invalid integer number '%s'", lexeme).withErr(err)
+		}
+		return pos, nil
+	}
+
+	val, err := strconv.ParseFloat(string(lexeme), 64)
+	if err != nil {
+		return pos, newSyntaxError(start, "invalid float number '%s'", lexeme).withErr(err)
+	}
+	if val == 0 {
+		for _, c := range lexeme {
+			if c >= '1' && c <= '9' {
+				return pos, newSyntaxError(start, "number underflow: '%s'", lexeme)
+			}
+		}
+	}
+	return pos, nil
+}
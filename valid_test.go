@@ -0,0 +1,66 @@
+package aaronjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	inputs := []string{
+		`{"name": "John", "tags": ["a", "bé\n"], "n": -1.5e3, "ok": true, "none": null}`,
+		`[1, 18446744073709551615, -9223372036854775809, 123456789012345678901234567890]`,
+		` "plain" `,
+		`{"": {}}`,
+		"\"invalid \xff utf-8\"",
+		``,
+		`   `,
+		`{"a": 1,}`,
+		`[1, 2`,
+		`[1 2]`,
+		`{"a" 1}`,
+		`{1: 2}`,
+		`"unterminated`,
+		"\"tab\there\"",
+		`"\x"`,
+		`"\u12"`,
+		`01`,
+		`1.`,
+		`-`,
+		`+1`,
+		`1e400`,
+		`1e-400`,
+		`1` + strings.Repeat("0", 400),
+		`12abc`,
+		`tru`,
+		`truex`,
+		`nul`,
+		`fals`,
+		`{"a": 1} {"b": 2}`,
+		`@`,
+		strings.Repeat("[", DefaultMaxDepth+1),
+	}
+
+	for _, input := range inputs {
+		_, parseErr := ParseByte([]byte(input))
+		validErr := Valid([]byte(input))
+		if (parseErr == nil) != (validErr == nil) {
+			t.Errorf("Valid(%.40q) = %v, ParseByte() error = %v", input, validErr, parseErr)
+			continue
+		}
+		if parseErr != nil && parseErr.Error() != validErr.Error() {
+			t.Errorf("Valid(%.40q) = %q, want %q", input, validErr.Error(), parseErr.Error())
+		}
+	}
+}
+
+func TestValidAllocations(t *testing.T) {
+	data := []byte(`{"users": [{"name": "Jo\"hn", "age": 30, "score": 9.75e1, "tags": ["a", "b"]}], "ok": true, "next": null}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := Valid(data); err != nil {
+			t.Fatalf("Valid() error = %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Valid() allocated %v times per run, want 0", allocs)
+	}
+}